@@ -0,0 +1,154 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/logging"
+	"strings"
+	"time"
+)
+
+// Alert warns that a batch of an ingredient is about to expire, along with recipes that would use it up
+type Alert struct {
+	Ingredient cookme.PerishableIngredient
+	Recipes    cookme.Recipes
+}
+
+func (a Alert) String() string {
+	if len(a.Recipes) == 0 {
+		return a.Ingredient.String()
+	}
+
+	var names []string
+	for _, r := range a.Recipes {
+		names = append(names, r.Name)
+	}
+
+	return fmt.Sprintf("%s, why not cook %s", a.Ingredient, strings.Join(names, ", "))
+}
+
+// Watcher checks the inventory for ingredients that are about to expire and sends alerts about them
+type Watcher struct {
+	ingredientsRepo cookme.IngredientsRepo
+	recipeRepo      cookme.RecipeRepo
+	notifiers       Notifiers
+	window          time.Duration
+	alerted         []map[string]bool
+}
+
+// NewWatcher creates a Watcher which alerts about ingredients expiring within window. When notifier is Notifiers, each
+// of them is tracked separately so one failing doesn't make the others send the same alerts again
+func NewWatcher(ingredientsRepo cookme.IngredientsRepo, recipeRepo cookme.RecipeRepo, notifier Notifier, window time.Duration) *Watcher {
+	notifiers, ok := notifier.(Notifiers)
+
+	if !ok {
+		notifiers = Notifiers{notifier}
+	}
+
+	alerted := make([]map[string]bool, len(notifiers))
+	for i := range alerted {
+		alerted[i] = make(map[string]bool)
+	}
+
+	return &Watcher{
+		ingredientsRepo: ingredientsRepo,
+		recipeRepo:      recipeRepo,
+		notifiers:       notifiers,
+		window:          window,
+		alerted:         alerted,
+	}
+}
+
+// Check sends alerts for every batch expiring within the window of now. Each notifier is only sent an alert about a
+// batch once, and is sent it again on the next check if it failed. The first error is returned, after trying the rest
+func (w *Watcher) Check(now time.Time) error {
+	ingredients := w.ingredientsRepo.Ingredients().SortByExpirationDate()
+	allRecipes, err := cookme.RecipesContext(context.Background(), w.recipeRepo)
+
+	if err != nil {
+		logging.Warn("problem getting recipes, alerting without suggestions", "err", err)
+	}
+
+	recipes := cookme.ListRecipes(cookme.IngredientsRepoFunc(func() cookme.PerishableIngredients {
+		return ingredients
+	}), cookme.RecipeRepoFunc(func() cookme.Recipes {
+		return allRecipes
+	}))
+
+	var expiring cookme.PerishableIngredients
+
+	for _, ingredient := range ingredients {
+		if !ingredient.HasExpired(now) && ingredient.HasExpired(now.Add(w.window)) {
+			expiring = append(expiring, ingredient)
+		}
+	}
+
+	var firstErr error
+
+	for n, notifier := range w.notifiers {
+		var alerts []Alert
+
+		for _, ingredient := range expiring {
+			if !w.alerted[n][alertKey(ingredient)] {
+				alerts = append(alerts, Alert{Ingredient: ingredient, Recipes: recipesUsing(recipes, ingredient.Ingredient)})
+			}
+		}
+
+		if len(alerts) == 0 {
+			continue
+		}
+
+		if err := notifier.Notify(alerts); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		for _, a := range alerts {
+			w.alerted[n][alertKey(a.Ingredient)] = true
+		}
+	}
+
+	return firstErr
+}
+
+// alertKey identifies a batch, falling back to its name and expiry for batches added before they had IDs
+func alertKey(ingredient cookme.PerishableIngredient) string {
+	if ingredient.BatchID != "" {
+		return ingredient.BatchID
+	}
+
+	return fmt.Sprintf("%s expiring %s", strings.ToLower(ingredient.Name), ingredient.ExpirationDate.Format(time.RFC3339Nano))
+}
+
+// Watch checks the inventory every interval until ctx is done
+func (w *Watcher) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Check(time.Now()); err != nil {
+			logging.Error("problem sending alerts", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func recipesUsing(recipes cookme.Recipes, ingredient cookme.Ingredient) (using cookme.Recipes) {
+	for _, r := range recipes {
+		for _, i := range r.Ingredients {
+			if strings.EqualFold(i.Name, ingredient.Name) {
+				using = append(using, r)
+				break
+			}
+		}
+	}
+	return
+}
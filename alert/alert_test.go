@@ -0,0 +1,161 @@
+package alert_test
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/alert"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+
+	now := time.Now()
+
+	milk := cookme.Ingredient{Name: "Milk"}
+	cheese := cookme.Ingredient{Name: "Cheese"}
+	pasta := cookme.Ingredient{Name: "Pasta"}
+
+	macAndCheese := cookme.NewRecipe("Mac and cheese", pasta, cheese)
+	cheesyMilk := cookme.NewRecipe("Cheesy milk", milk, cheese)
+
+	soonMilk := cookme.PerishableIngredient{Ingredient: milk, ExpirationDate: now.Add(12 * time.Hour), BatchID: "milk-1"}
+	laterCheese := cookme.PerishableIngredient{Ingredient: cheese, ExpirationDate: now.Add(24 * time.Hour), BatchID: "cheese-1"}
+	freshPasta := cookme.PerishableIngredient{Ingredient: pasta, ExpirationDate: now.Add(2000 * time.Hour), BatchID: "pasta-1"}
+	expiredMilk := cookme.PerishableIngredient{Ingredient: milk, ExpirationDate: now.Add(-time.Hour), BatchID: "milk-0"}
+
+	ingredients := cookme.IngredientsRepoFunc(func() cookme.PerishableIngredients {
+		return cookme.PerishableIngredients{freshPasta, laterCheese, soonMilk, expiredMilk}
+	})
+
+	recipes := cookme.RecipeRepoFunc(func() cookme.Recipes {
+		return cookme.Recipes{macAndCheese, cheesyMilk}
+	})
+
+	t.Run("alerts about ingredients expiring within the window with recipes that use them", func(t *testing.T) {
+		notifier := &spyNotifier{}
+		watcher := alert.NewWatcher(ingredients, recipes, notifier, 48*time.Hour)
+
+		if err := watcher.Check(now); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		want := [][]alert.Alert{{
+			{Ingredient: soonMilk, Recipes: cookme.Recipes{cheesyMilk}},
+			{Ingredient: laterCheese, Recipes: cookme.Recipes{macAndCheese, cheesyMilk}},
+		}}
+
+		assertAlertsEqual(t, notifier.calls, want)
+	})
+
+	t.Run("only alerts about ingredients inside the window", func(t *testing.T) {
+		notifier := &spyNotifier{}
+		watcher := alert.NewWatcher(ingredients, recipes, notifier, 18*time.Hour)
+
+		watcher.Check(now)
+
+		want := [][]alert.Alert{{
+			{Ingredient: soonMilk, Recipes: cookme.Recipes{cheesyMilk}},
+		}}
+
+		assertAlertsEqual(t, notifier.calls, want)
+	})
+
+	t.Run("alerts without suggestions when the recipes can't be fetched", func(t *testing.T) {
+		notifier := &spyNotifier{}
+		watcher := alert.NewWatcher(ingredients, failingRecipeRepo{}, notifier, 18*time.Hour)
+
+		if err := watcher.Check(now); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		assertAlertsEqual(t, notifier.calls, [][]alert.Alert{{{Ingredient: soonMilk}}})
+	})
+
+	t.Run("doesnt alert about the same batch twice", func(t *testing.T) {
+		notifier := &spyNotifier{}
+		watcher := alert.NewWatcher(ingredients, recipes, notifier, 18*time.Hour)
+
+		watcher.Check(now)
+		watcher.Check(now.Add(time.Minute))
+
+		if len(notifier.calls) != 1 {
+			t.Errorf("expected to be notified once but was notified %d times", len(notifier.calls))
+		}
+	})
+
+	t.Run("alerts about each batch without an ID", func(t *testing.T) {
+		notifier := &spyNotifier{}
+		oldMilk := cookme.PerishableIngredient{Ingredient: milk, ExpirationDate: now.Add(6 * time.Hour)}
+		oldCheese := cookme.PerishableIngredient{Ingredient: cheese, ExpirationDate: now.Add(12 * time.Hour)}
+
+		watcher := alert.NewWatcher(cookme.IngredientsRepoFunc(func() cookme.PerishableIngredients {
+			return cookme.PerishableIngredients{oldMilk, oldCheese}
+		}), recipes, notifier, 18*time.Hour)
+
+		watcher.Check(now)
+		watcher.Check(now.Add(time.Minute))
+
+		want := [][]alert.Alert{{
+			{Ingredient: oldMilk, Recipes: cookme.Recipes{cheesyMilk}},
+			{Ingredient: oldCheese, Recipes: cookme.Recipes{cheesyMilk}},
+		}}
+
+		assertAlertsEqual(t, notifier.calls, want)
+	})
+
+	t.Run("retries only the notifiers which failed", func(t *testing.T) {
+		working := &spyNotifier{}
+		flaky := &spyNotifier{failures: 1}
+		watcher := alert.NewWatcher(ingredients, recipes, alert.Notifiers{working, flaky}, 18*time.Hour)
+
+		if err := watcher.Check(now); err == nil {
+			t.Error("expected an error from the failing notifier")
+		}
+
+		if err := watcher.Check(now.Add(time.Minute)); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		want := [][]alert.Alert{{
+			{Ingredient: soonMilk, Recipes: cookme.Recipes{cheesyMilk}},
+		}}
+
+		assertAlertsEqual(t, working.calls, want)
+		assertAlertsEqual(t, flaky.calls, want)
+	})
+}
+
+type failingRecipeRepo struct{}
+
+func (failingRecipeRepo) Recipes() cookme.Recipes {
+	return nil
+}
+
+func (failingRecipeRepo) RecipesContext(ctx context.Context) (cookme.Recipes, error) {
+	return nil, errors.New("permission denied")
+}
+
+type spyNotifier struct {
+	calls    [][]alert.Alert
+	failures int
+}
+
+func (s *spyNotifier) Notify(alerts []alert.Alert) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("couldn't send alerts")
+	}
+
+	s.calls = append(s.calls, alerts)
+	return nil
+}
+
+func assertAlertsEqual(t *testing.T, got, want [][]alert.Alert) {
+	t.Helper()
+	if !cmp.Equal(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
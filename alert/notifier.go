@@ -0,0 +1,166 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// Notifier sends alerts somewhere a person will see them
+type Notifier interface {
+	Notify(alerts []Alert) error
+}
+
+// NotifierFunc allows you to implement Notifier with a func
+type NotifierFunc func(alerts []Alert) error
+
+// Notify sends the alerts with f
+func (f NotifierFunc) Notify(alerts []Alert) error {
+	return f(alerts)
+}
+
+// Notifiers sends alerts to every notifier in the collection
+type Notifiers []Notifier
+
+// Notify sends the alerts to each notifier, returning the first error but still trying the rest
+func (n Notifiers) Notify(alerts []Alert) error {
+	var firstErr error
+
+	for _, notifier := range n {
+		if err := notifier.Notify(alerts); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// WriterNotifier writes a line per alert to an io.Writer, such as os.Stdout
+type WriterNotifier struct {
+	out io.Writer
+}
+
+// NewWriterNotifier creates a WriterNotifier writing to out
+func NewWriterNotifier(out io.Writer) *WriterNotifier {
+	return &WriterNotifier{out: out}
+}
+
+// Notify writes the alerts
+func (w *WriterNotifier) Notify(alerts []Alert) error {
+	for _, a := range alerts {
+		if _, err := fmt.Fprintln(w.out, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FileNotifier appends alerts to a file, creating it if needed
+type FileNotifier struct {
+	filename string
+}
+
+// NewFileNotifier creates a FileNotifier appending to filename
+func NewFileNotifier(filename string) *FileNotifier {
+	return &FileNotifier{filename: filename}
+}
+
+// Notify appends the alerts to the file
+func (f *FileNotifier) Notify(alerts []Alert) error {
+	file, err := os.OpenFile(f.filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+
+	if err != nil {
+		return fmt.Errorf("problem opening alerts file '%s', %v", f.filename, err)
+	}
+
+	defer file.Close()
+
+	return NewWriterNotifier(file).Notify(alerts)
+}
+
+// CommandNotifier runs a command for each alert with the alert as its last argument, e.g. notify-send for desktop notifications
+type CommandNotifier struct {
+	name string
+	args []string
+}
+
+// NewCommandNotifier creates a CommandNotifier which will run name with args followed by the alert
+func NewCommandNotifier(name string, args ...string) *CommandNotifier {
+	return &CommandNotifier{name: name, args: args}
+}
+
+// Notify runs the command for each alert
+func (c *CommandNotifier) Notify(alerts []Alert) error {
+	for _, a := range alerts {
+		args := append(append([]string{}, c.args...), a.String())
+		if out, err := exec.Command(c.name, args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("problem running %s, %v: %s", c.name, err, out)
+		}
+	}
+	return nil
+}
+
+// WebhookAlert is the JSON sent to a webhook for each alert
+type WebhookAlert struct {
+	Ingredient     string    `json:"ingredient"`
+	BatchID        string    `json:"batchId"`
+	ExpirationDate time.Time `json:"expirationDate"`
+	Recipes        []string  `json:"recipes"`
+	Message        string    `json:"message"`
+}
+
+// WebhookNotifier POSTs alerts as JSON to a URL
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a WebhookNotifier posting to url
+func NewWebhookNotifier(url string, client *http.Client) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: client}
+}
+
+// Notify posts all the alerts in one request
+func (w *WebhookNotifier) Notify(alerts []Alert) error {
+	var body []WebhookAlert
+
+	for _, a := range alerts {
+		recipes := []string{}
+		for _, r := range a.Recipes {
+			recipes = append(recipes, r.Name)
+		}
+
+		body = append(body, WebhookAlert{
+			Ingredient:     a.Ingredient.Name,
+			BatchID:        a.Ingredient.BatchID,
+			ExpirationDate: a.Ingredient.ExpirationDate,
+			Recipes:        recipes,
+			Message:        a.String(),
+		})
+	}
+
+	payload, err := json.Marshal(body)
+
+	if err != nil {
+		return err
+	}
+
+	res, err := w.client.Post(w.url, "application/json", bytes.NewReader(payload))
+
+	if err != nil {
+		return fmt.Errorf("problem posting alerts to %s, %v", w.url, err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s responded with %d", w.url, res.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package alert_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/google/go-cmp/cmp"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/alert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestNotifiers(t *testing.T) {
+
+	expiry := time.Date(2019, time.January, 10, 0, 0, 0, 0, time.UTC)
+	milk := cookme.PerishableIngredient{Ingredient: cookme.Ingredient{Name: "Milk"}, ExpirationDate: expiry, BatchID: "milk-1"}
+	cheesyMilk := cookme.NewRecipe("Cheesy milk", cookme.Ingredient{Name: "Milk"})
+
+	alerts := []alert.Alert{{Ingredient: milk, Recipes: cookme.Recipes{cheesyMilk}}}
+	wantLine := alerts[0].String() + "\n"
+
+	t.Run("writer notifier writes a line per alert", func(t *testing.T) {
+		out := &bytes.Buffer{}
+
+		if err := alert.NewWriterNotifier(out).Notify(alerts); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		assertStringEqual(t, out.String(), wantLine)
+	})
+
+	t.Run("file notifier appends to the file", func(t *testing.T) {
+		filename := cookme.RandomString() + ".log"
+		defer os.Remove(filename)
+
+		notifier := alert.NewFileNotifier(filename)
+		notifier.Notify(alerts)
+		notifier.Notify(alerts)
+
+		contents, err := ioutil.ReadFile(filename)
+
+		if err != nil {
+			t.Fatalf("problem reading alerts file %v", err)
+		}
+
+		assertStringEqual(t, string(contents), wantLine+wantLine)
+	})
+
+	t.Run("command notifier passes the alert as the last argument", func(t *testing.T) {
+		filename := cookme.RandomString() + ".log"
+		defer os.Remove(filename)
+
+		notifier := alert.NewCommandNotifier("sh", "-c", `printf "%s" "$0" > `+filename)
+
+		if err := notifier.Notify(alerts); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		contents, _ := ioutil.ReadFile(filename)
+
+		assertStringEqual(t, string(contents), alerts[0].String())
+	})
+
+	t.Run("command notifier errors when the command fails", func(t *testing.T) {
+		if err := alert.NewCommandNotifier("false").Notify(alerts); err == nil {
+			t.Error("expected an error but didnt get one")
+		}
+	})
+
+	t.Run("webhook notifier posts the alerts as JSON", func(t *testing.T) {
+		var got []alert.WebhookAlert
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				t.Errorf("expected a POST but got %s", r.Method)
+			}
+			json.NewDecoder(r.Body).Decode(&got)
+		}))
+		defer server.Close()
+
+		if err := alert.NewWebhookNotifier(server.URL, server.Client()).Notify(alerts); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		want := []alert.WebhookAlert{{
+			Ingredient:     "Milk",
+			BatchID:        "milk-1",
+			ExpirationDate: expiry,
+			Recipes:        []string{"Cheesy milk"},
+			Message:        alerts[0].String(),
+		}}
+
+		if !cmp.Equal(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("webhook notifier errors when the webhook does", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		if err := alert.NewWebhookNotifier(server.URL, server.Client()).Notify(alerts); err == nil {
+			t.Error("expected an error but didnt get one")
+		}
+	})
+}
+
+func assertStringEqual(t *testing.T, got, want string) {
+	t.Helper()
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"github.com/quii/monolith-to-micro/auth"
+	"net/http"
+)
+
+// RequireToken only lets through requests with an Authorization: Bearer token, like the gRPC service reading needs a
+// reader and anything else an editor
+func RequireToken(verifier auth.Verifier, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		required := auth.Editor
+
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			required = auth.Reader
+		}
+
+		token, err := auth.Check(verifier, r.Header.Get("Authorization"), required)
+
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), token)))
+	})
+}
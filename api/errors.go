@@ -0,0 +1,56 @@
+package api
+
+import (
+	"github.com/quii/monolith-to-micro/logging"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+)
+
+type errorResponse struct {
+	Code    string
+	Message string
+}
+
+// writeError responds with the HTTP status matching the error's gRPC code. Errors without a code, like the inventory
+// failing to be read, are logged and answered with a 500 which doesn't give away their details
+func writeError(w http.ResponseWriter, err error) {
+	s, ok := status.FromError(err)
+
+	if !ok {
+		logging.Error("problem handling request", "err", err)
+		s = status.New(codes.Internal, "something went wrong reading or writing the data, try again")
+	}
+
+	writeJSON(w, HTTPStatus(s.Code()), errorResponse{Code: s.Code().String(), Message: s.Message()})
+}
+
+// HTTPStatus maps a gRPC code to the closest HTTP status
+func HTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return http.StatusRequestTimeout
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"github.com/quii/monolith-to-micro/auth"
+	"github.com/quii/monolith-to-micro/household"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Households serves each household from its own Server, chosen by the Household header or else the caller's token.
+// Any household can read the recipes of household.Public but inventories and suggestions stay private
+type Households struct {
+	open func(householdID string) (*Server, error)
+
+	mu      sync.Mutex
+	servers map[string]*Server
+}
+
+// NewHouseholds creates a Households which calls open the first time each household is used
+func NewHouseholds(open func(householdID string) (*Server, error)) *Households {
+	return &Households{open: open, servers: make(map[string]*Server)}
+}
+
+// ServeHTTP passes the request on to the household's Server
+func (h *Households) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	readOnly := (r.Method == http.MethodGet || r.Method == http.MethodHead) && readsRecipes(r.URL.Path)
+	id, err := auth.SelectHousehold(r.Context(), r.Header.Get(household.MetadataKey), readOnly)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	server, err := h.server(id)
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	server.ServeHTTP(w, r)
+}
+
+func (h *Households) server(id string) (*Server, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if server, exists := h.servers[id]; exists {
+		return server, nil
+	}
+
+	server, err := h.open(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	h.servers[id] = server
+	return server, nil
+}
+
+// readsRecipes says whether a path only shows recipes, so can be shared
+func readsRecipes(path string) bool {
+	switch strings.Split(strings.Trim(path, "/"), "/")[0] {
+	case "recipes", "matches", "openapi.json":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"io"
+	"net/http"
+)
+
+func (s *Server) openAPI(w http.ResponseWriter, r *http.Request, params []string) error {
+	w.Header().Set("Content-Type", "application/json")
+	_, err := io.WriteString(w, OpenAPI)
+	return err
+}
+
+// OpenAPI describes the HTTP API, it is served at /openapi.json
+const OpenAPI = `{
+  "openapi": "3.0.0",
+  "info": {
+    "title": "Cook me",
+    "description": "Recipes, the ingredients in the house and suggestions of what to cook. Errors use the gRPC code names of the recipe service",
+    "version": "1.0.0"
+  },
+  "paths": {
+    "/recipes": {
+      "get": {
+        "summary": "List recipes",
+        "parameters": [
+          {"name": "q", "in": "query", "description": "only recipes whose name or ingredients contain this", "schema": {"type": "string"}},
+          {"name": "sort", "in": "query", "schema": {"type": "string", "enum": ["added", "name", "rating"], "default": "added"}},
+          {"name": "page_size", "in": "query", "description": "0 returns every recipe", "schema": {"type": "integer", "minimum": 0}},
+          {"name": "page_token", "in": "query", "description": "NextPageToken from the previous page", "schema": {"type": "string"}}
+        ],
+        "responses": {
+          "200": {"description": "a page of recipes", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/RecipesPage"}}}},
+          "400": {"$ref": "#/components/responses/Error"}
+        }
+      },
+      "post": {
+        "summary": "Add a recipe",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Recipe"}}}},
+        "responses": {
+          "201": {"description": "the recipe was added", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Recipe"}}}},
+          "400": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/recipes/{name}": {
+      "parameters": [{"$ref": "#/components/parameters/RecipeName"}],
+      "delete": {
+        "summary": "Delete a recipe",
+        "responses": {
+          "204": {"description": "the recipe is no longer in the book"}
+        }
+      }
+    },
+    "/recipes/{name}/rating": {
+      "parameters": [{"$ref": "#/components/parameters/RecipeName"}],
+      "put": {
+        "summary": "Rate a recipe",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Rating"}}}},
+        "responses": {
+          "204": {"description": "the recipe was rated"},
+          "400": {"$ref": "#/components/responses/Error"},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/recipes/{name}/favourite": {
+      "parameters": [{"$ref": "#/components/parameters/RecipeName"}],
+      "put": {
+        "summary": "Star or unstar a recipe",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Favourite"}}}},
+        "responses": {
+          "204": {"description": "the recipe was changed"},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/matches": {
+      "get": {
+        "summary": "Recipes using any of the ingredients, those using the most of them first",
+        "parameters": [
+          {"name": "ingredient", "in": "query", "required": true, "style": "form", "explode": true, "schema": {"type": "array", "items": {"type": "string"}}}
+        ],
+        "responses": {
+          "200": {"description": "matching recipes", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Match"}}}}},
+          "400": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/ingredients": {
+      "get": {
+        "summary": "List every batch of ingredients in the house, soonest to expire first",
+        "responses": {
+          "200": {"description": "the inventory", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Batch"}}}}}
+        }
+      },
+      "post": {
+        "summary": "Add a batch of an ingredient",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Batch"}}}},
+        "responses": {
+          "201": {"description": "the batch with its ID", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Batch"}}}},
+          "400": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/ingredients/{name}": {
+      "parameters": [{"$ref": "#/components/parameters/IngredientName"}],
+      "delete": {
+        "summary": "Delete every batch of an ingredient",
+        "responses": {
+          "204": {"description": "the ingredient is no longer in the house"}
+        }
+      }
+    },
+    "/ingredients/{name}/batches/{batch-id}": {
+      "parameters": [
+        {"$ref": "#/components/parameters/IngredientName"},
+        {"name": "batch-id", "in": "path", "required": true, "description": "oldest deletes the batch added first", "schema": {"type": "string"}}
+      ],
+      "delete": {
+        "summary": "Delete one batch of an ingredient",
+        "responses": {
+          "204": {"description": "the batch was deleted"},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/suggestions": {
+      "get": {
+        "summary": "Recipes that can be cooked with the ingredients in the house, best first",
+        "parameters": [
+          {"name": "rotate_days", "in": "query", "description": "suggest recipes cooked within this many days last", "schema": {"type": "integer", "default": 3}},
+          {"name": "favourites", "in": "query", "description": "only suggest starred recipes", "schema": {"type": "boolean", "default": false}}
+        ],
+        "responses": {
+          "200": {"description": "recipes to cook", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Recipe"}}}}},
+          "400": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/openapi.json": {
+      "get": {
+        "summary": "This document",
+        "responses": {
+          "200": {"description": "the OpenAPI document", "content": {"application/json": {}}}
+        }
+      }
+    }
+  },
+  "components": {
+    "parameters": {
+      "RecipeName": {"name": "name", "in": "path", "required": true, "schema": {"type": "string"}},
+      "IngredientName": {"name": "name", "in": "path", "required": true, "schema": {"type": "string"}}
+    },
+    "responses": {
+      "Error": {"description": "the request failed", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+    },
+    "schemas": {
+      "Ingredient": {
+        "type": "object",
+        "required": ["Name"],
+        "properties": {"Name": {"type": "string"}}
+      },
+      "Recipe": {
+        "type": "object",
+        "required": ["Name"],
+        "properties": {
+          "Name": {"type": "string"},
+          "Ingredients": {"type": "array", "items": {"$ref": "#/components/schemas/Ingredient"}},
+          "Rating": {"type": "integer", "minimum": 0, "maximum": 5, "description": "0 means not rated"},
+          "Favourite": {"type": "boolean"}
+        }
+      },
+      "RecipesPage": {
+        "type": "object",
+        "properties": {
+          "Recipes": {"type": "array", "items": {"$ref": "#/components/schemas/Recipe"}},
+          "NextPageToken": {"type": "string", "description": "missing on the last page"}
+        }
+      },
+      "Rating": {
+        "type": "object",
+        "required": ["Rating"],
+        "properties": {"Rating": {"type": "integer", "minimum": 1, "maximum": 5}}
+      },
+      "Favourite": {
+        "type": "object",
+        "required": ["Favourite"],
+        "properties": {"Favourite": {"type": "boolean"}}
+      },
+      "Match": {
+        "type": "object",
+        "properties": {
+          "Recipe": {"$ref": "#/components/schemas/Recipe"},
+          "MatchingIngredients": {"type": "integer"}
+        }
+      },
+      "Batch": {
+        "type": "object",
+        "required": ["Name", "ExpirationDate"],
+        "properties": {
+          "Name": {"type": "string"},
+          "ExpirationDate": {"type": "string", "format": "date-time"},
+          "BatchID": {"type": "string", "readOnly": true},
+          "Quantity": {"type": "integer", "minimum": 0, "description": "0 is taken to mean 1"},
+          "Category": {"type": "string"}
+        }
+      },
+      "Error": {
+        "type": "object",
+        "properties": {
+          "Code": {"type": "string", "description": "the gRPC code name, e.g. NotFound or InvalidArgument"},
+          "Message": {"type": "string"}
+        }
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,380 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/history"
+	"github.com/quii/monolith-to-micro/inventory"
+	"github.com/quii/monolith-to-micro/recipe"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecipeBook is what the API needs from the recipes, *recipe.Book implements it
+type RecipeBook interface {
+	recipe.RecipeServiceServer
+	cookme.RecipeRepo
+}
+
+// Server exposes recipes, the inventory and suggestions of what to cook as JSON over HTTP. Recipes go through the same
+// methods as the gRPC service so both report errors the same way
+type Server struct {
+	recipes    RecipeBook
+	inventory  *inventory.HouseInventory
+	cookingLog *history.CookingLog
+	routes     []route
+}
+
+type route struct {
+	method  string
+	pattern []string
+	handle  func(w http.ResponseWriter, r *http.Request, params []string) error
+}
+
+// NewServer creates a Server, it is an http.Handler
+func NewServer(recipes RecipeBook, houseInventory *inventory.HouseInventory, cookingLog *history.CookingLog) *Server {
+	s := &Server{recipes: recipes, inventory: houseInventory, cookingLog: cookingLog}
+
+	s.handle(http.MethodGet, "/recipes", s.listRecipes)
+	s.handle(http.MethodPost, "/recipes", s.addRecipe)
+	s.handle(http.MethodDelete, "/recipes/{name}", s.deleteRecipe)
+	s.handle(http.MethodPut, "/recipes/{name}/rating", s.rateRecipe)
+	s.handle(http.MethodPut, "/recipes/{name}/favourite", s.favouriteRecipe)
+	s.handle(http.MethodGet, "/matches", s.matches)
+	s.handle(http.MethodGet, "/ingredients", s.listIngredients)
+	s.handle(http.MethodPost, "/ingredients", s.addIngredient)
+	s.handle(http.MethodDelete, "/ingredients/{name}", s.deleteIngredient)
+	s.handle(http.MethodDelete, "/ingredients/{name}/batches/{batch-id}", s.deleteBatch)
+	s.handle(http.MethodGet, "/suggestions", s.suggestions)
+	s.handle(http.MethodGet, "/openapi.json", s.openAPI)
+
+	return s
+}
+
+func (s *Server) handle(method string, pattern string, handle func(w http.ResponseWriter, r *http.Request, params []string) error) {
+	s.routes = append(s.routes, route{method: method, pattern: strings.Split(strings.Trim(pattern, "/"), "/"), handle: handle})
+}
+
+// ServeHTTP routes the request, path segments in {} match anything and are passed to the handler unescaped
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/")
+	var allowed []string
+
+	for _, route := range s.routes {
+		params, ok := route.match(segments)
+
+		if !ok {
+			continue
+		}
+
+		if route.method != r.Method {
+			allowed = append(allowed, route.method)
+			continue
+		}
+
+		if err := route.handle(w, r, params); err != nil {
+			writeError(w, err)
+		}
+		return
+	}
+
+	if len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Code: "MethodNotAllowed", Message: r.Method + " is not allowed"})
+		return
+	}
+
+	writeError(w, status.Errorf(codes.NotFound, "no such resource %s", r.URL.Path))
+}
+
+func (rt route) match(segments []string) (params []string, ok bool) {
+	if len(segments) != len(rt.pattern) {
+		return nil, false
+	}
+
+	for i, p := range rt.pattern {
+		if strings.HasPrefix(p, "{") {
+			param, err := url.PathUnescape(segments[i])
+
+			if err != nil {
+				return nil, false
+			}
+
+			params = append(params, param)
+			continue
+		}
+
+		if p != segments[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+type recipesPage struct {
+	Recipes       cookme.Recipes
+	NextPageToken string `json:",omitempty"`
+}
+
+func (s *Server) listRecipes(w http.ResponseWriter, r *http.Request, params []string) error {
+	query := r.URL.Query()
+	req := &recipe.GetRecipesRequest{Query: query.Get("q"), PageToken: query.Get("page_token")}
+
+	if sortBy := query.Get("sort"); sortBy != "" {
+		order, exists := recipe.GetRecipesRequest_SortOrder_value[strings.ToUpper(sortBy)]
+
+		if !exists {
+			return status.Errorf(codes.InvalidArgument, "invalid sort %q, expect added, name or rating", sortBy)
+		}
+
+		req.Sort = recipe.GetRecipesRequest_SortOrder(order)
+	}
+
+	if pageSize := query.Get("page_size"); pageSize != "" {
+		size, err := strconv.Atoi(pageSize)
+
+		if err != nil {
+			return status.Error(codes.InvalidArgument, "page size must be a number")
+		}
+
+		req.PageSize = int32(size)
+	}
+
+	res, err := s.recipes.GetRecipes(r.Context(), req)
+
+	if err != nil {
+		return err
+	}
+
+	page := recipesPage{Recipes: cookme.Recipes{}, NextPageToken: res.NextPageToken}
+
+	for _, rcp := range res.Recipes {
+		page.Recipes = append(page.Recipes, recipe.ConvertRecipeFromGRPC(rcp))
+	}
+
+	writeJSON(w, http.StatusOK, page)
+	return nil
+}
+
+func (s *Server) addRecipe(w http.ResponseWriter, r *http.Request, params []string) error {
+	var newRecipe cookme.Recipe
+
+	if err := decode(r, &newRecipe); err != nil {
+		return err
+	}
+
+	if newRecipe.Name == "" {
+		return status.Error(codes.InvalidArgument, "a recipe needs a name")
+	}
+
+	if _, err := s.recipes.AddRecipe(r.Context(), &recipe.AddRecipeRequest{Recipe: recipe.ConvertRecipeToGRPC(newRecipe)}); err != nil {
+		return err
+	}
+
+	writeJSON(w, http.StatusCreated, newRecipe)
+	return nil
+}
+
+func (s *Server) deleteRecipe(w http.ResponseWriter, r *http.Request, params []string) error {
+	if _, err := s.recipes.DeleteRecipe(r.Context(), &recipe.DeleteRecipeRequest{Name: params[0]}); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+type rating struct {
+	Rating int
+}
+
+func (s *Server) rateRecipe(w http.ResponseWriter, r *http.Request, params []string) error {
+	var body rating
+
+	if err := decode(r, &body); err != nil {
+		return err
+	}
+
+	if _, err := s.recipes.RateRecipe(r.Context(), &recipe.RateRecipeRequest{Name: params[0], Rating: int32(body.Rating)}); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+type favourite struct {
+	Favourite bool
+}
+
+func (s *Server) favouriteRecipe(w http.ResponseWriter, r *http.Request, params []string) error {
+	var body favourite
+
+	if err := decode(r, &body); err != nil {
+		return err
+	}
+
+	if _, err := s.recipes.SetFavourite(r.Context(), &recipe.SetFavouriteRequest{Name: params[0], Favourite: body.Favourite}); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) matches(w http.ResponseWriter, r *http.Request, params []string) error {
+	ingredients := r.URL.Query()["ingredient"]
+
+	if len(ingredients) == 0 {
+		return status.Error(codes.InvalidArgument, "give at least one ingredient")
+	}
+
+	res, err := s.recipes.FindRecipesUsing(r.Context(), &recipe.FindRecipesUsingRequest{Ingredients: ingredients})
+
+	if err != nil {
+		return err
+	}
+
+	matches := []recipe.Match{}
+
+	for _, m := range res.Matches {
+		matches = append(matches, recipe.Match{
+			Recipe:              recipe.ConvertRecipeFromGRPC(m.Recipe),
+			MatchingIngredients: int(m.MatchingIngredients),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, matches)
+	return nil
+}
+
+func (s *Server) listIngredients(w http.ResponseWriter, r *http.Request, params []string) error {
+	found, err := s.inventory.IngredientsContext(r.Context())
+
+	if err != nil {
+		return err
+	}
+
+	ingredients := cookme.PerishableIngredients{}
+	ingredients = append(ingredients, found.SortByExpirationDate()...)
+	writeJSON(w, http.StatusOK, ingredients)
+	return nil
+}
+
+func (s *Server) addIngredient(w http.ResponseWriter, r *http.Request, params []string) error {
+	var ingredient cookme.PerishableIngredient
+
+	if err := decode(r, &ingredient); err != nil {
+		return err
+	}
+
+	if ingredient.Name == "" || ingredient.ExpirationDate.IsZero() {
+		return status.Error(codes.InvalidArgument, "an ingredient needs a name and an expiration date")
+	}
+
+	if ingredient.Quantity < 0 {
+		return status.Error(codes.InvalidArgument, "quantity can't be negative")
+	}
+
+	added, err := s.inventory.AddIngredients(ingredient)
+
+	if err != nil {
+		return err
+	}
+
+	writeJSON(w, http.StatusCreated, added[0])
+	return nil
+}
+
+func (s *Server) deleteIngredient(w http.ResponseWriter, r *http.Request, params []string) error {
+	if err := s.inventory.DeleteIngredient(params[0]); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// deleteBatch removes one batch of an ingredient, the batch ID "oldest" removes the one added first
+func (s *Server) deleteBatch(w http.ResponseWriter, r *http.Request, params []string) error {
+	var err error
+
+	if params[1] == "oldest" {
+		err = s.inventory.DeleteOldest(params[0])
+	} else {
+		err = s.inventory.DeleteBatch(params[0], params[1])
+	}
+
+	if err == inventory.ErrBatchNotFound {
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// suggestions scores recipes the same way as the cookme command
+func (s *Server) suggestions(w http.ResponseWriter, r *http.Request, params []string) error {
+	query := r.URL.Query()
+	rotateDays := 3
+
+	if days := query.Get("rotate_days"); days != "" {
+		var err error
+		rotateDays, err = strconv.Atoi(days)
+
+		if err != nil {
+			return status.Error(codes.InvalidArgument, "rotate days must be a number")
+		}
+	}
+
+	now := time.Now()
+	ingredients, err := s.inventory.IngredientsContext(r.Context())
+
+	if err != nil {
+		return err
+	}
+
+	allRecipes, err := cookme.RecipesContext(r.Context(), s.recipes)
+
+	if err != nil {
+		return err
+	}
+
+	recipes := cookme.ListRecipesContext(r.Context(),
+		cookme.IngredientsRepoFunc(func() cookme.PerishableIngredients { return ingredients }),
+		cookme.RecipeRepoFunc(func() cookme.Recipes { return allRecipes }),
+		cookme.ScoreByRating(0.5),
+		cookme.ScoreByExpiry(ingredients, now, 0.5),
+		cookme.DownRankRecentlyCooked(s.cookingLog.History(), now.Add(-time.Duration(rotateDays)*24*time.Hour)),
+	)
+
+	if query.Get("favourites") == "true" {
+		recipes = recipes.Favourites()
+	}
+
+	writeJSON(w, http.StatusOK, append(cookme.Recipes{}, recipes...))
+	return nil
+}
+
+func decode(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid JSON body, %v", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,474 @@
+package api_test
+
+import (
+	"encoding/json"
+	"github.com/google/go-cmp/cmp"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/api"
+	"github.com/quii/monolith-to-micro/auth"
+	"github.com/quii/monolith-to-micro/bucket"
+	"github.com/quii/monolith-to-micro/history"
+	"github.com/quii/monolith-to-micro/household"
+	"github.com/quii/monolith-to-micro/inventory"
+	"github.com/quii/monolith-to-micro/recipe"
+	"google.golang.org/grpc/codes"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecipesAPI(t *testing.T) {
+
+	milk := cookme.Ingredient{Name: "Milk"}
+	cheese := cookme.Ingredient{Name: "Cheese"}
+	pasta := cookme.Ingredient{Name: "Pasta"}
+
+	macAndCheese := cookme.NewRecipe("Mac and cheese", pasta, cheese)
+	cheesyMilk := cookme.NewRecipe("Cheesy milk", milk, cheese)
+
+	t.Run("recipes added can be listed", func(t *testing.T) {
+		server, cleanup := NewTestServer(t)
+		defer cleanup()
+
+		assertStatus(t, server.do(http.MethodPost, "/recipes", macAndCheese), http.StatusCreated)
+		assertStatus(t, server.do(http.MethodPost, "/recipes", cheesyMilk), http.StatusCreated)
+
+		var page struct{ Recipes cookme.Recipes }
+		decodeBody(t, server.do(http.MethodGet, "/recipes?sort=name", nil), &page)
+
+		cookme.AssertRecipesEqual(t, page.Recipes, cookme.Recipes{cheesyMilk, macAndCheese})
+	})
+
+	t.Run("lists no recipes as an empty list", func(t *testing.T) {
+		server, cleanup := NewTestServer(t)
+		defer cleanup()
+
+		res := server.do(http.MethodGet, "/recipes", nil)
+
+		assertBody(t, res, `{"Recipes":[]}`)
+	})
+
+	t.Run("pages through recipes", func(t *testing.T) {
+		server, cleanup := NewTestServer(t)
+		defer cleanup()
+
+		server.do(http.MethodPost, "/recipes", macAndCheese)
+		server.do(http.MethodPost, "/recipes", cheesyMilk)
+
+		type recipesPage struct {
+			Recipes       cookme.Recipes
+			NextPageToken string
+		}
+
+		var first, last recipesPage
+		decodeBody(t, server.do(http.MethodGet, "/recipes?page_size=1", nil), &first)
+		cookme.AssertRecipesEqual(t, first.Recipes, cookme.Recipes{macAndCheese})
+
+		decodeBody(t, server.do(http.MethodGet, "/recipes?page_size=1&page_token="+first.NextPageToken, nil), &last)
+		cookme.AssertRecipesEqual(t, last.Recipes, cookme.Recipes{cheesyMilk})
+
+		if last.NextPageToken != "" {
+			t.Errorf("expected no more pages but got token %q", last.NextPageToken)
+		}
+	})
+
+	t.Run("deletes recipes with names that need escaping", func(t *testing.T) {
+		server, cleanup := NewTestServer(t)
+		defer cleanup()
+
+		halfAndHalf := cookme.NewRecipe("Half/half", milk)
+		server.do(http.MethodPost, "/recipes", halfAndHalf)
+		server.do(http.MethodPost, "/recipes", cheesyMilk)
+
+		assertStatus(t, server.do(http.MethodDelete, "/recipes/Half%2Fhalf", nil), http.StatusNoContent)
+
+		var page struct{ Recipes cookme.Recipes }
+		decodeBody(t, server.do(http.MethodGet, "/recipes", nil), &page)
+		cookme.AssertRecipesEqual(t, page.Recipes, cookme.Recipes{cheesyMilk})
+	})
+
+	t.Run("rates and stars recipes", func(t *testing.T) {
+		server, cleanup := NewTestServer(t)
+		defer cleanup()
+
+		server.do(http.MethodPost, "/recipes", macAndCheese)
+
+		assertStatus(t, server.do(http.MethodPut, "/recipes/Mac%20and%20cheese/rating", map[string]int{"Rating": 4}), http.StatusNoContent)
+		assertStatus(t, server.do(http.MethodPut, "/recipes/Mac%20and%20cheese/favourite", map[string]bool{"Favourite": true}), http.StatusNoContent)
+
+		var page struct{ Recipes cookme.Recipes }
+		decodeBody(t, server.do(http.MethodGet, "/recipes", nil), &page)
+
+		want := macAndCheese
+		want.Rating = 4
+		want.Favourite = true
+		cookme.AssertRecipesEqual(t, page.Recipes, cookme.Recipes{want})
+	})
+
+	t.Run("finds recipes using ingredients", func(t *testing.T) {
+		server, cleanup := NewTestServer(t)
+		defer cleanup()
+
+		server.do(http.MethodPost, "/recipes", macAndCheese)
+		server.do(http.MethodPost, "/recipes", cheesyMilk)
+
+		var got []recipe.Match
+		decodeBody(t, server.do(http.MethodGet, "/matches?ingredient=milk&ingredient=cheese", nil), &got)
+
+		want := []recipe.Match{
+			{Recipe: cheesyMilk, MatchingIngredients: 2},
+			{Recipe: macAndCheese, MatchingIngredients: 1},
+		}
+
+		if !cmp.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+}
+
+func TestIngredientsAPI(t *testing.T) {
+
+	t.Run("batches added can be listed, soonest to expire first", func(t *testing.T) {
+		server, cleanup := NewTestServer(t)
+		defer cleanup()
+
+		tomorrow := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+		nextWeek := time.Now().Add(7 * 24 * time.Hour).Truncate(time.Second)
+
+		var milk, cheese cookme.PerishableIngredient
+		decodeBody(t, server.do(http.MethodPost, "/ingredients", cookme.Ingredient{Name: "Cheese"}.ExpiresAt(nextWeek)), &cheese)
+		decodeBody(t, server.do(http.MethodPost, "/ingredients", cookme.Ingredient{Name: "Milk"}.ExpiresAt(tomorrow)), &milk)
+
+		if milk.BatchID == "" || milk.Quantity != 1 {
+			t.Errorf("expected the added batch to have an ID and a quantity of 1 but got %+v", milk)
+		}
+
+		var got cookme.PerishableIngredients
+		decodeBody(t, server.do(http.MethodGet, "/ingredients", nil), &got)
+
+		assertIngredientsEqual(t, got, cookme.PerishableIngredients{milk, cheese})
+	})
+
+	t.Run("deletes one batch or all of an ingredient", func(t *testing.T) {
+		server, cleanup := NewTestServer(t)
+		defer cleanup()
+
+		milk := cookme.Ingredient{Name: "Milk"}.ExpiresAt(time.Now().Add(24 * time.Hour))
+
+		var first, second cookme.PerishableIngredient
+		decodeBody(t, server.do(http.MethodPost, "/ingredients", milk), &first)
+		decodeBody(t, server.do(http.MethodPost, "/ingredients", milk), &second)
+
+		assertStatus(t, server.do(http.MethodDelete, "/ingredients/Milk/batches/oldest", nil), http.StatusNoContent)
+
+		var got cookme.PerishableIngredients
+		decodeBody(t, server.do(http.MethodGet, "/ingredients", nil), &got)
+		assertIngredientsEqual(t, got, cookme.PerishableIngredients{second})
+
+		assertStatus(t, server.do(http.MethodDelete, "/ingredients/Milk", nil), http.StatusNoContent)
+
+		assertBody(t, server.do(http.MethodGet, "/ingredients", nil), `[]`)
+	})
+
+	t.Run("suggests recipes that can be cooked", func(t *testing.T) {
+		server, cleanup := NewTestServer(t)
+		defer cleanup()
+
+		cheeseOnToast := cookme.NewRecipe("Cheese on toast", cookme.Ingredient{Name: "Cheese"}, cookme.Ingredient{Name: "Bread"})
+		server.do(http.MethodPost, "/recipes", cheeseOnToast)
+		server.do(http.MethodPost, "/recipes", cookme.NewRecipe("Omelette", cookme.Ingredient{Name: "Eggs"}))
+		server.do(http.MethodPost, "/ingredients", cookme.Ingredient{Name: "Cheese"}.ExpiresAt(time.Now().Add(24*time.Hour)))
+		server.do(http.MethodPost, "/ingredients", cookme.Ingredient{Name: "Bread"}.ExpiresAt(time.Now().Add(24*time.Hour)))
+
+		var got cookme.Recipes
+		decodeBody(t, server.do(http.MethodGet, "/suggestions", nil), &got)
+
+		cookme.AssertRecipesEqual(t, got, cookme.Recipes{cheeseOnToast})
+	})
+}
+
+func TestAPIErrors(t *testing.T) {
+
+	cases := []struct {
+		name       string
+		method     string
+		path       string
+		body       interface{}
+		wantStatus int
+		wantCode   string
+	}{
+		{"unknown resource", http.MethodGet, "/nope", nil, http.StatusNotFound, "NotFound"},
+		{"invalid sort", http.MethodGet, "/recipes?sort=tastiest", nil, http.StatusBadRequest, "InvalidArgument"},
+		{"invalid page token", http.MethodGet, "/recipes?page_token=nope", nil, http.StatusBadRequest, "InvalidArgument"},
+		{"recipe without a name", http.MethodPost, "/recipes", cookme.Recipe{}, http.StatusBadRequest, "InvalidArgument"},
+		{"rating a missing recipe", http.MethodPut, "/recipes/nope/rating", map[string]int{"Rating": 3}, http.StatusNotFound, "NotFound"},
+		{"rating out of range", http.MethodPut, "/recipes/nope/rating", map[string]int{"Rating": 9}, http.StatusBadRequest, "InvalidArgument"},
+		{"deleting a missing batch", http.MethodDelete, "/ingredients/Milk/batches/nope", nil, http.StatusNotFound, "NotFound"},
+		{"ingredient without an expiration date", http.MethodPost, "/ingredients", cookme.Ingredient{Name: "Milk"}, http.StatusBadRequest, "InvalidArgument"},
+		{"matches without ingredients", http.MethodGet, "/matches", nil, http.StatusBadRequest, "InvalidArgument"},
+		{"wrong method", http.MethodPatch, "/recipes", nil, http.StatusMethodNotAllowed, "MethodNotAllowed"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server, cleanup := NewTestServer(t)
+			defer cleanup()
+
+			res := server.do(c.method, c.path, c.body)
+			assertStatus(t, res, c.wantStatus)
+
+			var got struct{ Code string }
+			decodeBody(t, res, &got)
+
+			if got.Code != c.wantCode {
+				t.Errorf("got code %q, want %q", got.Code, c.wantCode)
+			}
+		})
+	}
+
+	t.Run("invalid JSON is a bad request", func(t *testing.T) {
+		server, cleanup := NewTestServer(t)
+		defer cleanup()
+
+		req := httptest.NewRequest(http.MethodPost, "/recipes", strings.NewReader("{"))
+		res := httptest.NewRecorder()
+		server.ServeHTTP(res, req)
+
+		assertStatus(t, res, http.StatusBadRequest)
+	})
+
+	t.Run("an unreadable inventory is an internal error rather than empty", func(t *testing.T) {
+		dbFilename := cookme.RandomString() + ".db"
+		defer os.Remove(dbFilename)
+
+		book, _ := recipe.NewBook(dbFilename)
+		houseInventory, _ := inventory.NewHouseInventory(dbFilename)
+		cookingLog, _ := history.NewCookingLog(dbFilename)
+		server := testServer{api.NewServer(book, houseInventory, cookingLog)}
+
+		inventoryBucket, _ := bucket.NewBoltBucket(dbFilename, household.Bucket("inventory", household.Default))
+		inventoryBucket.Put([]byte("not json"))
+
+		for _, path := range []string{"/ingredients", "/suggestions"} {
+			res := server.do(http.MethodGet, path, nil)
+			assertStatus(t, res, http.StatusInternalServerError)
+
+			if strings.Contains(res.Body.String(), dbFilename) || strings.Contains(res.Body.String(), "json") {
+				t.Errorf("expected the error's details to be kept from the client but got %s", res.Body.String())
+			}
+		}
+	})
+}
+
+func TestOpenAPI(t *testing.T) {
+	server, cleanup := NewTestServer(t)
+	defer cleanup()
+
+	var document struct {
+		Paths map[string]map[string]json.RawMessage
+	}
+	decodeBody(t, server.do(http.MethodGet, "/openapi.json", nil), &document)
+
+	if len(document.Paths) == 0 {
+		t.Fatal("expected the document to describe some paths")
+	}
+
+	for path, operations := range document.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+
+			t.Run(method+" "+path, func(t *testing.T) {
+				res := server.do(strings.ToUpper(method), strings.NewReplacer("{name}", "x", "{batch-id}", "x").Replace(path), nil)
+
+				var got struct{ Message string }
+				json.NewDecoder(res.Body).Decode(&got)
+
+				if res.Code == http.StatusMethodNotAllowed || strings.HasPrefix(got.Message, "no such resource") {
+					t.Errorf("documented operation isn't served, got %d %s", res.Code, got.Message)
+				}
+			})
+		}
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	cases := map[codes.Code]int{
+		codes.OK:                http.StatusOK,
+		codes.InvalidArgument:   http.StatusBadRequest,
+		codes.NotFound:          http.StatusNotFound,
+		codes.Unauthenticated:   http.StatusUnauthorized,
+		codes.PermissionDenied:  http.StatusForbidden,
+		codes.ResourceExhausted: http.StatusTooManyRequests,
+		codes.Unavailable:       http.StatusServiceUnavailable,
+		codes.DeadlineExceeded:  http.StatusGatewayTimeout,
+		codes.Unknown:           http.StatusInternalServerError,
+	}
+
+	for code, want := range cases {
+		if got := api.HTTPStatus(code); got != want {
+			t.Errorf("got %d for %v, want %d", got, code, want)
+		}
+	}
+}
+
+func TestRequireToken(t *testing.T) {
+	server, cleanup := NewTestServer(t)
+	defer cleanup()
+
+	verifier := auth.VerifierFunc(func(secret string) (auth.Token, bool) {
+		return auth.Token{Household: "home", Role: auth.Role(secret)}, secret == "reader" || secret == "editor"
+	})
+
+	handler := api.RequireToken(verifier, server)
+
+	cases := []struct {
+		name   string
+		method string
+		token  string
+		want   int
+	}{
+		{name: "no token", method: http.MethodGet, want: http.StatusUnauthorized},
+		{name: "unknown token", method: http.MethodGet, token: "guess", want: http.StatusUnauthorized},
+		{name: "reader reading", method: http.MethodGet, token: "reader", want: http.StatusOK},
+		{name: "reader adding", method: http.MethodPost, token: "reader", want: http.StatusForbidden},
+		{name: "editor adding", method: http.MethodPost, token: "editor", want: http.StatusCreated},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, "/recipes", strings.NewReader(`{"Name": "Toast", "Ingredients": [{"Name": "Bread"}]}`))
+
+			if c.token != "" {
+				req.Header.Set("Authorization", "Bearer "+c.token)
+			}
+
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			assertStatus(t, res, c.want)
+		})
+	}
+}
+
+func TestHouseholds(t *testing.T) {
+	dbFilename := cookme.RandomString() + ".db"
+	defer os.Remove(dbFilename)
+
+	households := api.NewHouseholds(func(householdID string) (*api.Server, error) {
+		book, _ := recipe.NewBookFor(dbFilename, householdID)
+		houseInventory, _ := inventory.NewHouseInventoryFor(dbFilename, householdID)
+		cookingLog, _ := history.NewCookingLogFor(dbFilename, householdID)
+		return api.NewServer(book, houseInventory, cookingLog), nil
+	})
+
+	do := func(method, path, requested, tokenHousehold, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+
+		if requested != "" {
+			req.Header.Set("household", requested)
+		}
+
+		if tokenHousehold != "" {
+			req = req.WithContext(auth.NewContext(req.Context(), auth.Token{Household: tokenHousehold, Role: auth.Editor}))
+		}
+
+		res := httptest.NewRecorder()
+		households.ServeHTTP(res, req)
+		return res
+	}
+
+	toast := `{"Name": "Toast", "Ingredients": [{"Name": "Bread"}]}`
+
+	assertStatus(t, do(http.MethodPost, "/recipes", "", "home", toast), http.StatusCreated)
+	assertBody(t, do(http.MethodGet, "/recipes", "office", "", ""), `{"Recipes":[]}`)
+	assertStatus(t, do(http.MethodGet, "/recipes", "office", "home", ""), http.StatusForbidden)
+	assertStatus(t, do(http.MethodGet, "/recipes", "Not Valid", "", ""), http.StatusBadRequest)
+
+	assertStatus(t, do(http.MethodPost, "/recipes", "public", "", toast), http.StatusCreated)
+	assertStatus(t, do(http.MethodGet, "/recipes", "public", "home", ""), http.StatusOK)
+	assertStatus(t, do(http.MethodPost, "/recipes", "public", "home", toast), http.StatusForbidden)
+	assertStatus(t, do(http.MethodGet, "/ingredients", "public", "home", ""), http.StatusForbidden)
+
+	var page struct{ Recipes cookme.Recipes }
+	decodeBody(t, do(http.MethodGet, "/recipes", "", "home", ""), &page)
+	cookme.AssertRecipesEqual(t, page.Recipes, cookme.Recipes{cookme.NewRecipe("Toast", cookme.Ingredient{Name: "Bread"})})
+}
+
+type testServer struct {
+	*api.Server
+}
+
+func (s testServer) do(method, path string, body interface{}) *httptest.ResponseRecorder {
+	var reader io.Reader
+
+	if body != nil {
+		b, _ := json.Marshal(body)
+		reader = strings.NewReader(string(b))
+	}
+
+	res := httptest.NewRecorder()
+	s.ServeHTTP(res, httptest.NewRequest(method, path, reader))
+	return res
+}
+
+func assertStatus(t *testing.T, res *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if res.Code != want {
+		t.Errorf("got status %d, want %d, body %s", res.Code, want, res.Body.String())
+	}
+}
+
+func assertBody(t *testing.T, res *httptest.ResponseRecorder, want string) {
+	t.Helper()
+	if got := strings.TrimSpace(res.Body.String()); got != want {
+		t.Errorf("got body %s, want %s", got, want)
+	}
+}
+
+func assertIngredientsEqual(t *testing.T, got, want cookme.PerishableIngredients) {
+	t.Helper()
+	if !cmp.Equal(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func decodeBody(t *testing.T, res *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		t.Fatalf("problem decoding body %v", err)
+	}
+}
+
+func NewTestServer(t *testing.T) (server testServer, cleanup func()) {
+	t.Helper()
+	dbFilename := cookme.RandomString() + ".db"
+
+	book, err := recipe.NewBook(dbFilename)
+
+	if err != nil {
+		log.Fatalf("problem creating db %+v", err)
+	}
+
+	houseInventory, err := inventory.NewHouseInventory(dbFilename)
+
+	if err != nil {
+		log.Fatalf("problem creating db %+v", err)
+	}
+
+	cookingLog, err := history.NewCookingLog(dbFilename)
+
+	if err != nil {
+		log.Fatalf("problem creating db %+v", err)
+	}
+
+	return testServer{api.NewServer(book, houseInventory, cookingLog)}, func() {
+		os.Remove(dbFilename)
+	}
+}
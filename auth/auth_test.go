@@ -0,0 +1,303 @@
+package auth_test
+
+import (
+	"context"
+	"github.com/boltdb/bolt"
+	"github.com/quii/monolith-to-micro/auth"
+	"github.com/quii/monolith-to-micro/auth/authtest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTokens(t *testing.T) {
+
+	now := time.Date(2019, time.March, 10, 18, 0, 0, 0, time.UTC)
+
+	t.Run("verifies the secret of a created token", func(t *testing.T) {
+		tokens, cleanup := authtest.NewTokens(t)
+		defer cleanup()
+
+		secret, created, err := tokens.Create("home", auth.Editor, now)
+
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		got, ok := tokens.Verify(secret)
+
+		if !ok || got != created {
+			t.Errorf("got %+v %v, want %+v", got, ok, created)
+		}
+
+		if got.Household != "home" || got.Role != auth.Editor {
+			t.Errorf("got %+v, want an editor token for home", got)
+		}
+	})
+
+	t.Run("doesn't keep the secret", func(t *testing.T) {
+		tokens, cleanup := authtest.NewTokens(t)
+		defer cleanup()
+
+		secret, created, _ := tokens.Create("home", auth.Reader, now)
+
+		if created.Hash == secret || created.ID == secret {
+			t.Errorf("expected only a hash of the secret to be kept but got %+v", created)
+		}
+	})
+
+	t.Run("doesn't verify unknown secrets", func(t *testing.T) {
+		tokens, cleanup := authtest.NewTokens(t)
+		defer cleanup()
+
+		tokens.Create("home", auth.Reader, now)
+
+		if _, ok := tokens.Verify("guess"); ok {
+			t.Error("expected an unknown secret not to be verified")
+		}
+	})
+
+	t.Run("revoked tokens can't be used", func(t *testing.T) {
+		tokens, cleanup := authtest.NewTokens(t)
+		defer cleanup()
+
+		secret, created, _ := tokens.Create("home", auth.Reader, now)
+		otherSecret, _, _ := tokens.Create("office", auth.Reader, now)
+
+		if err := tokens.Revoke(created.ID); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		if _, ok := tokens.Verify(secret); ok {
+			t.Error("expected a revoked token not to be verified")
+		}
+
+		if _, ok := tokens.Verify(otherSecret); !ok {
+			t.Error("expected the other token to still be verified")
+		}
+	})
+
+	t.Run("revoking an unknown token is an error", func(t *testing.T) {
+		tokens, cleanup := authtest.NewTokens(t)
+		defer cleanup()
+
+		if err := tokens.Revoke("nope"); err != auth.ErrTokenNotFound {
+			t.Errorf("got %v, want %v", err, auth.ErrTokenNotFound)
+		}
+	})
+
+	t.Run("a token needs a valid household and a known role", func(t *testing.T) {
+		tokens, cleanup := authtest.NewTokens(t)
+		defer cleanup()
+
+		if _, _, err := tokens.Create("", auth.Reader, now); err == nil {
+			t.Error("expected an error for a missing household")
+		}
+
+		if _, _, err := tokens.Create("../recipes", auth.Reader, now); err == nil {
+			t.Error("expected an error for an invalid household")
+		}
+
+		if _, _, err := tokens.Create("home", auth.Role("owner"), now); err == nil {
+			t.Error("expected an error for an unknown role")
+		}
+
+		if len(tokens.List()) != 0 {
+			t.Errorf("expected no tokens but got %v", tokens.List())
+		}
+	})
+
+	t.Run("keeps every token created at the same time by stores sharing a db", func(t *testing.T) {
+		tokens, other, _, cleanup := newTestTokenStores(t)
+		defer cleanup()
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 3; i++ {
+			for _, store := range []*auth.Tokens{tokens, other} {
+				wg.Add(1)
+				go func(store *auth.Tokens) {
+					defer wg.Done()
+					if _, _, err := store.Create("home", auth.Reader, now); err != nil {
+						t.Errorf("unexpected error %v", err)
+					}
+				}(store)
+			}
+		}
+
+		wg.Wait()
+
+		if got := len(tokens.List()); got != 6 {
+			t.Errorf("got %d tokens, want 6", got)
+		}
+	})
+
+	t.Run("doesn't verify a token revoked by another store sharing the db, like the CLI", func(t *testing.T) {
+		tokens, cli, _, cleanup := newTestTokenStores(t)
+		defer cleanup()
+
+		secret, created, _ := cli.Create("home", auth.Reader, now)
+
+		if _, ok := tokens.Verify(secret); !ok {
+			t.Fatal("expected the token to be verified before it's revoked")
+		}
+
+		if err := cli.Revoke(created.ID); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		if _, ok := tokens.Verify(secret); ok {
+			t.Error("expected a token revoked by the other store not to be verified")
+		}
+	})
+
+	t.Run("verifies without opening the db while it hasn't been modified", func(t *testing.T) {
+		tokens, _, dbFilename, cleanup := newTestTokenStores(t)
+		defer cleanup()
+
+		secret, _, _ := tokens.Create("home", auth.Reader, now)
+		lastHour := time.Now().Add(-time.Hour)
+
+		if err := os.Chtimes(dbFilename, lastHour, lastHour); err != nil {
+			t.Fatalf("problem changing the db's modification time %v", err)
+		}
+
+		tokens.Verify(secret)
+
+		db, err := bolt.Open(dbFilename, 0600, &bolt.Options{Timeout: time.Second})
+
+		if err != nil {
+			t.Fatalf("problem opening db %v", err)
+		}
+		defer db.Close()
+
+		if _, ok := tokens.Verify(secret); !ok {
+			t.Error("expected the token to be verified while the db is locked")
+		}
+	})
+}
+
+func TestCheck(t *testing.T) {
+
+	reader := auth.Token{ID: "r", Household: "home", Role: auth.Reader}
+	editor := auth.Token{ID: "e", Household: "home", Role: auth.Editor}
+
+	verifier := auth.VerifierFunc(func(secret string) (auth.Token, bool) {
+		switch secret {
+		case "reader":
+			return reader, true
+		case "editor":
+			return editor, true
+		default:
+			return auth.Token{}, false
+		}
+	})
+
+	cases := []struct {
+		name     string
+		header   string
+		required auth.Role
+		want     codes.Code
+	}{
+		{name: "no token", header: "", required: auth.Reader, want: codes.Unauthenticated},
+		{name: "not a bearer token", header: "Basic cmVhZGVy", required: auth.Reader, want: codes.Unauthenticated},
+		{name: "unknown token", header: "Bearer guess", required: auth.Reader, want: codes.Unauthenticated},
+		{name: "reader reading", header: "Bearer reader", required: auth.Reader, want: codes.OK},
+		{name: "reader editing", header: "Bearer reader", required: auth.Editor, want: codes.PermissionDenied},
+		{name: "editor reading", header: "Bearer editor", required: auth.Reader, want: codes.OK},
+		{name: "editor editing", header: "Bearer editor", required: auth.Editor, want: codes.OK},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := auth.Check(verifier, c.header, c.required)
+
+			if got := status.Code(err); got != c.want {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+
+	verifier := auth.VerifierFunc(func(secret string) (auth.Token, bool) {
+		return auth.Token{ID: "r", Household: "home", Role: auth.Reader}, secret == "reader"
+	})
+
+	interceptor := auth.UnaryServerInterceptor(verifier, auth.Policy{
+		"/Service/Read":  auth.Reader,
+		"/Service/Check": auth.Anyone,
+	})
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "called", nil
+	}
+
+	withToken := metadata.NewIncomingContext(context.Background(), metadata.Pairs(auth.MetadataKey, "Bearer reader"))
+
+	cases := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		want   codes.Code
+	}{
+		{name: "listed method with a token", ctx: withToken, method: "/Service/Read", want: codes.OK},
+		{name: "listed method without a token", ctx: context.Background(), method: "/Service/Read", want: codes.Unauthenticated},
+		{name: "method anyone can call", ctx: context.Background(), method: "/Service/Check", want: codes.OK},
+		{name: "method missing from the policy", ctx: withToken, method: "/Service/Forgotten", want: codes.PermissionDenied},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := interceptor(c.ctx, nil, &grpc.UnaryServerInfo{FullMethod: c.method}, handler)
+
+			if got := status.Code(err); got != c.want {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	token := auth.Token{ID: "r", Household: "home", Role: auth.Reader}
+
+	if _, ok := auth.FromContext(context.Background()); ok {
+		t.Error("expected no token in an empty context")
+	}
+
+	if got, ok := auth.FromContext(auth.NewContext(context.Background(), token)); !ok || got != token {
+		t.Errorf("got %+v %v, want %+v", got, ok, token)
+	}
+}
+
+// newTestTokenStores are two token stores sharing a db in a temporary directory, like a server and the CLI
+func newTestTokenStores(t *testing.T) (tokens, other *auth.Tokens, dbFilename string, cleanup func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "auth")
+
+	if err != nil {
+		t.Fatalf("problem creating temporary directory %+v", err)
+	}
+
+	dbFilename = filepath.Join(dir, "tokens.db")
+	tokens, err = auth.NewTokens(dbFilename)
+
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("problem creating db %+v", err)
+	}
+
+	other, _ = auth.NewTokens(dbFilename)
+
+	return tokens, other, dbFilename, func() {
+		os.RemoveAll(dir)
+	}
+}
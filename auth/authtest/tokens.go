@@ -0,0 +1,31 @@
+// Package authtest makes token stores for tests, on databases in temporary directories
+package authtest
+
+import (
+	"github.com/quii/monolith-to-micro/auth"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// NewTokens is an empty token store on a database in a temporary directory. Cleaning up removes the directory
+func NewTokens(t testing.TB) (tokens *auth.Tokens, cleanup func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "authtest")
+
+	if err != nil {
+		t.Fatalf("problem creating temporary directory %+v", err)
+	}
+
+	tokens, err = auth.NewTokens(filepath.Join(dir, "tokens.db"))
+
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("problem creating db %+v", err)
+	}
+
+	return tokens, func() {
+		os.RemoveAll(dir)
+	}
+}
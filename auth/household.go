@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"context"
+	"github.com/quii/monolith-to-micro/household"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SelectHousehold returns the household a call is for, the requested one or if that's empty the household of the
+// caller's token, or household.Default when tokens aren't required. Callers with a token can only use their own
+// household, apart from reading the household.Public household's shared data when readOnly
+func SelectHousehold(ctx context.Context, requested string, readOnly bool) (string, error) {
+	token, authenticated := FromContext(ctx)
+
+	if requested == "" {
+		if authenticated {
+			return token.Household, nil
+		}
+		return household.Default, nil
+	}
+
+	if err := household.Valid(requested); err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if !authenticated || token.Household == requested || requested == household.Public && readOnly {
+		return requested, nil
+	}
+
+	return "", status.Errorf(codes.PermissionDenied, "this token is for %s so can't use %s", token.Household, requested)
+}
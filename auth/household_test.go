@@ -0,0 +1,48 @@
+package auth_test
+
+import (
+	"context"
+	"github.com/quii/monolith-to-micro/auth"
+	"github.com/quii/monolith-to-micro/household"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestSelectHousehold(t *testing.T) {
+
+	anonymous := context.Background()
+	home := auth.NewContext(context.Background(), auth.Token{Household: "home", Role: auth.Editor})
+
+	cases := []struct {
+		name      string
+		ctx       context.Context
+		requested string
+		readOnly  bool
+		want      string
+		wantCode  codes.Code
+	}{
+		{name: "nothing asked for without a token", ctx: anonymous, want: household.Default},
+		{name: "nothing asked for with a token", ctx: home, want: "home"},
+		{name: "any household without a token", ctx: anonymous, requested: "office", want: "office"},
+		{name: "the token's household", ctx: home, requested: "home", want: "home"},
+		{name: "another household", ctx: home, requested: "office", wantCode: codes.PermissionDenied},
+		{name: "reading public", ctx: home, requested: household.Public, readOnly: true, want: household.Public},
+		{name: "changing public", ctx: home, requested: household.Public, wantCode: codes.PermissionDenied},
+		{name: "an invalid household", ctx: anonymous, requested: "../tokens", wantCode: codes.InvalidArgument},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := auth.SelectHousehold(c.ctx, c.requested, c.readOnly)
+
+			if code := status.Code(err); code != c.wantCode {
+				t.Fatalf("got status %v, want %v", code, c.wantCode)
+			}
+
+			if got != c.want {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
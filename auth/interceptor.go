@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+// MetadataKey is the gRPC metadata holding the token, as "Bearer <secret>"
+const MetadataKey = "authorization"
+
+const bearer = "Bearer "
+
+// Policy is the least powerful role allowed to call each method, keyed by full method name e.g. /RecipeService/GetRecipes.
+// Methods not in the policy can't be called, so list the ones that don't need a token, like health checks, as Anyone
+type Policy map[string]Role
+
+// Anyone is the role in a Policy for methods which can be called without a token
+const Anyone Role = ""
+
+type tokenKey struct{}
+
+// NewContext returns a copy of ctx carrying the token the call was made with
+func NewContext(ctx context.Context, token Token) context.Context {
+	return context.WithValue(ctx, tokenKey{}, token)
+}
+
+// FromContext returns the token the call was made with
+func FromContext(ctx context.Context) (Token, bool) {
+	token, ok := ctx.Value(tokenKey{}).(Token)
+	return token, ok
+}
+
+// UnaryServerInterceptor rejects calls without a token allowed to call the method by policy, returning Unauthenticated
+// when the token is missing or unknown and PermissionDenied when its role isn't enough or the method isn't in the policy
+func UnaryServerInterceptor(verifier Verifier, policy Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authorize(ctx, verifier, policy, info.FullMethod)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the streaming version of UnaryServerInterceptor
+func StreamServerInterceptor(verifier Verifier, policy Policy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(stream.Context(), verifier, policy, info.FullMethod)
+
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &authorizedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (a *authorizedStream) Context() context.Context {
+	return a.ctx
+}
+
+func authorize(ctx context.Context, verifier Verifier, policy Policy, method string) (context.Context, error) {
+	required, listed := policy[method]
+
+	if !listed {
+		return ctx, status.Errorf(codes.PermissionDenied, "%s isn't in the policy so can't be called", method)
+	}
+
+	if required == Anyone {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	var header string
+
+	if values := md.Get(MetadataKey); len(values) > 0 {
+		header = values[0]
+	}
+
+	token, err := Check(verifier, header, required)
+
+	if err != nil {
+		return ctx, err
+	}
+
+	return NewContext(ctx, token), nil
+}
+
+// Check finds the token in an authorization header and makes sure it has the required role, returning a status error
+// if not
+func Check(verifier Verifier, header string, required Role) (Token, error) {
+	if !strings.HasPrefix(header, bearer) {
+		return Token{}, status.Error(codes.Unauthenticated, "missing token, expect an authorization of Bearer <token>")
+	}
+
+	token, ok := verifier.Verify(strings.TrimPrefix(header, bearer))
+
+	if !ok {
+		return Token{}, status.Error(codes.Unauthenticated, "unknown or revoked token")
+	}
+
+	if !token.Role.Allows(required) {
+		return Token{}, status.Errorf(codes.PermissionDenied, "a %s token is needed but this token is a %s", required, token.Role)
+	}
+
+	return token, nil
+}
+
+// Credentials sends a token with every call
+type Credentials struct {
+	Secret string
+}
+
+// GetRequestMetadata adds the token to the call's metadata
+func (c Credentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{MetadataKey: bearer + c.Secret}, nil
+}
+
+// RequireTransportSecurity allows tokens to be sent in plain text, so they can be used when trying things out locally.
+// Use TLS anywhere else
+func (c Credentials) RequireTransportSecurity() bool {
+	return false
+}
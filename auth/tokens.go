@@ -0,0 +1,288 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/quii/monolith-to-micro/bucket"
+	"github.com/quii/monolith-to-micro/household"
+	"github.com/quii/monolith-to-micro/logging"
+	"os"
+	"sync"
+	"time"
+)
+
+// Role is what a token is allowed to do
+type Role string
+
+// The roles a token can have, an editor can do everything a reader can
+const (
+	Reader Role = "reader"
+	Editor Role = "editor"
+)
+
+// Roles lists every Role, least powerful first
+var Roles = []Role{Reader, Editor}
+
+// ParseRole returns the Role called name
+func ParseRole(name string) (Role, error) {
+	for _, r := range Roles {
+		if string(r) == name {
+			return r, nil
+		}
+	}
+	return "", fmt.Errorf("unknown role %q, expect reader or editor", name)
+}
+
+// Allows says whether the role can do what needs the required role
+func (r Role) Allows(required Role) bool {
+	return r.rank() >= required.rank()
+}
+
+func (r Role) rank() int {
+	for i, role := range Roles {
+		if role == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// Token lets the holder of its secret use a household's data. Only a hash of the secret is kept
+type Token struct {
+	ID        string
+	Household string
+	Role      Role
+	Hash      string
+	CreatedAt time.Time
+}
+
+// Verifier finds the token a secret belongs to
+type Verifier interface {
+	Verify(secret string) (Token, bool)
+}
+
+// VerifierFunc allows you to use a function as a Verifier
+type VerifierFunc func(secret string) (Token, bool)
+
+// Verify finds the token a secret belongs to
+func (v VerifierFunc) Verify(secret string) (Token, bool) {
+	return v(secret)
+}
+
+// ErrTokenNotFound is returned when revoking a token that doesn't exist
+var ErrTokenNotFound = errors.New("could not find a token with that ID")
+
+// Tokens stores tokens, persisting them in the filesystem
+type Tokens struct {
+	boltBucket *bucket.BoltBucket
+	dbFilename string
+
+	mu       sync.Mutex
+	verified []Token
+	modTime  time.Time
+}
+
+const bucketName = "tokens"
+
+const modTimeResolution = time.Second
+
+// NewTokens creates a new token store, creating the db file if needed
+func NewTokens(dbFilename string) (*Tokens, error) {
+	boltBucket, err := bucket.NewBoltBucket(dbFilename, bucketName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tokens{boltBucket: boltBucket, dbFilename: dbFilename}, nil
+}
+
+// List returns every token, oldest first
+func (t *Tokens) List() []Token {
+	data, err := t.boltBucket.Get()
+
+	if err != nil {
+		logging.Error("problem getting tokens", "err", err)
+		return nil
+	}
+
+	tokens, err := tokensFromJSON(data)
+
+	if err != nil {
+		logging.Error("problem reading tokens", "err", err)
+		return nil
+	}
+
+	return tokens
+}
+
+// Create makes a token for a household with role, returning the secret to give to whoever will use it. The secret
+// can't be retrieved again
+func (t *Tokens) Create(householdID string, role Role, now time.Time) (secret string, token Token, err error) {
+	if err := household.Valid(householdID); err != nil {
+		return "", Token{}, err
+	}
+
+	if role.rank() < 0 {
+		return "", Token{}, fmt.Errorf("unknown role %q", role)
+	}
+
+	b := make([]byte, 24)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", Token{}, err
+	}
+
+	secret = hex.EncodeToString(b)
+	hash := hashSecret(secret)
+
+	token = Token{
+		ID:        hash[:8],
+		Household: householdID,
+		Role:      role,
+		Hash:      hash,
+		CreatedAt: now,
+	}
+
+	err = t.change(func(tokens []Token) ([]Token, error) {
+		return append(tokens, token), nil
+	})
+
+	if err != nil {
+		return "", Token{}, err
+	}
+
+	return secret, token, nil
+}
+
+// Revoke stops the token with id from being used
+func (t *Tokens) Revoke(id string) error {
+	return t.change(func(tokens []Token) ([]Token, error) {
+		var kept []Token
+
+		for _, token := range tokens {
+			if token.ID != id {
+				kept = append(kept, token)
+			}
+		}
+
+		if len(kept) == len(tokens) {
+			return nil, ErrTokenNotFound
+		}
+
+		return kept, nil
+	})
+}
+
+// Verify finds the token a secret belongs to
+func (t *Tokens) Verify(secret string) (Token, bool) {
+	tokens, err := t.verifiable()
+
+	if err != nil {
+		logging.Error("problem reading tokens to verify a secret", "err", err)
+		return Token{}, false
+	}
+
+	hash := []byte(hashSecret(secret))
+
+	for _, token := range tokens {
+		if subtle.ConstantTimeCompare(hash, []byte(token.Hash)) == 1 {
+			return token, true
+		}
+	}
+
+	return Token{}, false
+}
+
+// verifiable returns the tokens Verify checks secrets against. They're only read again when the db file has been
+// modified, by this store or another process like the CLI, so verifying every call doesn't open the db every time
+func (t *Tokens) verifiable() ([]Token, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	checkedAt := time.Now()
+	info, err := os.Stat(t.dbFilename)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if t.verified != nil && info.ModTime().Equal(t.modTime) {
+		return t.verified, nil
+	}
+
+	data, err := t.boltBucket.Get()
+
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := tokensFromJSON(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// some file systems only keep modification times to the second, so a file modified just before it was read could
+	// be modified again without its time changing. Only keep what was read once that can't happen
+	if checkedAt.Sub(info.ModTime()) > modTimeResolution {
+		t.verified, t.modTime = append([]Token{}, tokens...), info.ModTime()
+	}
+
+	return tokens, nil
+}
+
+// change replaces the tokens with what edit makes of them, in one transaction so concurrent changes aren't lost
+func (t *Tokens) change(edit func(tokens []Token) ([]Token, error)) error {
+	err := t.boltBucket.Update(context.Background(), func(data []byte) ([]byte, error) {
+		tokens, err := tokensFromJSON(data)
+
+		if err != nil {
+			return nil, err
+		}
+
+		tokens, err = edit(tokens)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return asJSON(tokens), nil
+	})
+
+	t.mu.Lock()
+	t.verified = nil
+	t.mu.Unlock()
+
+	return err
+}
+
+func tokensFromJSON(data []byte) ([]Token, error) {
+	var tokens []Token
+
+	if len(data) == 0 {
+		return tokens, nil
+	}
+
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("problem parsing tokens, %v", err)
+	}
+
+	return tokens, nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func asJSON(tokens []Token) []byte {
+	b, _ := json.Marshal(tokens)
+	return b
+}
@@ -1,9 +1,13 @@
 package bucket
 
 import (
+	"context"
 	"fmt"
 	"github.com/boltdb/bolt"
-	"log"
+	"github.com/quii/monolith-to-micro/logging"
+	"github.com/quii/monolith-to-micro/metrics"
+	"github.com/quii/monolith-to-micro/tracing"
+	"strings"
 	"time"
 )
 
@@ -12,6 +16,11 @@ var (
 	itemsKey   = []byte("items")
 )
 
+var (
+	txDuration = metrics.Default.NewHistogram("cookme_bolt_transaction_duration_seconds", "time taken by bolt transactions", metrics.DurationBuckets, "bucket", "op")
+	txSize     = metrics.Default.NewHistogram("cookme_bolt_transaction_bytes", "size of the data read or written by bolt transactions", metrics.SizeBuckets, "bucket", "op")
+)
+
 // BoltBucket is a wrapper around bolt just to store one blob of stuff in a bucket
 type BoltBucket struct {
 	filename string
@@ -27,16 +36,30 @@ func NewBoltBucket(filename string, bucket string) (*BoltBucket, error) {
 
 // Get tries to retrieve the data inside the bucket
 func (i *BoltBucket) Get() ([]byte, error) {
+	return i.GetContext(context.Background())
+}
+
+// GetContext is Get, traced as part of the span in ctx if there is one
+func (i *BoltBucket) GetContext(ctx context.Context) (data []byte, err error) {
+	span := i.startSpan(ctx, "bolt.read")
+	defer func() {
+		span.SetAttributes("db.bytes", len(data))
+		span.SetError(err)
+		span.End()
+	}()
+
 	db, err := i.openBoltDB()
 
 	if err != nil {
-		return nil, nil
+		return nil, err
 	}
 
 	defer db.Close()
 
 	var bucketData []byte
 
+	defer i.observe("read", time.Now(), func() int { return len(bucketData) })
+
 	err = db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket(i.bucket)
 		data := b.Get(itemsKey)
@@ -52,6 +75,18 @@ func (i *BoltBucket) Get() ([]byte, error) {
 
 // Put will replace the data in the bucket
 func (i *BoltBucket) Put(data []byte) error {
+	return i.PutContext(context.Background(), data)
+}
+
+// PutContext is Put, traced as part of the span in ctx if there is one
+func (i *BoltBucket) PutContext(ctx context.Context, data []byte) (err error) {
+	span := i.startSpan(ctx, "bolt.write")
+	defer func() {
+		span.SetAttributes("db.bytes", len(data))
+		span.SetError(err)
+		span.End()
+	}()
+
 	db, err := i.openBoltDB()
 
 	if err != nil {
@@ -59,22 +94,114 @@ func (i *BoltBucket) Put(data []byte) error {
 	}
 
 	defer db.Close()
+	defer i.observe("write", time.Now(), func() int { return len(data) })
 
 	err = db.Update(func(tx *bolt.Tx) error {
 		b := tx.Bucket(i.bucket)
 		err := b.Put(itemsKey, data)
 
 		if err != nil {
-			log.Printf("problem adding data to bucket %v", err)
-			return nil
+			logging.Error("problem adding data to bucket", "bucket", string(i.bucket), "err", err)
 		}
 
-		return nil
+		return err
 	})
 
 	return err
 }
 
+// Update replaces the data in the bucket with what change makes of it. The read and the write are one transaction, so
+// nothing else can change the data in between. Nothing is written if change returns an error, or nil
+func (i *BoltBucket) Update(ctx context.Context, change func(data []byte) ([]byte, error)) error {
+	return UpdateTogether(ctx, func(data [][]byte) ([][]byte, error) {
+		newData, err := change(data[0])
+
+		if err != nil || newData == nil {
+			return nil, err
+		}
+
+		return [][]byte{newData}, nil
+	}, i)
+}
+
+// UpdateTogether is Update for buckets in the same db file, change is given the data of each bucket in order and
+// returns what to replace it with. Either every bucket is changed or none are
+func UpdateTogether(ctx context.Context, change func(data [][]byte) ([][]byte, error), buckets ...*BoltBucket) (err error) {
+	first := buckets[0]
+
+	for _, b := range buckets[1:] {
+		if b.filename != first.filename {
+			return fmt.Errorf("can't update %s and %s together as they're in different db files", first.bucket, b.bucket)
+		}
+	}
+
+	written := make([]int, len(buckets))
+
+	span := first.startSpan(ctx, "bolt.update")
+	defer func() {
+		total := 0
+		for _, n := range written {
+			total += n
+		}
+		span.SetAttributes("db.bytes", total)
+		span.SetError(err)
+		span.End()
+	}()
+
+	db, err := first.openBoltDB()
+
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+	defer func(start time.Time) {
+		for n, b := range buckets {
+			b.observe("write", start, func() int { return written[n] })
+		}
+	}(time.Now())
+
+	return db.Update(func(tx *bolt.Tx) error {
+		data := make([][]byte, len(buckets))
+
+		for n, b := range buckets {
+			data[n] = tx.Bucket(b.bucket).Get(itemsKey)
+		}
+
+		newData, err := change(data)
+
+		if err != nil || newData == nil {
+			return err
+		}
+
+		for n, b := range buckets {
+			if err := tx.Bucket(b.bucket).Put(itemsKey, newData[n]); err != nil {
+				return err
+			}
+			written[n] = len(newData[n])
+		}
+
+		return nil
+	})
+}
+
+// observe records a transaction, labelled with the bucket's name without any household so there's a series per kind of data
+func (i *BoltBucket) observe(op string, start time.Time, size func() int) {
+	name := strings.SplitN(string(i.bucket), "/", 2)[0]
+	txDuration.Observe(time.Since(start).Seconds(), name, op)
+	txSize.Observe(float64(size()), name, op)
+}
+
+// startSpan only traces operations done as part of a traced request, so reads made for metrics don't start traces
+func (i *BoltBucket) startSpan(ctx context.Context, name string) *tracing.Span {
+	if !tracing.SpanContextFromContext(ctx).IsValid() {
+		return nil
+	}
+
+	_, span := tracing.Start(ctx, name, "db.system", "bolt", "db.file", i.filename, "db.bucket", string(i.bucket))
+	return span
+}
+
 func (i *BoltBucket) ensureBucket() error {
 	db, err := i.openBoltDB()
 
@@ -0,0 +1,271 @@
+package certs_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/quii/monolith-to-micro/certs"
+	"github.com/quii/monolith-to-micro/certs/certstest"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestInit(t *testing.T) {
+
+	t.Run("creates a server certificate for the hosts signed by the CA", func(t *testing.T) {
+		dir, cleanup := certstest.NewCerts(t)
+		defer cleanup()
+
+		ca := readCertificate(t, filepath.Join(dir, certs.CAFile))
+		server := readCertificate(t, filepath.Join(dir, certs.ServerFile))
+
+		roots := x509.NewCertPool()
+		roots.AddCert(ca)
+
+		for _, host := range []string{"localhost", "127.0.0.1"} {
+			if _, err := server.Verify(x509.VerifyOptions{DNSName: host, Roots: roots}); err != nil {
+				t.Errorf("expected the server certificate to be valid for %s but got %v", host, err)
+			}
+		}
+	})
+
+	t.Run("keeps the keys private", func(t *testing.T) {
+		dir, cleanup := certstest.NewCerts(t)
+		defer cleanup()
+
+		for _, name := range []string{certs.CAKeyFile, certs.ServerKeyFile, certs.ClientKeyFile} {
+			info, err := os.Stat(filepath.Join(dir, name))
+
+			if err != nil {
+				t.Fatalf("problem reading %s %v", name, err)
+			}
+
+			if perm := info.Mode().Perm(); perm != 0600 {
+				t.Errorf("got %s permissions %v, want -rw-------", name, perm)
+			}
+		}
+	})
+
+	t.Run("won't overwrite existing certificates", func(t *testing.T) {
+		dir, cleanup := certstest.NewCerts(t)
+		defer cleanup()
+
+		if err := certs.Init(dir, []string{"localhost"}, time.Hour); err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+}
+
+func TestTLS(t *testing.T) {
+
+	t.Run("mutual TLS accepts clients with a certificate signed by the CA", func(t *testing.T) {
+		dir, cleanup := certstest.NewCerts(t)
+		defer cleanup()
+
+		server := serverConfig(t, dir, true)
+		client := clientConfig(t, dir, true)
+
+		if err := handshake(server, client); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+
+	t.Run("mutual TLS rejects clients without a certificate", func(t *testing.T) {
+		dir, cleanup := certstest.NewCerts(t)
+		defer cleanup()
+
+		server := serverConfig(t, dir, true)
+		client := clientConfig(t, dir, false)
+
+		if err := handshake(server, client); err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+
+	t.Run("mutual TLS rejects client certificates from another CA", func(t *testing.T) {
+		dir, cleanup := certstest.NewCerts(t)
+		defer cleanup()
+
+		otherDir, cleanupOther := certstest.NewCerts(t)
+		defer cleanupOther()
+
+		server := serverConfig(t, dir, true)
+
+		client, err := certs.ClientConfig(
+			filepath.Join(dir, certs.CAFile),
+			filepath.Join(otherDir, certs.ClientFile),
+			filepath.Join(otherDir, certs.ClientKeyFile),
+		)
+
+		if err != nil {
+			t.Fatalf("problem loading client config %v", err)
+		}
+
+		if err := handshake(server, client); err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+
+	t.Run("the server reloads its certificate when it changes", func(t *testing.T) {
+		dir, cleanup := certstest.NewCerts(t)
+		defer cleanup()
+
+		newDir, cleanupNew := certstest.NewCerts(t)
+		defer cleanupNew()
+
+		server := serverConfig(t, dir, false)
+
+		if err := handshake(server, clientConfig(t, dir, false)); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		for _, name := range []string{certs.ServerFile, certs.ServerKeyFile} {
+			replace(t, filepath.Join(newDir, name), filepath.Join(dir, name))
+		}
+
+		if err := handshake(server, clientConfig(t, newDir, false)); err != nil {
+			t.Errorf("expected the new certificate to be served but got %v", err)
+		}
+	})
+
+	t.Run("keeps the old certificate if the new one can't be read", func(t *testing.T) {
+		dir, cleanup := certstest.NewCerts(t)
+		defer cleanup()
+
+		server := serverConfig(t, dir, false)
+
+		filename := filepath.Join(dir, certs.ServerFile)
+		if err := ioutil.WriteFile(filename, []byte("half written"), 0644); err != nil {
+			t.Fatalf("problem writing %v", err)
+		}
+		touch(t, filename)
+
+		if err := handshake(server, clientConfig(t, dir, false)); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+}
+
+func serverConfig(t *testing.T, dir string, mutual bool) *tls.Config {
+	t.Helper()
+
+	clientCA := ""
+	if mutual {
+		clientCA = filepath.Join(dir, certs.CAFile)
+	}
+
+	config, err := certs.ServerConfig(filepath.Join(dir, certs.ServerFile), filepath.Join(dir, certs.ServerKeyFile), clientCA)
+
+	if err != nil {
+		t.Fatalf("problem loading server config %v", err)
+	}
+
+	return config
+}
+
+func clientConfig(t *testing.T, dir string, withCertificate bool) *tls.Config {
+	t.Helper()
+
+	certFile, keyFile := "", ""
+	if withCertificate {
+		certFile, keyFile = filepath.Join(dir, certs.ClientFile), filepath.Join(dir, certs.ClientKeyFile)
+	}
+
+	config, err := certs.ClientConfig(filepath.Join(dir, certs.CAFile), certFile, keyFile)
+
+	if err != nil {
+		t.Fatalf("problem loading client config %v", err)
+	}
+
+	config.ServerName = "localhost"
+	return config
+}
+
+// handshake connects a client to a server in memory, returning the first error either side had
+func handshake(server, client *tls.Config) error {
+	serverConn, clientConn := net.Pipe()
+	errs := make(chan error, 2)
+
+	go func() {
+		conn := tls.Server(serverConn, server)
+		errs <- conn.Handshake()
+		conn.Close()
+	}()
+
+	go func() {
+		conn := tls.Client(clientConn, client)
+		err := conn.Handshake()
+		if err == nil {
+			// with TLS 1.3 the server checks the client certificate after the client has finished, so wait for it to
+			// either close the connection or send an alert
+			if _, err = conn.Read(make([]byte, 1)); err == io.EOF {
+				err = nil
+			}
+		}
+		errs <- err
+		conn.Close()
+	}()
+
+	var first error
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+func replace(t *testing.T, from, to string) {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(from)
+
+	if err != nil {
+		t.Fatalf("problem reading %s %v", from, err)
+	}
+
+	if err := ioutil.WriteFile(to, b, 0600); err != nil {
+		t.Fatalf("problem writing %s %v", to, err)
+	}
+
+	touch(t, to)
+}
+
+// touch moves the modification time on, as files written in quick succession can have the same one
+func touch(t *testing.T, filename string) {
+	t.Helper()
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filename, later, later); err != nil {
+		t.Fatalf("problem touching %s %v", filename, err)
+	}
+}
+
+func readCertificate(t *testing.T, filename string) *x509.Certificate {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(filename)
+
+	if err != nil {
+		t.Fatalf("problem reading %s %v", filename, err)
+	}
+
+	block, _ := pem.Decode(b)
+
+	if block == nil {
+		t.Fatalf("no PEM data in %s", filename)
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+
+	if err != nil {
+		t.Fatalf("problem parsing %s %v", filename, err)
+	}
+
+	return cert
+}
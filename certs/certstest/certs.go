@@ -0,0 +1,30 @@
+// Package certstest makes certificates for tests, in temporary directories
+package certstest
+
+import (
+	"github.com/quii/monolith-to-micro/certs"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+// NewCerts initialises a CA and certificates for localhost, valid for an hour, in a temporary directory. Cleaning up
+// removes the directory
+func NewCerts(t testing.TB) (dir string, cleanup func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "certstest")
+
+	if err != nil {
+		t.Fatalf("problem creating temporary directory %+v", err)
+	}
+
+	if err := certs.Init(dir, []string{"localhost", "127.0.0.1"}, time.Hour); err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("problem creating certificates %v", err)
+	}
+
+	return dir, func() {
+		os.RemoveAll(dir)
+	}
+}
@@ -0,0 +1,166 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// The files Init creates
+const (
+	CAFile        = "ca.pem"
+	CAKeyFile     = "ca-key.pem"
+	ServerFile    = "server.pem"
+	ServerKeyFile = "server-key.pem"
+	ClientFile    = "client.pem"
+	ClientKeyFile = "client-key.pem"
+)
+
+const (
+	certificateTag = "CERTIFICATE"
+	keyTag         = "EC PRIVATE KEY"
+)
+
+// Init creates a CA in dir along with a server certificate for hosts, which can be names or IPs, and a client
+// certificate, both signed by the CA. It won't overwrite existing files
+func Init(dir string, hosts []string, validFor time.Duration) error {
+	for _, name := range []string{CAFile, CAKeyFile, ServerFile, ServerKeyFile, ClientFile, ClientKeyFile} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return fmt.Errorf("%s already exists, remove the old certificates first", filepath.Join(dir, name))
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	// allow for clocks being a little behind
+	notBefore := time.Now().Add(-time.Hour)
+	notAfter := time.Now().Add(validFor)
+
+	caKey, ca, err := issue(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: "cookme CA"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}, nil, nil)
+
+	if err != nil {
+		return err
+	}
+
+	server := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "cookme recipe service"},
+		NotBefore:   notBefore,
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			server.IPAddresses = append(server.IPAddresses, ip)
+		} else {
+			server.DNSNames = append(server.DNSNames, host)
+		}
+	}
+
+	serverKey, serverCert, err := issue(server, ca, caKey)
+
+	if err != nil {
+		return err
+	}
+
+	clientKey, clientCert, err := issue(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: "cookme"},
+		NotBefore:   notBefore,
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, caKey)
+
+	if err != nil {
+		return err
+	}
+
+	for _, pair := range []struct {
+		name string
+		cert *x509.Certificate
+		key  *ecdsa.PrivateKey
+		file string
+	}{
+		{CAFile, ca, caKey, CAKeyFile},
+		{ServerFile, serverCert, serverKey, ServerKeyFile},
+		{ClientFile, clientCert, clientKey, ClientKeyFile},
+	} {
+		if err := writePEM(filepath.Join(dir, pair.name), 0644, certificateTag, pair.cert.Raw); err != nil {
+			return err
+		}
+
+		key, err := x509.MarshalECPrivateKey(pair.key)
+
+		if err != nil {
+			return err
+		}
+
+		if err := writePEM(filepath.Join(dir, pair.file), 0600, keyTag, key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// issue creates a key and a certificate for it from template, signed by parent or self signed if parent is nil
+func issue(template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*ecdsa.PrivateKey, *x509.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template.SerialNumber, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem creating certificate for %s, %v", template.Subject.CommonName, err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	return key, cert, err
+}
+
+func writePEM(filename string, perm os.FileMode, tag string, der []byte) error {
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+
+	if err != nil {
+		return err
+	}
+
+	if err := pem.Encode(f, &pem.Block{Type: tag, Bytes: der}); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
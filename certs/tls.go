@@ -0,0 +1,254 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// ServerConfig serves TLS with the certificate and key in certFile and keyFile. If clientCAFile is set clients must
+// present a certificate signed by it. The files are read again whenever they change so certificates can be rotated
+// without a restart
+func ServerConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	keyPair, err := newKeyPairReloader(certFile, keyFile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return keyPair.certificate()
+		},
+	}
+
+	if clientCAFile == "" {
+		return config, nil
+	}
+
+	clientCAs, err := newPoolReloader(clientCAFile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the client certificate is verified here rather than by setting ClientCAs so the CA can change. gRPC and net/http
+	// copy the config to add their protocols, so a GetConfigForClient returning this config would drop them
+	config.ClientAuth = tls.RequireAnyClientCert
+	config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		pool, err := clientCAs.pool()
+
+		if err != nil {
+			return err
+		}
+
+		return verifyClient(rawCerts, pool)
+	}
+
+	return config, nil
+}
+
+// ClientConfig verifies servers with the CA in caFile, or the system's CAs if it is empty, and if certFile and keyFile
+// are set presents them as a client certificate. The client certificate is read again whenever it changes but the CA is
+// only read once
+func ClientConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		pool, err := loadPool(caFile)
+
+		if err != nil {
+			return nil, err
+		}
+
+		config.RootCAs = pool
+	}
+
+	if certFile == "" && keyFile == "" {
+		return config, nil
+	}
+
+	keyPair, err := newKeyPairReloader(certFile, keyFile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return keyPair.certificate()
+	}
+
+	return config, nil
+}
+
+func verifyClient(rawCerts [][]byte, roots *x509.CertPool) error {
+	intermediates := x509.NewCertPool()
+	var leaf *x509.Certificate
+
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+
+		if err != nil {
+			return err
+		}
+
+		if i == 0 {
+			leaf = cert
+		} else {
+			intermediates.AddCert(cert)
+		}
+	}
+
+	if leaf == nil {
+		return fmt.Errorf("no client certificate")
+	}
+
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+
+	return err
+}
+
+// reloader keeps what was loaded from some files, loading it again when any of their modification times change. If
+// loading again fails, for instance because a file is half written, the last good value is kept
+type reloader struct {
+	files []string
+	load  func() (interface{}, error)
+
+	mu       sync.Mutex
+	modTimes []time.Time
+	value    interface{}
+}
+
+func newReloader(load func() (interface{}, error), files ...string) (*reloader, error) {
+	r := &reloader{files: files, load: load}
+	_, err := r.get()
+	return r, err
+}
+
+func (r *reloader) get() (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var modTimes []time.Time
+
+	for _, file := range r.files {
+		info, err := os.Stat(file)
+
+		if err != nil {
+			return r.last(err)
+		}
+
+		modTimes = append(modTimes, info.ModTime())
+	}
+
+	if r.value != nil && sameTimes(modTimes, r.modTimes) {
+		return r.value, nil
+	}
+
+	value, err := r.load()
+
+	if err != nil {
+		return r.last(err)
+	}
+
+	r.value, r.modTimes = value, modTimes
+	return value, nil
+}
+
+func (r *reloader) last(err error) (interface{}, error) {
+	if r.value != nil {
+		return r.value, nil
+	}
+	return nil, err
+}
+
+func sameTimes(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+type keyPairReloader struct {
+	*reloader
+}
+
+func newKeyPairReloader(certFile, keyFile string) (keyPairReloader, error) {
+	if certFile == "" || keyFile == "" {
+		return keyPairReloader{}, fmt.Errorf("a certificate needs both a certificate file and a key file")
+	}
+
+	r, err := newReloader(func() (interface{}, error) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+
+		if err != nil {
+			return nil, fmt.Errorf("problem loading certificate %s, %v", certFile, err)
+		}
+
+		return &cert, nil
+	}, certFile, keyFile)
+
+	return keyPairReloader{r}, err
+}
+
+func (k keyPairReloader) certificate() (*tls.Certificate, error) {
+	cert, err := k.get()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cert.(*tls.Certificate), nil
+}
+
+type poolReloader struct {
+	*reloader
+}
+
+func newPoolReloader(caFile string) (poolReloader, error) {
+	r, err := newReloader(func() (interface{}, error) {
+		return loadPool(caFile)
+	}, caFile)
+
+	return poolReloader{r}, err
+}
+
+func (p poolReloader) pool() (*x509.CertPool, error) {
+	pool, err := p.get()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pool.(*x509.CertPool), nil
+}
+
+func loadPool(caFile string) (*x509.CertPool, error) {
+	b, err := ioutil.ReadFile(caFile)
+
+	if err != nil {
+		return nil, fmt.Errorf("problem reading CA %v", err)
+	}
+
+	pool := x509.NewCertPool()
+
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, fmt.Errorf("no certificates found in CA %s", caFile)
+	}
+
+	return pool, nil
+}
@@ -1,46 +1,254 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/alert"
+	"github.com/quii/monolith-to-micro/auth"
+	"github.com/quii/monolith-to-micro/certs"
+	"github.com/quii/monolith-to-micro/config"
+	"github.com/quii/monolith-to-micro/history"
 	"github.com/quii/monolith-to-micro/inventory"
+	"github.com/quii/monolith-to-micro/logging"
+	"github.com/quii/monolith-to-micro/output"
 	"github.com/quii/monolith-to-micro/recipe"
+	"github.com/quii/monolith-to-micro/tracing"
+	"github.com/quii/monolith-to-micro/tui"
 	"github.com/spf13/cobra"
-	"log"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 )
 
-const dbFileName = "cookme.db"
-const recipeAddress = "recipes:5000"
-
 func main() {
 
-	recipeBook, close := recipe.NewClient(recipeAddress)
-	defer close()
+	var (
+		recipeClient      *recipe.Client
+		closeRecipeClient func() error
+		houseInventory    *inventory.HouseInventory
+		cookingLog        *history.CookingLog
+		recipeBook        *recipe.CachedClient
+		loader            *config.Loader
+		ctx               = context.Background()
+		commandSpan       *tracing.Span
+	)
+
+	defer func() {
+		commandSpan.End()
+
+		if closeRecipeClient != nil {
+			closeRecipeClient()
+		}
+
+		tracing.Default().Close()
+	}()
 
-	houseInventory, err := inventory.NewHouseInventory(dbFileName)
+	var (
+		quantity     int
+		category     string
+		rotateDays   int
+		historyDays  int
+		favourites   bool
+		outputFormat string
+		format       output.Format
+	)
 
-	if err != nil {
-		log.Fatalf("problem creating db %v", err)
+	write := func(listing output.Listing) {
+		if err := output.Write(os.Stdout, format, listing); err != nil {
+			logging.Fatal("problem writing output", "err", err)
+		}
+	}
+
+	// setup reads the flags common to every command
+	setup := func() config.Config {
+		var err error
+		format, err = output.ParseFormat(outputFormat)
+
+		if err != nil {
+			logging.Fatal("invalid output format", "err", err)
+		}
+
+		conf, err := loader.Load()
+
+		if err != nil {
+			logging.Fatal("problem loading config", "err", err)
+		}
+
+		logger, err := conf.Logger()
+
+		if err != nil {
+			logging.Fatal("problem setting up logging", "err", err)
+		}
+
+		logging.SetDefault(logger)
+
+		tracer, err := conf.Tracer("cookme")
+
+		if err != nil {
+			logging.Fatal("problem setting up tracing", "err", err)
+		}
+
+		tracing.SetDefault(tracer)
+
+		return conf
 	}
 
 	var rootCmd = &cobra.Command{
 		Use:   "cookme",
 		Short: "Cook me tells you what you should cook",
+		Long:  "Cook me tells you what you should cook. Other than as text only the suggestions are output",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			conf := setup()
+			ctx, commandSpan = tracing.Start(context.Background(), cmd.CommandPath())
+
+			tlsConfig, err := conf.RecipeTLS()
+
+			if err != nil {
+				logging.Fatal("problem loading certificates", "err", err)
+			}
+
+			householdID, err := conf.ResolveHousehold()
+
+			if err != nil {
+				logging.Fatal("problem finding the household", "err", err)
+			}
+
+			clientOptions := []recipe.ClientOption{recipe.WithTimeout(conf.Timeout)}
+
+			if tlsConfig != nil {
+				clientOptions = append(clientOptions, recipe.WithTLS(tlsConfig))
+			}
+
+			if conf.Token != "" {
+				clientOptions = append(clientOptions, recipe.WithToken(conf.Token))
+			}
+
+			clientOptions = append(clientOptions, recipe.WithHousehold(householdID))
+
+			recipeClient, closeRecipeClient = recipe.NewClient(conf.RecipeAddress, clientOptions...)
+
+			houseInventory, err = inventory.NewHouseInventoryFor(conf.DBFile, householdID)
+
+			if err != nil {
+				logging.Fatal("problem creating db", "db_file", conf.DBFile, "err", err)
+			}
+
+			cookingLog, err = history.NewCookingLogFor(conf.DBFile, householdID)
+
+			if err != nil {
+				logging.Fatal("problem creating db", "db_file", conf.DBFile, "err", err)
+			}
+
+			recipeBook, err = recipe.NewCachedClientFor(recipeClient, conf.DBFile, householdID)
+
+			if err != nil {
+				logging.Fatal("problem creating db", "db_file", conf.DBFile, "err", err)
+			}
+		},
 		Run: func(cmd *cobra.Command, args []string) {
+			ingredients, err := houseInventory.IngredientsContext(ctx)
+
+			if err != nil {
+				logging.Fatal("problem reading inventory", "err", err)
+			}
+
+			if format == output.Text {
+				fmt.Println("In the house")
+				for _, batches := range ingredients.SortByExpirationDate().GroupByName() {
+					fmt.Printf(" - %s (%d batches)\n", batches[0].Name, len(batches))
+					for _, batch := range batches {
+						fmt.Printf("   - [%s] %s\n", batch.BatchID, batch)
+					}
+				}
+				fmt.Println("Why not cook")
+			}
+
+			allRecipes, err := recipeBook.RecipesContext(ctx)
 
-			recipes := cookme.ListRecipes(
-				houseInventory,
-				recipeBook,
+			if err != nil {
+				logging.Fatal("problem getting recipes", "err", err)
+			}
+
+			recipes := cookme.ListRecipesContext(ctx,
+				cookme.IngredientsRepoFunc(func() cookme.PerishableIngredients { return ingredients }),
+				cookme.RecipeRepoFunc(func() cookme.Recipes { return allRecipes }),
+				cookme.ScoreByRating(0.5),
+				cookme.ScoreByExpiry(ingredients, time.Now(), 0.5),
+				cookme.DownRankRecentlyCooked(cookingLog.History(), daysAgo(rotateDays)),
 			)
 
-			log.Println("Why not cook")
-			for _, recipe := range recipes {
-				log.Printf(" - %s\n", recipe)
+			if favourites {
+				recipes = recipes.Favourites()
+			}
+
+			write(output.Recipes(recipes))
+		},
+	}
+
+	loader = config.Bind(rootCmd.PersistentFlags(), append([]string{config.DBFile, config.RecipeAddress, config.Timeout, config.Token, config.Household}, append(append(config.ClientTLSSettings, config.LogSettings...), config.TraceSettings...)...)...)
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", string(output.Text), "write listings as text, json, yaml, table or csv")
+
+	rootCmd.Flags().IntVar(&rotateDays, "rotate-days", 3, "suggest recipes cooked within this many days last")
+	rootCmd.Flags().BoolVar(&favourites, "favourites", false, "only suggest starred recipes")
+
+	var (
+		expiringWithin string
+		expired        bool
+		name           string
+	)
+
+	var listIngredients = &cobra.Command{
+		Use:   "list-ingredients",
+		Short: "List the batches of ingredients in the house, soonest to expire first",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			now := time.Now()
+			listed := houseInventory.IngredientsContext
+
+			if expired {
+				listed = houseInventory.IngredientsWithExpired
+			}
+
+			ingredients, err := listed(ctx)
+
+			if err != nil {
+				logging.Fatal("problem reading inventory", "err", err)
+			}
+
+			ingredients = ingredients.SortByExpirationDate()
+
+			if expiringWithin != "" {
+				window, err := parseWithin(expiringWithin)
+
+				if err != nil {
+					logging.Fatal("invalid --expiring-within, expect a number of days like 3d or a duration like 12h", "expiring_within", expiringWithin)
+				}
+
+				ingredients = ingredients.ExpiringWithin(now, window)
 			}
+
+			if expired {
+				ingredients = ingredients.Expired(now)
+			}
+
+			if name != "" {
+				ingredients = ingredients.Named(name)
+			}
+
+			write(output.Ingredients(ingredients, now))
 		},
 	}
 
+	listIngredients.Flags().StringVar(&expiringWithin, "expiring-within", "", "only list batches which haven't expired but will within this long, e.g. 3d")
+	listIngredients.Flags().BoolVar(&expired, "expired", false, "only list batches which have expired, without moving them to the waste log first")
+	listIngredients.Flags().StringVar(&name, "name", "", "only list ingredients whose name contains this")
+
 	var addIngredient = &cobra.Command{
 		Use:   "add-ingredient [name] [days-to-expire]",
 		Short: "Add ingredient to inventory",
@@ -49,7 +257,7 @@ func main() {
 			hoursExpire, err := strconv.Atoi(args[1])
 
 			if err != nil {
-				log.Fatalf("invalid days argument, expect a number")
+				logging.Fatal("invalid days argument, expect a number", "days", args[1])
 			}
 
 			daysExpire := hoursExpire * 24
@@ -57,17 +265,191 @@ func main() {
 			newIngredient := cookme.PerishableIngredient{
 				Ingredient:     cookme.Ingredient{Name: args[0]},
 				ExpirationDate: time.Now().Add(time.Duration(daysExpire) * time.Hour),
+				Quantity:       quantity,
+				Category:       category,
+			}
+			if _, err := houseInventory.AddIngredients(newIngredient); err != nil {
+				logging.Fatal("problem adding ingredient", "ingredient", args[0], "err", err)
 			}
-			houseInventory.AddIngredients(newIngredient)
 		},
 	}
 
+	addIngredient.Flags().IntVar(&quantity, "quantity", 1, "how many of the ingredient were bought")
+	addIngredient.Flags().StringVar(&category, "category", "", "what kind of ingredient it is, e.g. dairy")
+
 	var deleteIngredient = &cobra.Command{
-		Use:   "delete-ingredient [name]",
-		Short: "Delete ingredient from inventory",
+		Use:   "delete-ingredient [name] [batch-id|oldest]",
+		Short: "Delete ingredient from inventory, either every batch of it or just one",
+		Args:  cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 1 {
+				if err := houseInventory.DeleteIngredient(args[0]); err != nil {
+					logging.Fatal("problem deleting ingredient", "ingredient", args[0], "err", err)
+				}
+				return
+			}
+
+			var err error
+
+			if args[1] == "oldest" {
+				err = houseInventory.DeleteOldest(args[0])
+			} else {
+				err = houseInventory.DeleteBatch(args[0], args[1])
+			}
+
+			if err != nil {
+				logging.Fatal("problem deleting batch", "ingredient", args[0], "err", err)
+			}
+		},
+	}
+
+	var sweep = &cobra.Command{
+		Use:   "sweep",
+		Short: "Move expired ingredients from the inventory to the waste log",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			write(output.Wasted(houseInventory.Sweep(time.Now())))
+		},
+	}
+
+	var wasteReport = &cobra.Command{
+		Use:   "waste-report",
+		Short: "Show what has been thrown away each month",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			write(output.WasteReport(inventory.NewWasteReport(houseInventory.Waste())))
+		},
+	}
+
+	var (
+		within        time.Duration
+		every         time.Duration
+		alertsFile    string
+		notifyCommand string
+		webhookURL    string
+	)
+
+	var watch = &cobra.Command{
+		Use:   "watch",
+		Short: "Keep checking the inventory and alert about ingredients that are about to expire",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			notifiers := alert.Notifiers{alert.NewWriterNotifier(os.Stdout)}
+
+			if alertsFile != "" {
+				notifiers = append(notifiers, alert.NewFileNotifier(alertsFile))
+			}
+
+			if notifyCommand != "" {
+				notifiers = append(notifiers, alert.NewCommandNotifier(notifyCommand))
+			}
+
+			if webhookURL != "" {
+				notifiers = append(notifiers, alert.NewWebhookNotifier(webhookURL, &http.Client{Timeout: 10 * time.Second}))
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+			go func() {
+				<-signals
+				cancel()
+			}()
+
+			alert.NewWatcher(houseInventory, recipeBook, notifiers, within).Watch(ctx, every)
+		},
+	}
+
+	watch.Flags().DurationVar(&within, "within", 48*time.Hour, "alert about ingredients expiring within this long")
+	watch.Flags().DurationVar(&every, "every", time.Hour, "how often to check the inventory")
+	watch.Flags().StringVar(&alertsFile, "alerts-file", "", "also append alerts to this file")
+	watch.Flags().StringVar(&notifyCommand, "notify-command", "", "also run this command with each alert, e.g. notify-send")
+	watch.Flags().StringVar(&webhookURL, "webhook", "", "also POST alerts as JSON to this URL")
+
+	var cook = &cobra.Command{
+		Use:   "cook [recipe]",
+		Short: "Record that a recipe was cooked",
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			houseInventory.DeleteIngredient(args[0])
+			if err := cookingLog.Cooked(args[0], time.Now()); err != nil {
+				logging.Fatal("problem recording a recipe was cooked", "recipe", args[0], "err", err)
+			}
+		},
+	}
+
+	var cookingHistory = &cobra.Command{
+		Use:   "history",
+		Short: "Show what has been cooked",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cooked := cookingLog.History()
+
+			if historyDays > 0 {
+				cooked = cooked.Since(daysAgo(historyDays))
+			}
+
+			write(output.History(cooked))
+		},
+	}
+
+	cookingHistory.Flags().IntVar(&historyDays, "days", 0, "only show what was cooked in this many days, 0 shows everything")
+
+	var (
+		search   string
+		sortBy   string
+		pageSize int
+	)
+
+	var listRecipes = &cobra.Command{
+		Use:   "list-recipes",
+		Short: "List the recipes in the recipe book",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			order, exists := recipe.GetRecipesRequest_SortOrder_value[strings.ToUpper(sortBy)]
+
+			if !exists {
+				logging.Fatal("invalid sort, expect added, name or rating", "sort", sortBy)
+			}
+
+			pageToken := ""
+			var all cookme.Recipes
+
+			for {
+				recipes, next, err := recipeClient.SearchContext(ctx, search, recipe.GetRecipesRequest_SortOrder(order), pageSize, pageToken)
+
+				if err != nil {
+					logging.Fatal("problem listing recipes", "err", err)
+				}
+
+				all = append(all, recipes...)
+
+				if next == "" {
+					write(output.Recipes(all))
+					return
+				}
+
+				pageToken = next
+			}
+		},
+	}
+
+	listRecipes.Flags().StringVar(&search, "search", "", "only list recipes whose name or ingredients contain this")
+	listRecipes.Flags().StringVar(&sortBy, "sort", "added", "order recipes by added, name or rating")
+	listRecipes.Flags().IntVar(&pageSize, "page-size", 20, "how many recipes to fetch from the recipe service at a time")
+
+	var recipesWith = &cobra.Command{
+		Use:   "recipes-with [ingredients...]",
+		Short: "List recipes that use the ingredients, those using the most of them first",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			matches, err := recipeClient.RecipesUsingContext(ctx, args)
+
+			if err != nil {
+				logging.Fatal("problem finding recipes", "err", err)
+			}
+
+			write(output.Matches(matches, len(args)))
 		},
 	}
 
@@ -76,7 +458,9 @@ func main() {
 		Short: "Add recipe",
 		Args:  cobra.MinimumNArgs(2),
 		Run: func(cmd *cobra.Command, args []string) {
-			recipeBook.Add(args[0], args[1:])
+			if err := recipeBook.Add(args[0], args[1:]); err != nil {
+				logging.Fatal("problem adding recipe", "recipe", args[0], "err", err)
+			}
 		},
 	}
 
@@ -85,16 +469,211 @@ func main() {
 		Short: "Delete recipe",
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			recipeBook.Delete(args[0])
+			if err := recipeBook.Delete(args[0]); err != nil {
+				logging.Fatal("problem deleting recipe", "recipe", args[0], "err", err)
+			}
+		},
+	}
+
+	var rateRecipe = &cobra.Command{
+		Use:   "rate-recipe [name] [1-5]",
+		Short: "Rate a recipe out of 5",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			rating, err := strconv.Atoi(args[1])
+
+			if err != nil {
+				logging.Fatal("invalid rating argument, expect a number", "rating", args[1])
+			}
+
+			if err := recipeBook.Rate(args[0], rating); err != nil {
+				logging.Fatal("problem rating recipe", "recipe", args[0], "err", err)
+			}
+		},
+	}
+
+	var favouriteRecipe = &cobra.Command{
+		Use:   "favourite-recipe [name]",
+		Short: "Star a recipe as a favourite",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := recipeBook.SetFavourite(args[0], true); err != nil {
+				logging.Fatal("problem starring recipe", "recipe", args[0], "err", err)
+			}
+		},
+	}
+
+	var unfavouriteRecipe = &cobra.Command{
+		Use:   "unfavourite-recipe [name]",
+		Short: "Remove the star from a favourite recipe",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := recipeBook.SetFavourite(args[0], false); err != nil {
+				logging.Fatal("problem unstarring recipe", "recipe", args[0], "err", err)
+			}
 		},
 	}
 
+	var refresh time.Duration
+
+	var terminalUI = &cobra.Command{
+		Use:   "tui",
+		Short: "Manage the inventory and recipes full screen, seeing what to cook as they change",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			model := tui.NewModel(houseInventory, recipeBook, cookme.DownRankRecentlyCooked(cookingLog.History(), daysAgo(rotateDays)))
+
+			// logging would draw over the screen
+			logger := logging.Default()
+			logging.SetDefault(logging.New(ioutil.Discard, logging.LevelError, logging.Text))
+			ctx, cancel := context.WithCancel(context.Background())
+			err := tui.Run(ctx, os.Stdin, os.Stdout, model, recipeClient.Changes(ctx), refresh)
+			cancel()
+			logging.SetDefault(logger)
+
+			if err != nil {
+				logging.Fatal("problem running the terminal ui", "err", err)
+			}
+		},
+	}
+
+	terminalUI.Flags().DurationVar(&refresh, "refresh", 5*time.Second, "how often to reload the inventory for changes made elsewhere, recipes are reloaded as they change")
+	terminalUI.Flags().IntVar(&rotateDays, "rotate-days", 3, "suggest recipes cooked within this many days last")
+
+	var (
+		certsDir  string
+		hosts     []string
+		validDays int
+	)
+
+	var certificates = &cobra.Command{
+		Use:   "certs",
+		Short: "Manage the certificates used to talk to the recipe service over TLS",
+		// doesn't need the database or the recipe service
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+	}
+
+	var initCerts = &cobra.Command{
+		Use:   "init",
+		Short: "Create a CA and a server and client certificate signed by it, e.g. for docker-compose",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := certs.Init(certsDir, hosts, time.Duration(validDays)*24*time.Hour); err != nil {
+				logging.Fatal("problem creating certificates", "dir", certsDir, "err", err)
+			}
+
+			fmt.Printf("Created a CA, a server certificate for %s and a client certificate in %s\n", strings.Join(hosts, ", "), certsDir)
+		},
+	}
+
+	initCerts.Flags().StringVar(&certsDir, "dir", "tls", "directory to write the certificates and keys to")
+	initCerts.Flags().StringSliceVar(&hosts, "hosts", []string{"localhost", "127.0.0.1", "recipes"}, "names and IPs the server certificate is valid for")
+	initCerts.Flags().IntVar(&validDays, "valid-days", 365, "how many days the certificates are valid for")
+
+	certificates.AddCommand(initCerts)
+
+	var (
+		tokens    *auth.Tokens
+		household string
+		role      string
+	)
+
+	var token = &cobra.Command{
+		Use:   "token",
+		Short: "Manage the tokens allowed to call the recipe service, --db-file must be the recipe service's database",
+		// doesn't need the recipe service
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			var err error
+			tokens, err = auth.NewTokens(setup().DBFile)
+
+			if err != nil {
+				logging.Fatal("problem opening tokens", "err", err)
+			}
+		},
+	}
+
+	var createToken = &cobra.Command{
+		Use:   "create",
+		Short: "Create a token for a household, it is only shown once",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			tokenRole, err := auth.ParseRole(role)
+
+			if err != nil {
+				logging.Fatal("invalid role", "err", err)
+			}
+
+			secret, created, err := tokens.Create(household, tokenRole, time.Now())
+
+			if err != nil {
+				logging.Fatal("problem creating token", "err", err)
+			}
+
+			fmt.Printf("Created %s token %s for %s, keep it safe as it won't be shown again\n%s\n", created.Role, created.ID, created.Household, secret)
+		},
+	}
+
+	createToken.Flags().StringVar(&household, "household", "", "household the token can use")
+	createToken.Flags().StringVar(&role, "role", string(auth.Reader), "reader to only look at recipes or editor to change them too")
+	createToken.MarkFlagRequired("household")
+
+	var listTokens = &cobra.Command{
+		Use:   "list",
+		Short: "List the tokens, without their secrets",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			write(output.Tokens(tokens.List()))
+		},
+	}
+
+	var revokeToken = &cobra.Command{
+		Use:   "revoke [id]",
+		Short: "Stop a token from being used",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := tokens.Revoke(args[0]); err != nil {
+				logging.Fatal("problem revoking token", "id", args[0], "err", err)
+			}
+		},
+	}
+
+	token.AddCommand(createToken)
+	token.AddCommand(listTokens)
+	token.AddCommand(revokeToken)
+
+	rootCmd.AddCommand(listIngredients)
 	rootCmd.AddCommand(addIngredient)
 	rootCmd.AddCommand(deleteIngredient)
+	rootCmd.AddCommand(sweep)
+	rootCmd.AddCommand(wasteReport)
+	rootCmd.AddCommand(watch)
+	rootCmd.AddCommand(cook)
+	rootCmd.AddCommand(cookingHistory)
+	rootCmd.AddCommand(listRecipes)
+	rootCmd.AddCommand(recipesWith)
 	rootCmd.AddCommand(addRecipe)
 	rootCmd.AddCommand(deleteRecipe)
+	rootCmd.AddCommand(rateRecipe)
+	rootCmd.AddCommand(favouriteRecipe)
+	rootCmd.AddCommand(unfavouriteRecipe)
+	rootCmd.AddCommand(terminalUI)
+	rootCmd.AddCommand(certificates)
+	rootCmd.AddCommand(token)
 
 	if err := rootCmd.Execute(); err != nil {
-		log.Fatal(err)
+		logging.Fatal("problem running command", "err", err)
+	}
+}
+
+func daysAgo(days int) time.Time {
+	return time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+}
+
+// parseWithin parses a duration which can also be a whole number of days, e.g. 3d
+func parseWithin(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		return time.Duration(days) * 24 * time.Hour, err
 	}
+	return time.ParseDuration(s)
 }
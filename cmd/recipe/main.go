@@ -1,29 +1,223 @@
 package main
 
 import (
+	"context"
+	"github.com/quii/monolith-to-micro/api"
+	"github.com/quii/monolith-to-micro/auth"
+	"github.com/quii/monolith-to-micro/config"
+	"github.com/quii/monolith-to-micro/history"
+	"github.com/quii/monolith-to-micro/household"
+	"github.com/quii/monolith-to-micro/interceptor"
+	"github.com/quii/monolith-to-micro/inventory"
+	"github.com/quii/monolith-to-micro/logging"
+	"github.com/quii/monolith-to-micro/metrics"
 	"github.com/quii/monolith-to-micro/recipe"
+	"github.com/quii/monolith-to-micro/tracing"
+	"github.com/spf13/pflag"
 	"google.golang.org/grpc"
-	"log"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
-const dbFileName = "cookme.db"
-const port = ":5000"
+const serviceName = "RecipeService"
+
+// shutdownTimeout is how long in-flight requests get to finish before the servers are stopped anyway
+const shutdownTimeout = 5 * time.Second
 
 func main() {
-	recipeBook, err := recipe.NewBook(dbFileName)
+	loader := config.Bind(pflag.CommandLine, append([]string{config.DBFile, config.RecipeListen, config.APIListen, config.RequireToken, config.AdminListen}, append(append(config.ServerTLSSettings, config.LogSettings...), config.TraceSettings...)...)...)
+	pflag.Parse()
+
+	conf, err := loader.Load()
+
+	if err != nil {
+		logging.Fatal("problem loading config", "err", err)
+	}
 
-	listener, err := net.Listen("tcp", port)
+	logger, err := conf.Logger()
 
 	if err != nil {
-		log.Fatalf("problem listening to port %s, %v", port, err)
+		logging.Fatal("problem setting up logging", "err", err)
+	}
+
+	logging.SetDefault(logger)
+
+	tracer, err := conf.Tracer("recipes")
+
+	if err != nil {
+		logger.Fatal("problem setting up tracing", "err", err)
+	}
+
+	tracing.SetDefault(tracer)
+	defer tracer.Close()
+
+	tlsConfig, err := conf.ServerTLS()
+
+	if err != nil {
+		logger.Fatal("problem loading certificates", "err", err)
+	}
+
+	var serverOptions []grpc.ServerOption
+
+	if tlsConfig != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
 	}
 
-	server := grpc.NewServer()
+	rpcMetrics := metrics.NewRPCMetrics(metrics.Default)
+
+	unaryInterceptors := []grpc.UnaryServerInterceptor{tracing.UnaryServerInterceptor(tracer), logging.UnaryServerInterceptor(logger), rpcMetrics.UnaryServerInterceptor()}
+	streamInterceptors := []grpc.StreamServerInterceptor{tracing.StreamServerInterceptor(tracer), logging.StreamServerInterceptor(logger), rpcMetrics.StreamServerInterceptor()}
+
+	var tokens *auth.Tokens
+
+	if conf.RequireToken {
+		tokens, err = auth.NewTokens(conf.DBFile)
+
+		if err != nil {
+			logger.Fatal("problem opening tokens", "db_file", conf.DBFile, "err", err)
+		}
+
+		unaryInterceptors = append(unaryInterceptors, auth.UnaryServerInterceptor(tokens, recipe.Policy))
+		streamInterceptors = append(streamInterceptors, auth.StreamServerInterceptor(tokens, recipe.Policy))
+	} else {
+		logger.Warn("anyone who can reach the recipe service can change the recipes and inventory, set require_token to stop them", "recipe_listen", conf.RecipeListen, "api_listen", conf.APIListen)
+	}
+
+	serverOptions = append(serverOptions,
+		grpc.UnaryInterceptor(interceptor.ChainUnaryServer(unaryInterceptors...)),
+		grpc.StreamInterceptor(interceptor.ChainStreamServer(streamInterceptors...)),
+	)
+
+	server := grpc.NewServer(serverOptions...)
+
+	healthServer := health.NewServer()
+	setServingStatus(healthServer, healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+
+	library := recipe.NewLibrary(conf.DBFile)
+
+	if _, err := library.Book(household.Default); err != nil {
+		logger.Fatal("problem opening recipe book", "db_file", conf.DBFile, "err", err)
+	}
+
+	recipe.RegisterRecipeServiceServer(server, library)
+	recipe.RegisterMetrics(metrics.Default, library)
+	inventory.RegisterMetrics(metrics.Default, conf.DBFile, library.Households)
+
+	var handler http.Handler = api.NewHouseholds(func(householdID string) (*api.Server, error) {
+		recipeBook, err := library.Book(householdID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		houseInventory, err := inventory.NewHouseInventoryFor(conf.DBFile, householdID)
 
-	recipe.RegisterRecipeServiceServer(server, recipeBook)
+		if err != nil {
+			return nil, err
+		}
+
+		cookingLog, err := history.NewCookingLogFor(conf.DBFile, householdID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return api.NewServer(recipeBook, houseInventory, cookingLog), nil
+	})
+
+	if tokens != nil {
+		handler = api.RequireToken(tokens, handler)
+	}
+
+	httpServer := &http.Server{Addr: conf.APIListen, Handler: tracing.Handler(tracer, logging.Handler(logger, metrics.NewHTTPMetrics(metrics.Default).Handler(handler))), TLSConfig: tlsConfig}
+
+	go func() {
+		var err error
+
+		if tlsConfig != nil {
+			err = httpServer.ListenAndServeTLS("", "")
+		} else {
+			err = httpServer.ListenAndServe()
+		}
+
+		if err != http.ErrServerClosed {
+			logger.Fatal("failed to serve http", "err", err)
+		}
+	}()
+
+	if conf.AdminListen != "" {
+		go serveMetrics(conf.AdminListen)
+	}
+
+	listener, err := net.Listen("tcp", conf.RecipeListen)
+
+	if err != nil {
+		logger.Fatal("problem listening", "address", conf.RecipeListen, "err", err)
+	}
+
+	go stopOnSignal(server, httpServer, healthServer, library)
+
+	setServingStatus(healthServer, healthpb.HealthCheckResponse_SERVING)
+
+	logger.Info("serving the recipe service", "recipe_listen", conf.RecipeListen, "api_listen", conf.APIListen, "tls", tlsConfig != nil)
 
 	if err := server.Serve(listener); err != nil {
-		log.Fatalf("failed to serve %v", err)
+		logger.Fatal("failed to serve", "err", err)
+	}
+}
+
+// stopOnSignal waits for SIGINT or SIGTERM then lets in-flight requests finish before stopping the servers.
+// The recipe book only holds the database open for the length of each transaction so once the requests have
+// finished the database is closed and unlocked for the next container. Watchers are disconnected as they would
+// otherwise never finish, and anything still going after shutdownTimeout is stopped
+func stopOnSignal(server *grpc.Server, httpServer *http.Server, healthServer *health.Server, library *recipe.Library) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	sig := <-signals
+	logging.Info("shutting down", "signal", sig)
+
+	setServingStatus(healthServer, healthpb.HealthCheckResponse_NOT_SERVING)
+	library.StopWatching()
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	httpServer.Shutdown(ctx)
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		logging.Warn("requests didn't finish in time, stopping anyway", "timeout", shutdownTimeout)
+		server.Stop()
+	}
+}
+
+func serveMetrics(address string) {
+	logging.Info("serving metrics", "admin_listen", address)
+
+	if err := metrics.ListenAndServe(address, metrics.Default); err != nil {
+		logging.Fatal("failed to serve metrics", "err", err)
 	}
 }
+
+func setServingStatus(healthServer *health.Server, status healthpb.HealthCheckResponse_ServingStatus) {
+	healthServer.SetServingStatus("", status)
+	healthServer.SetServingStatus(serviceName, status)
+}
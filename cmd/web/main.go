@@ -0,0 +1,123 @@
+package main
+
+import (
+	"github.com/quii/monolith-to-micro/auth"
+	"github.com/quii/monolith-to-micro/config"
+	"github.com/quii/monolith-to-micro/history"
+	"github.com/quii/monolith-to-micro/inventory"
+	"github.com/quii/monolith-to-micro/logging"
+	"github.com/quii/monolith-to-micro/metrics"
+	"github.com/quii/monolith-to-micro/recipe"
+	"github.com/quii/monolith-to-micro/tracing"
+	"github.com/quii/monolith-to-micro/web"
+	"github.com/spf13/pflag"
+	"net/http"
+)
+
+func main() {
+	loader := config.Bind(pflag.CommandLine, append([]string{config.DBFile, config.RecipeAddress, config.WebListen, config.Timeout, config.Token, config.RequireToken, config.Household, config.AdminListen}, append(append(config.ClientTLSSettings, config.LogSettings...), config.TraceSettings...)...)...)
+	pflag.Parse()
+
+	conf, err := loader.Load()
+
+	if err != nil {
+		logging.Fatal("problem loading config", "err", err)
+	}
+
+	logger, err := conf.Logger()
+
+	if err != nil {
+		logging.Fatal("problem setting up logging", "err", err)
+	}
+
+	logging.SetDefault(logger)
+
+	tracer, err := conf.Tracer("web")
+
+	if err != nil {
+		logger.Fatal("problem setting up tracing", "err", err)
+	}
+
+	tracing.SetDefault(tracer)
+	defer tracer.Close()
+
+	tlsConfig, err := conf.RecipeTLS()
+
+	if err != nil {
+		logger.Fatal("problem loading certificates", "err", err)
+	}
+
+	householdID, err := conf.ResolveHousehold()
+
+	if err != nil {
+		logger.Fatal("problem finding the household", "err", err)
+	}
+
+	clientOptions := []recipe.ClientOption{recipe.WithTimeout(conf.Timeout)}
+
+	if tlsConfig != nil {
+		clientOptions = append(clientOptions, recipe.WithTLS(tlsConfig))
+	}
+
+	if conf.Token != "" {
+		clientOptions = append(clientOptions, recipe.WithToken(conf.Token))
+	}
+
+	clientOptions = append(clientOptions, recipe.WithHousehold(householdID))
+
+	recipeClient, close := recipe.NewClient(conf.RecipeAddress, clientOptions...)
+	defer close()
+
+	houseInventory, err := inventory.NewHouseInventoryFor(conf.DBFile, householdID)
+
+	if err != nil {
+		logger.Fatal("problem creating db", "db_file", conf.DBFile, "err", err)
+	}
+
+	recipeBook, err := recipe.NewCachedClientFor(recipeClient, conf.DBFile, householdID)
+
+	if err != nil {
+		logger.Fatal("problem creating db", "db_file", conf.DBFile, "err", err)
+	}
+
+	cookingLog, err := history.NewCookingLogFor(conf.DBFile, householdID)
+
+	if err != nil {
+		logger.Fatal("problem creating db", "db_file", conf.DBFile, "err", err)
+	}
+
+	var options []web.Option
+
+	// with a token of its own the UI could make changes for anyone who can reach it, so they must log in first
+	if conf.RequireToken || conf.Token != "" {
+		tokens, err := auth.NewTokens(conf.DBFile)
+
+		if err != nil {
+			logger.Fatal("problem opening tokens", "db_file", conf.DBFile, "err", err)
+		}
+
+		options = append(options, web.RequireLogin(tokens, householdID))
+	}
+
+	inventory.RegisterMetrics(metrics.Default, conf.DBFile, func() []string {
+		return []string{householdID}
+	})
+
+	if conf.AdminListen != "" {
+		go func() {
+			logger.Info("serving metrics", "admin_listen", conf.AdminListen)
+
+			if err := metrics.ListenAndServe(conf.AdminListen, metrics.Default); err != nil {
+				logger.Fatal("failed to serve metrics", "err", err)
+			}
+		}()
+	}
+
+	handler := metrics.NewHTTPMetrics(metrics.Default).Handler(web.NewServer(houseInventory, recipeBook, cookingLog, options...))
+
+	logger.Info("serving the web ui", "web_listen", conf.WebListen)
+
+	if err := http.ListenAndServe(conf.WebListen, tracing.Handler(tracer, logging.Handler(logger, handler))); err != nil {
+		logger.Fatal("failed to serve", "err", err)
+	}
+}
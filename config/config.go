@@ -0,0 +1,378 @@
+package config
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"github.com/quii/monolith-to-micro/auth"
+	"github.com/quii/monolith-to-micro/certs"
+	"github.com/quii/monolith-to-micro/household"
+	"github.com/quii/monolith-to-micro/logging"
+	"github.com/quii/monolith-to-micro/tracing"
+	"github.com/spf13/pflag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config is where the cookme binaries keep their data, listen and find each other
+type Config struct {
+	DBFile        string
+	RecipeAddress string
+	RecipeListen  string
+	APIListen     string
+	WebListen     string
+	Timeout       time.Duration
+	RecipeCA      string
+	RecipeCert    string
+	RecipeKey     string
+	TLSCert       string
+	TLSKey        string
+	TLSClientCA   string
+	Token         string
+	RequireToken  bool
+	Household     string
+	LogLevel      string
+	LogFormat     string
+	AdminListen   string
+	TraceExporter string
+	TraceFile     string
+	TraceEndpoint string
+}
+
+// Default is the config used when nothing is set
+func Default() Config {
+	return Config{
+		DBFile:        "cookme.db",
+		RecipeAddress: "localhost:5000",
+		RecipeListen:  ":5000",
+		APIListen:     ":8080",
+		WebListen:     ":8000",
+		Timeout:       5 * time.Second,
+		LogLevel:      "info",
+		LogFormat:     "text",
+		TraceFile:     "cookme-traces.json",
+		TraceEndpoint: tracing.DefaultOTLPEndpoint,
+	}
+}
+
+// The keys of each setting. In the config file they are used as is, as environment variables they are upper cased and
+// prefixed with COOKME_, e.g. COOKME_DB_FILE, and as flags underscores become dashes, e.g. --db-file
+const (
+	DBFile        = "db_file"
+	RecipeAddress = "recipe_address"
+	RecipeListen  = "recipe_listen"
+	APIListen     = "api_listen"
+	WebListen     = "web_listen"
+	Timeout       = "timeout"
+	RecipeCA      = "recipe_ca"
+	RecipeCert    = "recipe_cert"
+	RecipeKey     = "recipe_key"
+	TLSCert       = "tls_cert"
+	TLSKey        = "tls_key"
+	TLSClientCA   = "tls_client_ca"
+	Token         = "token"
+	RequireToken  = "require_token"
+	Household     = "household"
+	LogLevel      = "log_level"
+	LogFormat     = "log_format"
+	AdminListen   = "admin_listen"
+	TraceExporter = "trace_exporter"
+	TraceFile     = "trace_file"
+	TraceEndpoint = "trace_endpoint"
+)
+
+const envPrefix = "COOKME_"
+
+type setting struct {
+	key   string
+	usage string
+	field func(c *Config) interface{}
+}
+
+var settings = []setting{
+	{DBFile, "the bolt database holding the inventory, recipes and history", func(c *Config) interface{} { return &c.DBFile }},
+	{RecipeAddress, "host:port of the recipe service", func(c *Config) interface{} { return &c.RecipeAddress }},
+	{RecipeListen, "address the recipe service listens on for gRPC", func(c *Config) interface{} { return &c.RecipeListen }},
+	{APIListen, "address the recipe service listens on for HTTP", func(c *Config) interface{} { return &c.APIListen }},
+	{WebListen, "address the web UI listens on", func(c *Config) interface{} { return &c.WebListen }},
+	{Timeout, "how long to wait for each call to the recipe service", func(c *Config) interface{} { return &c.Timeout }},
+	{RecipeCA, "CA to verify the recipe service with, setting it connects over TLS", func(c *Config) interface{} { return &c.RecipeCA }},
+	{RecipeCert, "client certificate presented to the recipe service", func(c *Config) interface{} { return &c.RecipeCert }},
+	{RecipeKey, "key of the client certificate presented to the recipe service", func(c *Config) interface{} { return &c.RecipeKey }},
+	{TLSCert, "certificate to serve TLS with, setting it turns TLS on", func(c *Config) interface{} { return &c.TLSCert }},
+	{TLSKey, "key of the certificate to serve TLS with", func(c *Config) interface{} { return &c.TLSKey }},
+	{TLSClientCA, "CA which must have signed client certificates, setting it turns on mutual TLS", func(c *Config) interface{} { return &c.TLSClientCA }},
+	{Token, "token to call the recipe service with, see cookme token create", func(c *Config) interface{} { return &c.Token }},
+	{RequireToken, "only allow calls with a token for the household and role needed", func(c *Config) interface{} { return &c.RequireToken }},
+	{Household, "household whose recipes and inventory to use, defaults to the token's household", func(c *Config) interface{} { return &c.Household }},
+	{LogLevel, "least important messages logged, debug, info, warn or error", func(c *Config) interface{} { return &c.LogLevel }},
+	{LogFormat, "how messages are logged, text or json", func(c *Config) interface{} { return &c.LogFormat }},
+	{AdminListen, "address serving /metrics for Prometheus, it isn't served unless set", func(c *Config) interface{} { return &c.AdminListen }},
+	{TraceExporter, "where to send traces, stderr, file or otlp, nothing is traced unless set", func(c *Config) interface{} { return &c.TraceExporter }},
+	{TraceFile, "file the file trace exporter appends spans to", func(c *Config) interface{} { return &c.TraceFile }},
+	{TraceEndpoint, "URL of the OpenTelemetry collector's OTLP/HTTP traces endpoint", func(c *Config) interface{} { return &c.TraceEndpoint }},
+}
+
+// Settings used by each kind of binary
+var (
+	ServerTLSSettings = []string{TLSCert, TLSKey, TLSClientCA}
+	ClientTLSSettings = []string{RecipeCA, RecipeCert, RecipeKey}
+	LogSettings       = []string{LogLevel, LogFormat}
+	TraceSettings     = []string{TraceExporter, TraceFile, TraceEndpoint}
+)
+
+func (s setting) env() string {
+	return envPrefix + strings.ToUpper(s.key)
+}
+
+func (s setting) flag() string {
+	return strings.Replace(s.key, "_", "-", -1)
+}
+
+func (s setting) set(c *Config, value string) error {
+	switch field := s.field(c).(type) {
+	case *string:
+		*field = value
+	case *time.Duration:
+		d, err := time.ParseDuration(value)
+
+		if err != nil {
+			return fmt.Errorf("invalid %s %q, expect a duration like 5s", s.key, value)
+		}
+
+		*field = d
+	case *bool:
+		b, err := strconv.ParseBool(value)
+
+		if err != nil {
+			return fmt.Errorf("invalid %s %q, expect true or false", s.key, value)
+		}
+
+		*field = b
+	}
+	return nil
+}
+
+func (s setting) copy(to, from *Config) {
+	switch field := s.field(to).(type) {
+	case *string:
+		*field = *s.field(from).(*string)
+	case *time.Duration:
+		*field = *s.field(from).(*time.Duration)
+	case *bool:
+		*field = *s.field(from).(*bool)
+	}
+}
+
+func lookup(key string) (setting, bool) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+// Loader builds a Config from, in increasing precedence, the defaults, a config file, COOKME_* environment variables
+// and flags
+type Loader struct {
+	flags   *pflag.FlagSet
+	used    []setting
+	file    string
+	flagged Config
+}
+
+// Bind adds --config and a flag for each of keys, the settings the binary uses, to flags
+func Bind(flags *pflag.FlagSet, keys ...string) *Loader {
+	l := &Loader{flags: flags, flagged: Default()}
+
+	flags.StringVar(&l.file, "config", "", fmt.Sprintf("config file, defaults to %s if it exists (env %sCONFIG)", DefaultPath(), envPrefix))
+
+	for _, key := range keys {
+		s, ok := lookup(key)
+
+		if !ok {
+			panic(fmt.Sprintf("no config setting called %q", key))
+		}
+
+		usage := fmt.Sprintf("%s (env %s)", s.usage, s.env())
+
+		switch field := s.field(&l.flagged).(type) {
+		case *string:
+			flags.StringVar(field, s.flag(), *field, usage)
+		case *time.Duration:
+			flags.DurationVar(field, s.flag(), *field, usage)
+		case *bool:
+			flags.BoolVar(field, s.flag(), *field, usage)
+		}
+
+		l.used = append(l.used, s)
+	}
+
+	return l
+}
+
+// Load layers the config, it must be called after the flags are parsed
+func (l *Loader) Load() (Config, error) {
+	c := Default()
+
+	if err := l.loadFile(&c); err != nil {
+		return c, err
+	}
+
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env()); ok {
+			if err := s.set(&c, value); err != nil {
+				return c, fmt.Errorf("problem reading %s, %v", s.env(), err)
+			}
+		}
+	}
+
+	for _, s := range l.used {
+		if l.flags.Changed(s.flag()) {
+			s.copy(&c, &l.flagged)
+		}
+	}
+
+	return c, nil
+}
+
+// loadFile reads the config file, which is a JSON object of keys to string values. The default file is optional but
+// one chosen by --config or COOKME_CONFIG must exist
+func (l *Loader) loadFile(c *Config) error {
+	path, chosen := l.file, l.file != ""
+
+	if !chosen {
+		path, chosen = os.LookupEnv(envPrefix + "CONFIG")
+	}
+
+	if !chosen {
+		path = DefaultPath()
+	}
+
+	b, err := ioutil.ReadFile(path)
+
+	if os.IsNotExist(err) && !chosen {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("problem reading config file %v", err)
+	}
+
+	var values map[string]string
+
+	if err := json.Unmarshal(b, &values); err != nil {
+		return fmt.Errorf("problem parsing config file %s, expect a JSON object of strings, %v", path, err)
+	}
+
+	for key, value := range values {
+		s, ok := lookup(key)
+
+		if !ok {
+			return fmt.Errorf("unknown setting %q in config file %s", key, path)
+		}
+
+		if err := s.set(c, value); err != nil {
+			return fmt.Errorf("problem reading config file %s, %v", path, err)
+		}
+	}
+
+	return nil
+}
+
+// ResolveHousehold is the household chosen or, if none was, the household of the Token as found in the DBFile's tokens.
+// Without either it's household.Default. Every store and the recipe client should use it, so they agree
+func (c Config) ResolveHousehold() (string, error) {
+	if c.Household != "" {
+		return c.Household, nil
+	}
+
+	if c.Token == "" {
+		return household.Default, nil
+	}
+
+	tokens, err := auth.NewTokens(c.DBFile)
+
+	if err != nil {
+		return "", err
+	}
+
+	token, ok := tokens.Verify(c.Token)
+
+	if !ok {
+		return "", fmt.Errorf("the token isn't in %s so its household isn't known, set %s", c.DBFile, Household)
+	}
+
+	return token.Household, nil
+}
+
+// ServerTLS is how to serve TLS, or nil for plain text
+func (c Config) ServerTLS() (*tls.Config, error) {
+	if c.TLSCert == "" && c.TLSKey == "" && c.TLSClientCA == "" {
+		return nil, nil
+	}
+	return certs.ServerConfig(c.TLSCert, c.TLSKey, c.TLSClientCA)
+}
+
+// RecipeTLS is how to connect to the recipe service over TLS, or nil for plain text
+func (c Config) RecipeTLS() (*tls.Config, error) {
+	if c.RecipeCA == "" && c.RecipeCert == "" && c.RecipeKey == "" {
+		return nil, nil
+	}
+	return certs.ClientConfig(c.RecipeCA, c.RecipeCert, c.RecipeKey)
+}
+
+// Logger is how the binary logs, to stderr at the LogLevel in the LogFormat
+func (c Config) Logger() (*logging.Logger, error) {
+	level, err := logging.ParseLevel(c.LogLevel)
+
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := logging.ParseFormat(c.LogFormat)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return logging.New(os.Stderr, level, format), nil
+}
+
+// Tracer traces the work of service, sending spans to the TraceExporter, or it traces nothing if that's unset
+func (c Config) Tracer(service string) (*tracing.Tracer, error) {
+	switch c.TraceExporter {
+	case "":
+		return tracing.NewTracer(service, nil), nil
+	case "stderr":
+		return tracing.NewTracer(service, tracing.NewWriterExporter(os.Stderr)), nil
+	case "file":
+		exporter, err := tracing.NewFileExporter(c.TraceFile)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return tracing.NewTracer(service, exporter), nil
+	case "otlp":
+		return tracing.NewTracer(service, tracing.NewOTLPExporter(c.TraceEndpoint, 5*time.Second)), nil
+	default:
+		return nil, fmt.Errorf("unknown %s %q, expect stderr, file or otlp", TraceExporter, c.TraceExporter)
+	}
+}
+
+// DefaultPath is cookme/config.json in the XDG config directory, $XDG_CONFIG_HOME or ~/.config
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+
+	if dir == "" {
+		dir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+
+	return filepath.Join(dir, "cookme", "config.json")
+}
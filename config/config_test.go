@@ -0,0 +1,312 @@
+package config_test
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/auth"
+	"github.com/quii/monolith-to-micro/config"
+	"github.com/quii/monolith-to-micro/household"
+	"github.com/spf13/pflag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoader(t *testing.T) {
+
+	t.Run("uses the defaults when nothing is set", func(t *testing.T) {
+		cleanup := NewTestEnvironment(t, "")
+		defer cleanup()
+
+		assertConfig(t, load(t), config.Default())
+	})
+
+	t.Run("the config file overrides the defaults", func(t *testing.T) {
+		cleanup := NewTestEnvironment(t, `{"db_file": "/var/lib/cookme.db", "timeout": "10s"}`)
+		defer cleanup()
+
+		want := config.Default()
+		want.DBFile = "/var/lib/cookme.db"
+		want.Timeout = 10 * time.Second
+
+		assertConfig(t, load(t), want)
+	})
+
+	t.Run("environment variables override the config file", func(t *testing.T) {
+		cleanup := NewTestEnvironment(t, `{"db_file": "/var/lib/cookme.db", "recipe_address": "recipes:5000"}`)
+		defer cleanup()
+
+		setenv(t, "COOKME_RECIPE_ADDRESS", "kitchen:5000")
+
+		want := config.Default()
+		want.DBFile = "/var/lib/cookme.db"
+		want.RecipeAddress = "kitchen:5000"
+
+		assertConfig(t, load(t), want)
+	})
+
+	t.Run("flags override environment variables", func(t *testing.T) {
+		cleanup := NewTestEnvironment(t, `{"timeout": "10s"}`)
+		defer cleanup()
+
+		setenv(t, "COOKME_TIMEOUT", "20s")
+		setenv(t, "COOKME_DB_FILE", "env.db")
+
+		want := config.Default()
+		want.Timeout = time.Second
+		want.DBFile = "env.db"
+
+		assertConfig(t, load(t, "--timeout", "1s"), want)
+	})
+
+	t.Run("reads true or false settings", func(t *testing.T) {
+		cleanup := NewTestEnvironment(t, `{"require_token": "true"}`)
+		defer cleanup()
+
+		want := config.Default()
+		want.RequireToken = true
+
+		assertConfig(t, load(t), want)
+
+		setenv(t, "COOKME_REQUIRE_TOKEN", "false")
+
+		assertConfig(t, load(t), config.Default())
+
+		want.RequireToken = true
+		assertConfig(t, load(t, "--require-token"), want)
+	})
+
+	t.Run("reads the config file named by --config", func(t *testing.T) {
+		cleanup := NewTestEnvironment(t, "")
+		defer cleanup()
+
+		filename := writeConfigFile(t, os.Getenv("XDG_CONFIG_HOME"), "other.json", `{"web_listen": ":9000"}`)
+
+		want := config.Default()
+		want.WebListen = ":9000"
+
+		assertConfig(t, load(t, "--config", filename), want)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		cases := map[string]struct {
+			file string
+			env  map[string]string
+			args []string
+		}{
+			"unknown setting in the file":  {file: `{"colour": "blue"}`},
+			"invalid JSON in the file":     {file: `db_file = "cookme.db"`},
+			"invalid duration in the file": {file: `{"timeout": "soon"}`},
+			"invalid duration in the env":  {env: map[string]string{"COOKME_TIMEOUT": "soon"}},
+			"invalid bool in the env":      {env: map[string]string{"COOKME_REQUIRE_TOKEN": "maybe"}},
+			"missing chosen config file":   {args: []string{"--config", "does-not-exist.json"}},
+		}
+
+		for name, c := range cases {
+			t.Run(name, func(t *testing.T) {
+				cleanup := NewTestEnvironment(t, c.file)
+				defer cleanup()
+
+				for key, value := range c.env {
+					setenv(t, key, value)
+				}
+
+				flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+				loader := config.Bind(flags, config.Timeout)
+
+				if err := flags.Parse(c.args); err != nil {
+					t.Fatalf("problem parsing flags %v", err)
+				}
+
+				if _, err := loader.Load(); err == nil {
+					t.Error("expected an error but didn't get one")
+				}
+			})
+		}
+	})
+}
+
+func TestLogger(t *testing.T) {
+	if _, err := config.Default().Logger(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	for _, c := range []config.Config{{LogLevel: "loud", LogFormat: "text"}, {LogLevel: "info", LogFormat: "xml"}} {
+		if _, err := c.Logger(); err == nil {
+			t.Errorf("expected an error for %+v", c)
+		}
+	}
+}
+
+func TestTracer(t *testing.T) {
+	for _, exporter := range []string{"", "stderr", "otlp"} {
+		c := config.Default()
+		c.TraceExporter = exporter
+
+		tracer, err := c.Tracer("test")
+
+		if err != nil {
+			t.Errorf("unexpected error for %q, %v", exporter, err)
+			continue
+		}
+
+		tracer.Close()
+	}
+
+	for _, exporter := range []string{"zipkin", "stdout"} {
+		c := config.Default()
+		c.TraceExporter = exporter
+
+		if _, err := c.Tracer("test"); err == nil {
+			t.Errorf("expected an error for %q", exporter)
+		}
+	}
+}
+
+func TestResolveHousehold(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+
+	if err != nil {
+		t.Fatalf("problem creating temporary directory %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dbFile := filepath.Join(dir, "cookme.db")
+	tokens, err := auth.NewTokens(dbFile)
+
+	if err != nil {
+		t.Fatalf("problem creating db %v", err)
+	}
+
+	secret, _, err := tokens.Create("home", auth.Reader, time.Now())
+
+	if err != nil {
+		t.Fatalf("problem creating token %v", err)
+	}
+
+	cases := []struct {
+		name      string
+		household string
+		token     string
+		want      string
+	}{
+		{name: "nothing chosen and no token", want: household.Default},
+		{name: "a household chosen", household: "office", want: "office"},
+		{name: "a token", token: secret, want: "home"},
+		{name: "a household chosen and a token", household: "office", token: secret, want: "office"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			conf := config.Default()
+			conf.DBFile, conf.Household, conf.Token = dbFile, c.household, c.token
+
+			got, err := conf.ResolveHousehold()
+
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if got != c.want {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+
+	t.Run("an unknown token is an error, as its household can't be told", func(t *testing.T) {
+		conf := config.Default()
+		conf.DBFile, conf.Token = dbFile, "guess"
+
+		if _, err := conf.ResolveHousehold(); err == nil {
+			t.Error("expected an error for an unknown token")
+		}
+	})
+}
+
+func load(t *testing.T, args ...string) config.Config {
+	t.Helper()
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	loader := config.Bind(flags, config.DBFile, config.RecipeAddress, config.WebListen, config.Timeout, config.RequireToken)
+
+	if err := flags.Parse(args); err != nil {
+		t.Fatalf("problem parsing flags %v", err)
+	}
+
+	c, err := loader.Load()
+
+	if err != nil {
+		t.Fatalf("problem loading config %v", err)
+	}
+
+	return c
+}
+
+func assertConfig(t *testing.T, got, want config.Config) {
+	t.Helper()
+	if !cmp.Equal(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// NewTestEnvironment points XDG_CONFIG_HOME at a new directory holding file as cookme/config.json, unless file is
+// empty, and clears every COOKME_ environment variable
+func NewTestEnvironment(t *testing.T, file string) (cleanup func()) {
+	t.Helper()
+
+	dir := filepath.Join(os.TempDir(), cookme.RandomString())
+	restore := map[string]*string{}
+
+	for _, key := range []string{"XDG_CONFIG_HOME", "COOKME_CONFIG", "COOKME_DB_FILE", "COOKME_RECIPE_ADDRESS", "COOKME_RECIPE_LISTEN", "COOKME_API_LISTEN", "COOKME_WEB_LISTEN", "COOKME_TIMEOUT",
+		"COOKME_RECIPE_CA", "COOKME_RECIPE_CERT", "COOKME_RECIPE_KEY", "COOKME_TLS_CERT", "COOKME_TLS_KEY", "COOKME_TLS_CLIENT_CA",
+		"COOKME_TOKEN", "COOKME_REQUIRE_TOKEN", "COOKME_HOUSEHOLD", "COOKME_LOG_LEVEL", "COOKME_LOG_FORMAT", "COOKME_ADMIN_LISTEN",
+		"COOKME_TRACE_EXPORTER", "COOKME_TRACE_FILE", "COOKME_TRACE_ENDPOINT"} {
+		restore[key] = nil
+		if value, ok := os.LookupEnv(key); ok {
+			restore[key] = &value
+		}
+		os.Unsetenv(key)
+	}
+
+	os.Setenv("XDG_CONFIG_HOME", dir)
+
+	if file != "" {
+		writeConfigFile(t, filepath.Join(dir, "cookme"), "config.json", file)
+	}
+
+	return func() {
+		os.RemoveAll(dir)
+		for key, value := range restore {
+			if value == nil {
+				os.Unsetenv(key)
+			} else {
+				os.Setenv(key, *value)
+			}
+		}
+	}
+}
+
+func writeConfigFile(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatalf("problem creating config dir %v", err)
+	}
+
+	filename := filepath.Join(dir, name)
+
+	if err := ioutil.WriteFile(filename, []byte(contents), 0600); err != nil {
+		t.Fatalf("problem writing config file %v", err)
+	}
+
+	return filename
+}
+
+func setenv(t *testing.T, key, value string) {
+	t.Helper()
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatalf("problem setting %s %v", key, err)
+	}
+}
@@ -0,0 +1,186 @@
+package cookme
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// RecipeRepoContract is what every RecipeRepo must do. Run it against a new implementation to check it can be used in
+// place of the others
+type RecipeRepoContract struct {
+	// NewRepo returns a repo holding recipes, in that order, and a func to clean it up
+	NewRepo func(t *testing.T, recipes Recipes) (repo RecipeRepo, cleanup func())
+}
+
+// Test runs the contract against repos from NewRepo
+func (c RecipeRepoContract) Test(t *testing.T) {
+
+	macAndCheese := NewRecipe("Mac and cheese", Ingredient{Name: "Pasta"}, Ingredient{Name: "Cheese"})
+	macAndCheese.Rating = 4
+	macAndCheese.Favourite = true
+
+	cheesyMilk := NewRecipe("Cheesy milk", Ingredient{Name: "Milk"}, Ingredient{Name: "Cheese"})
+	cheesyMilk.Rating = 1
+
+	toast := NewRecipe("Toast", Ingredient{Name: "Bread"})
+	toast.Rating = 3
+
+	bakedMacAndCheese := NewRecipe("Mac and cheese", Ingredient{Name: "Pasta"}, Ingredient{Name: "Cheese"}, Ingredient{Name: "Breadcrumbs"})
+	bakedMacAndCheese.Rating = 5
+
+	t.Run("returns no recipes when it has none", func(t *testing.T) {
+		repo, cleanup := c.NewRepo(t, nil)
+		defer cleanup()
+
+		if got := repo.Recipes(); len(got) != 0 {
+			t.Errorf("expected no recipes but got %+v", got)
+		}
+	})
+
+	t.Run("returns no recipes and no error as part of the work in a context when it has none", func(t *testing.T) {
+		repo, cleanup := c.NewRepo(t, nil)
+		defer cleanup()
+
+		got, err := RecipesContext(context.Background(), repo)
+
+		if err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+
+		if len(got) != 0 {
+			t.Errorf("expected no recipes but got %+v", got)
+		}
+	})
+
+	t.Run("returns every recipe with its ingredients, rating and whether it's a favourite", func(t *testing.T) {
+		repo, cleanup := c.NewRepo(t, Recipes{macAndCheese, cheesyMilk})
+		defer cleanup()
+
+		AssertRecipesEqual(t, repo.Recipes(), Recipes{macAndCheese, cheesyMilk})
+	})
+
+	t.Run("returns the same recipes each time", func(t *testing.T) {
+		repo, cleanup := c.NewRepo(t, Recipes{macAndCheese})
+		defer cleanup()
+
+		repo.Recipes()
+
+		AssertRecipesEqual(t, repo.Recipes(), Recipes{macAndCheese})
+	})
+
+	t.Run("returns the same recipes as part of the work in a context", func(t *testing.T) {
+		repo, cleanup := c.NewRepo(t, Recipes{macAndCheese, cheesyMilk})
+		defer cleanup()
+
+		AssertRecipesEqual(t, RecipesFor(context.Background(), repo), Recipes{macAndCheese, cheesyMilk})
+	})
+
+	t.Run("returns recipes in the order they were added, not by name or rating", func(t *testing.T) {
+		repo, cleanup := c.NewRepo(t, Recipes{cheesyMilk, toast, macAndCheese})
+		defer cleanup()
+
+		AssertRecipesEqual(t, repo.Recipes(), Recipes{cheesyMilk, toast, macAndCheese})
+	})
+
+	t.Run("keeps recipes with the same name as separate recipes", func(t *testing.T) {
+		repo, cleanup := c.NewRepo(t, Recipes{macAndCheese, cheesyMilk, bakedMacAndCheese})
+		defer cleanup()
+
+		AssertRecipesEqual(t, repo.Recipes(), Recipes{macAndCheese, cheesyMilk, bakedMacAndCheese})
+	})
+}
+
+// IngredientsRepoContract is what every IngredientsRepo must do. Run it against a new implementation to check it can
+// be used in place of the others
+type IngredientsRepoContract struct {
+	// NewRepo returns a repo holding ingredients, in that order, and a func to clean it up
+	NewRepo func(t *testing.T, ingredients PerishableIngredients) (repo IngredientsRepo, cleanup func())
+}
+
+// Test runs the contract against repos from NewRepo
+func (c IngredientsRepoContract) Test(t *testing.T) {
+
+	// repos needn't list batches which have expired, so these are all still good
+	expiry := time.Now().Add(24 * time.Hour)
+
+	milk := Ingredient{Name: "Milk"}.ExpiresAt(expiry)
+	milk.BatchID = "milk-1"
+	milk.Quantity = 2
+	milk.Category = "Dairy"
+
+	pasta := Ingredient{Name: "Pasta"}.ExpiresAt(expiry.Add(2000 * time.Hour))
+	pasta.BatchID = "pasta-1"
+	pasta.Quantity = 1
+
+	cheese := Ingredient{Name: "Cheese"}.ExpiresAt(expiry.Add(200 * time.Hour))
+	cheese.BatchID = "cheese-1"
+	cheese.Quantity = 3
+	cheese.Category = "Dairy"
+
+	moreMilk := Ingredient{Name: "Milk"}.ExpiresAt(expiry.Add(48 * time.Hour))
+	moreMilk.BatchID = "milk-2"
+	moreMilk.Quantity = 1
+	moreMilk.Category = "Dairy"
+
+	t.Run("returns no ingredients when it has none", func(t *testing.T) {
+		repo, cleanup := c.NewRepo(t, nil)
+		defer cleanup()
+
+		if got := repo.Ingredients(); len(got) != 0 {
+			t.Errorf("expected no ingredients but got %+v", got)
+		}
+	})
+
+	t.Run("returns no ingredients and no error as part of the work in a context when it has none", func(t *testing.T) {
+		repo, cleanup := c.NewRepo(t, nil)
+		defer cleanup()
+
+		got, err := IngredientsContext(context.Background(), repo)
+
+		if err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+
+		if len(got) != 0 {
+			t.Errorf("expected no ingredients but got %+v", got)
+		}
+	})
+
+	t.Run("returns every batch with its expiration date, quantity and category", func(t *testing.T) {
+		repo, cleanup := c.NewRepo(t, PerishableIngredients{milk, pasta})
+		defer cleanup()
+
+		AssertPerishableIngredientsEqual(t, repo.Ingredients(), PerishableIngredients{milk, pasta})
+	})
+
+	t.Run("returns the same ingredients each time", func(t *testing.T) {
+		repo, cleanup := c.NewRepo(t, PerishableIngredients{milk})
+		defer cleanup()
+
+		repo.Ingredients()
+
+		AssertPerishableIngredientsEqual(t, repo.Ingredients(), PerishableIngredients{milk})
+	})
+
+	t.Run("returns the same ingredients as part of the work in a context", func(t *testing.T) {
+		repo, cleanup := c.NewRepo(t, PerishableIngredients{milk, pasta})
+		defer cleanup()
+
+		AssertPerishableIngredientsEqual(t, IngredientsFor(context.Background(), repo), PerishableIngredients{milk, pasta})
+	})
+
+	t.Run("returns batches in the order they were added, not by name or expiry", func(t *testing.T) {
+		repo, cleanup := c.NewRepo(t, PerishableIngredients{pasta, cheese, milk})
+		defer cleanup()
+
+		AssertPerishableIngredientsEqual(t, repo.Ingredients(), PerishableIngredients{pasta, cheese, milk})
+	})
+
+	t.Run("keeps every batch of the same ingredient", func(t *testing.T) {
+		repo, cleanup := c.NewRepo(t, PerishableIngredients{milk, pasta, moreMilk})
+		defer cleanup()
+
+		AssertPerishableIngredientsEqual(t, repo.Ingredients(), PerishableIngredients{milk, pasta, moreMilk})
+	})
+}
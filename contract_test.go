@@ -0,0 +1,40 @@
+package cookme_test
+
+import (
+	"github.com/quii/monolith-to-micro"
+	"testing"
+)
+
+func TestRecipeRepoContract(t *testing.T) {
+
+	t.Run("stub", cookme.RecipeRepoContract{
+		NewRepo: func(t *testing.T, recipes cookme.Recipes) (cookme.RecipeRepo, func()) {
+			return newStubRecipeRepo(recipes...), func() {}
+		},
+	}.Test)
+
+	t.Run("RecipeRepoFunc", cookme.RecipeRepoContract{
+		NewRepo: func(t *testing.T, recipes cookme.Recipes) (cookme.RecipeRepo, func()) {
+			return cookme.RecipeRepoFunc(func() cookme.Recipes {
+				return recipes
+			}), func() {}
+		},
+	}.Test)
+}
+
+func TestIngredientsRepoContract(t *testing.T) {
+
+	t.Run("stub", cookme.IngredientsRepoContract{
+		NewRepo: func(t *testing.T, ingredients cookme.PerishableIngredients) (cookme.IngredientsRepo, func()) {
+			return newStubIngredientsRepo(ingredients...), func() {}
+		},
+	}.Test)
+
+	t.Run("IngredientsRepoFunc", cookme.IngredientsRepoContract{
+		NewRepo: func(t *testing.T, ingredients cookme.PerishableIngredients) (cookme.IngredientsRepo, func()) {
+			return cookme.IngredientsRepoFunc(func() cookme.PerishableIngredients {
+				return ingredients
+			}), func() {}
+		},
+	}.Test)
+}
@@ -1,6 +1,9 @@
 package cookme
 
-import "log"
+import (
+	"context"
+	"github.com/quii/monolith-to-micro/tracing"
+)
 
 // IngredientsRepo returns a collection of ingredients
 type IngredientsRepo interface {
@@ -20,6 +23,33 @@ type RecipeRepo interface {
 	Recipes() Recipes
 }
 
+// ContextIngredientsRepo is an IngredientsRepo which can get the ingredients as part of the work in a context, so
+// getting them is traced as part of it
+type ContextIngredientsRepo interface {
+	IngredientsRepo
+	IngredientsFor(ctx context.Context) PerishableIngredients
+}
+
+// ContextRecipeRepo is a RecipeRepo which can get the recipes as part of the work in a context, so getting them is
+// traced as part of it
+type ContextRecipeRepo interface {
+	RecipeRepo
+	RecipesFor(ctx context.Context) Recipes
+}
+
+// FallibleIngredientsRepo is an IngredientsRepo which can say why it couldn't get the ingredients, rather than
+// returning none
+type FallibleIngredientsRepo interface {
+	IngredientsRepo
+	IngredientsContext(ctx context.Context) (PerishableIngredients, error)
+}
+
+// FallibleRecipeRepo is a RecipeRepo which can say why it couldn't get the recipes, rather than returning none
+type FallibleRecipeRepo interface {
+	RecipeRepo
+	RecipesContext(ctx context.Context) (Recipes, error)
+}
+
 // RecipeRepoFunc allows you to implement RecipeRepo with a func
 type RecipeRepoFunc func() Recipes
 
@@ -28,14 +58,55 @@ func (f RecipeRepoFunc) Recipes() Recipes {
 	return f()
 }
 
-// ListRecipes describes what meals should be cooked given the expiration dates of the IngredientsRepo
-func ListRecipes(ingredientsRepo IngredientsRepo, recipeRepo RecipeRepo) Recipes {
+// ListRecipes describes what meals should be cooked given the expiration dates of the IngredientsRepo, best scored first
+func ListRecipes(ingredientsRepo IngredientsRepo, recipeRepo RecipeRepo, scorers ...RecipeScorer) Recipes {
+	return ListRecipesContext(context.Background(), ingredientsRepo, recipeRepo, scorers...)
+}
+
+// ListRecipesContext is ListRecipes as part of the work in ctx, traced as a span of it
+func ListRecipesContext(ctx context.Context, ingredientsRepo IngredientsRepo, recipeRepo RecipeRepo, scorers ...RecipeScorer) Recipes {
+	ctx, span := tracing.Start(ctx, "ListRecipes")
+	defer span.End()
+
+	ingredients := IngredientsFor(ctx, ingredientsRepo)
+	recipes := RecipesFor(ctx, recipeRepo)
 
-	ingredients := ingredientsRepo.Ingredients().SortByExpirationDate()
-	recipes := recipeRepo.Recipes()
+	found := FindRecipes(recipes, ingredients.SortByExpirationDate()).SortByScore(scorers...)
+	span.SetAttributes("ingredients", len(ingredients), "recipes", len(recipes), "suggestions", len(found))
 
-	log.Printf("All ingredients %+v\n", ingredients)
-	log.Printf("All recipes %+v\n", recipes)
+	return found
+}
+
+// IngredientsFor gets the ingredients from repo, as part of the work in ctx if it is a ContextIngredientsRepo
+func IngredientsFor(ctx context.Context, repo IngredientsRepo) PerishableIngredients {
+	if repo, ok := repo.(ContextIngredientsRepo); ok {
+		return repo.IngredientsFor(ctx)
+	}
+	return repo.Ingredients()
+}
+
+// IngredientsContext gets the ingredients from repo as part of the work in ctx, along with why it couldn't if it is a
+// FallibleIngredientsRepo
+func IngredientsContext(ctx context.Context, repo IngredientsRepo) (PerishableIngredients, error) {
+	if repo, ok := repo.(FallibleIngredientsRepo); ok {
+		return repo.IngredientsContext(ctx)
+	}
+	return IngredientsFor(ctx, repo), nil
+}
+
+// RecipesFor gets the recipes from repo, as part of the work in ctx if it is a ContextRecipeRepo
+func RecipesFor(ctx context.Context, repo RecipeRepo) Recipes {
+	if repo, ok := repo.(ContextRecipeRepo); ok {
+		return repo.RecipesFor(ctx)
+	}
+	return repo.Recipes()
+}
 
-	return FindRecipes(recipes, ingredients)
+// RecipesContext gets the recipes from repo as part of the work in ctx, along with why it couldn't if it is a
+// FallibleRecipeRepo
+func RecipesContext(ctx context.Context, repo RecipeRepo) (Recipes, error) {
+	if repo, ok := repo.(FallibleRecipeRepo); ok {
+		return repo.RecipesContext(ctx)
+	}
+	return RecipesFor(ctx, repo), nil
 }
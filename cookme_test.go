@@ -30,6 +30,96 @@ func TestListIngredients(t *testing.T) {
 		cookme.AssertRecipesEqual(t, got, want)
 	})
 
+	t.Run("suggests recipes that were cooked recently last", func(t *testing.T) {
+		history := cookme.CookingHistory{
+			{Name: "Mac and cheese", CookedAt: time.Now().Add(-24 * time.Hour)},
+			{Name: "Cheesy milk", CookedAt: time.Now().Add(-30 * 24 * time.Hour)},
+		}
+
+		got := cookme.ListRecipes(
+			newStubIngredientsRepo(
+				milk.ExpiresAt(time.Now().Add(72*time.Hour)),
+				cheese.ExpiresAt(time.Now().Add(48*time.Hour)),
+				pasta.ExpiresAt(time.Now().Add(2000*time.Hour)),
+			),
+			newStubRecipeRepo(macAndCheese, cheesyMilk),
+			cookme.DownRankRecentlyCooked(history, time.Now().Add(-7*24*time.Hour)),
+		)
+
+		want := cookme.Recipes{cheesyMilk, macAndCheese}
+
+		cookme.AssertRecipesEqual(t, got, want)
+	})
+
+	t.Run("suggests recipes that were cooked recently last however well they score", func(t *testing.T) {
+		lovedMacAndCheese := macAndCheese
+		lovedMacAndCheese.Rating = 5
+
+		hatedCheesyMilk := cheesyMilk
+		hatedCheesyMilk.Rating = 1
+
+		ingredients := cookme.PerishableIngredients{
+			milk.ExpiresAt(time.Now().Add(2000 * time.Hour)),
+			cheese.ExpiresAt(time.Now().Add(2000 * time.Hour)),
+			pasta.ExpiresAt(time.Now().Add(time.Hour)),
+		}
+
+		history := cookme.CookingHistory{
+			{Name: "Mac and cheese", CookedAt: time.Now().Add(-24 * time.Hour)},
+		}
+
+		got := cookme.ListRecipes(
+			newStubIngredientsRepo(ingredients...),
+			newStubRecipeRepo(lovedMacAndCheese, hatedCheesyMilk),
+			cookme.ScoreByRating(10),
+			cookme.ScoreByExpiry(ingredients, time.Now(), 10),
+			cookme.DownRankRecentlyCooked(history, time.Now().Add(-7*24*time.Hour)),
+		)
+
+		want := cookme.Recipes{hatedCheesyMilk, lovedMacAndCheese}
+
+		cookme.AssertRecipesEqual(t, got, want)
+	})
+
+	t.Run("blends ratings with how soon ingredients expire", func(t *testing.T) {
+		lovedMacAndCheese := macAndCheese
+		lovedMacAndCheese.Rating = 5
+
+		hatedCheesyMilk := cheesyMilk
+		hatedCheesyMilk.Rating = 1
+
+		ingredients := cookme.PerishableIngredients{
+			milk.ExpiresAt(time.Now().Add(24 * time.Hour)),
+			cheese.ExpiresAt(time.Now().Add(240 * time.Hour)),
+			pasta.ExpiresAt(time.Now().Add(2000 * time.Hour)),
+		}
+
+		got := cookme.ListRecipes(
+			newStubIngredientsRepo(ingredients...),
+			newStubRecipeRepo(hatedCheesyMilk, lovedMacAndCheese),
+			cookme.ScoreByRating(0.5),
+			cookme.ScoreByExpiry(ingredients, time.Now(), 0.5),
+		)
+
+		want := cookme.Recipes{lovedMacAndCheese, hatedCheesyMilk}
+
+		cookme.AssertRecipesEqual(t, got, want)
+	})
+
+	t.Run("scores recipes with the same name separately", func(t *testing.T) {
+		hatedMacAndCheese := macAndCheese
+		hatedMacAndCheese.Rating = 1
+
+		lovedMacAndCheese := macAndCheese
+		lovedMacAndCheese.Rating = 5
+
+		got := cookme.Recipes{hatedMacAndCheese, cheesyMilk, lovedMacAndCheese}.SortByScore(cookme.ScoreByRating(1))
+
+		want := cookme.Recipes{lovedMacAndCheese, cheesyMilk, hatedMacAndCheese}
+
+		cookme.AssertRecipesEqual(t, got, want)
+	})
+
 	t.Run("prints no recipes if there aren't any", func(t *testing.T) {
 		got := cookme.ListRecipes(
 			newStubIngredientsRepo(milk.ExpiresAt(time.Now().Add(72*time.Hour))),
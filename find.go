@@ -1,19 +1,7 @@
 package cookme
 
-// FindRecipes finds appropriate recipes to cook given a list of recipes and perishable ingredients
+// FindRecipes finds appropriate recipes to cook given a list of recipes and perishable ingredients. If you are going
+// to check the same recipes against many inventories use a Matcher directly to only prepare them once
 func FindRecipes(recipes Recipes, ingredients PerishableIngredients) (foundRecipes Recipes) {
-	for _, recipe := range recipes {
-		allIngredientsFound := true
-		for _, requiredIngredient := range recipe.Ingredients {
-			if !ingredients.Contains(requiredIngredient) {
-				allIngredientsFound = false
-			}
-		}
-
-		if allIngredientsFound {
-			foundRecipes = append(foundRecipes, recipe)
-		}
-	}
-
-	return
+	return NewMatcher(recipes).Find(ingredients)
 }
@@ -0,0 +1,129 @@
+package cookme_test
+
+import (
+	"fmt"
+	"github.com/quii/monolith-to-micro"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestFindRecipes(t *testing.T) {
+
+	t.Run("finds the same recipes as comparing every ingredient", func(t *testing.T) {
+		random := rand.New(rand.NewSource(1))
+
+		for i := 0; i < 100; i++ {
+			recipes, ingredients := randomKitchen(random, 50, 20, 30)
+
+			got := cookme.FindRecipes(recipes, ingredients)
+			want := nestedLoopFindRecipes(recipes, ingredients)
+
+			cookme.AssertRecipesEqual(t, got, want)
+		}
+	})
+
+	t.Run("finds the same recipes with more than 64 different ingredients, so the sets of them take more than one word", func(t *testing.T) {
+		random := rand.New(rand.NewSource(2))
+
+		for i := 0; i < 100; i++ {
+			recipes, ingredients := randomKitchen(random, 200, 200, 400)
+
+			got := cookme.FindRecipes(recipes, ingredients)
+			want := nestedLoopFindRecipes(recipes, ingredients)
+
+			if len(want) == 0 {
+				t.Fatal("expected the kitchen to have recipes which can be cooked, so the test checks something")
+			}
+
+			cookme.AssertRecipesEqual(t, got, want)
+		}
+	})
+
+	t.Run("matches ingredients ignoring case", func(t *testing.T) {
+		cheeseOnToast := cookme.NewRecipe("Cheese on toast", cookme.Ingredient{Name: "cheese"}, cookme.Ingredient{Name: "Bread"})
+
+		got := cookme.FindRecipes(cookme.Recipes{cheeseOnToast}, cookme.PerishableIngredients{
+			cookme.Ingredient{Name: "CHEESE"}.ExpiresAt(time.Now()),
+			cookme.Ingredient{Name: "bread"}.ExpiresAt(time.Now()),
+		})
+
+		cookme.AssertRecipesEqual(t, got, cookme.Recipes{cheeseOnToast})
+	})
+
+	t.Run("recipes without ingredients can always be cooked", func(t *testing.T) {
+		water := cookme.NewRecipe("Glass of water")
+
+		cookme.AssertRecipesEqual(t, cookme.FindRecipes(cookme.Recipes{water}, nil), cookme.Recipes{water})
+	})
+}
+
+func BenchmarkFindRecipes(b *testing.B) {
+	recipes, ingredients := randomKitchen(rand.New(rand.NewSource(1)), 20000, 500, 100)
+
+	b.Run("nested loop", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			nestedLoopFindRecipes(recipes, ingredients)
+		}
+	})
+
+	b.Run("matcher including preparation", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			cookme.FindRecipes(recipes, ingredients)
+		}
+	})
+
+	b.Run("prepared matcher", func(b *testing.B) {
+		matcher := cookme.NewMatcher(recipes)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			matcher.Find(ingredients)
+		}
+	})
+}
+
+// nestedLoopFindRecipes is how FindRecipes used to work, kept to check the Matcher gives the same results and is faster
+func nestedLoopFindRecipes(recipes cookme.Recipes, ingredients cookme.PerishableIngredients) (foundRecipes cookme.Recipes) {
+	for _, recipe := range recipes {
+		allIngredientsFound := true
+		for _, requiredIngredient := range recipe.Ingredients {
+			if !ingredients.Contains(requiredIngredient) {
+				allIngredientsFound = false
+			}
+		}
+
+		if allIngredientsFound {
+			foundRecipes = append(foundRecipes, recipe)
+		}
+	}
+
+	return
+}
+
+// randomKitchen makes recipes of up to 5 ingredients and an inventory drawn from the same pool of ingredients, with
+// randomly changed case to check matching ignores it
+func randomKitchen(random *rand.Rand, recipeCount, pantrySize, inventorySize int) (cookme.Recipes, cookme.PerishableIngredients) {
+	names := []string{"ingredient %d", "Ingredient %d", "INGREDIENT %d"}
+	ingredient := func() cookme.Ingredient {
+		return cookme.Ingredient{Name: fmt.Sprintf(names[random.Intn(len(names))], random.Intn(pantrySize))}
+	}
+
+	var recipes cookme.Recipes
+	for i := 0; i < recipeCount; i++ {
+		recipe := cookme.NewRecipe(fmt.Sprintf("recipe %d", i))
+		for j := random.Intn(5); j >= 0; j-- {
+			recipe.Ingredients = append(recipe.Ingredients, ingredient())
+		}
+		recipes = append(recipes, recipe)
+	}
+
+	var ingredients cookme.PerishableIngredients
+	for i := 0; i < inventorySize; i++ {
+		ingredients = append(ingredients, ingredient().ExpiresAt(time.Now()))
+	}
+
+	return recipes, ingredients
+}
@@ -0,0 +1,59 @@
+package cookme
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CookedRecipe records when a recipe was cooked
+type CookedRecipe struct {
+	Name     string
+	CookedAt time.Time
+}
+
+func (c CookedRecipe) String() string {
+	return fmt.Sprintf("%s cooked %s", c.Name, c.CookedAt.Format("Mon 2 Jan 2006"))
+}
+
+// CookingHistory is a collection of CookedRecipe, oldest first
+type CookingHistory []CookedRecipe
+
+// Since returns the recipes cooked after t
+func (history CookingHistory) Since(t time.Time) (cooked CookingHistory) {
+	for _, c := range history {
+		if c.CookedAt.After(t) {
+			cooked = append(cooked, c)
+		}
+	}
+	return
+}
+
+// Contains tells you if the recipe has been cooked
+func (history CookingHistory) Contains(recipe Recipe) bool {
+	for _, c := range history {
+		if strings.EqualFold(c.Name, recipe.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// DownRankRecentlyCooked is a RecipeDemoter which puts recipes cooked after since at the bottom of the suggestions so they rotate
+func DownRankRecentlyCooked(history CookingHistory, since time.Time) RecipeDemoter {
+	return recentlyCooked{history.Since(since)}
+}
+
+type recentlyCooked struct {
+	cooked CookingHistory
+}
+
+// Score leaves the order to Demote
+func (r recentlyCooked) Score(recipe Recipe) float64 {
+	return 0
+}
+
+// Demote tells you if the recipe was cooked recently
+func (r recentlyCooked) Demote(recipe Recipe) bool {
+	return r.cooked.Contains(recipe)
+}
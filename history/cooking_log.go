@@ -0,0 +1,60 @@
+package history
+
+import (
+	"encoding/json"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/bucket"
+	"github.com/quii/monolith-to-micro/household"
+	"github.com/quii/monolith-to-micro/logging"
+	"time"
+)
+
+// CookingLog records which recipes were cooked when, persisting the data in the filesystem
+type CookingLog struct {
+	boltBucket *bucket.BoltBucket
+}
+
+const bucketName = "history"
+
+// NewCookingLog creates a new cooking log for the default household, creating the db file if needed
+func NewCookingLog(dbFilename string) (*CookingLog, error) {
+	return NewCookingLogFor(dbFilename, household.Default)
+}
+
+// NewCookingLogFor creates a new cooking log for a household, kept apart from every other household's
+func NewCookingLogFor(dbFilename string, householdID string) (*CookingLog, error) {
+	boltBucket, err := bucket.NewBoltBucket(dbFilename, household.Bucket(bucketName, householdID))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &CookingLog{boltBucket: boltBucket}, nil
+}
+
+// History lists everything that has been cooked, oldest first
+func (c *CookingLog) History() cookme.CookingHistory {
+	var history cookme.CookingHistory
+
+	data, err := c.boltBucket.Get()
+
+	if err != nil {
+		logging.Error("problem getting history", "err", err)
+		return nil
+	}
+
+	json.Unmarshal(data, &history)
+
+	return history
+}
+
+// Cooked records that the recipe called name was cooked at t
+func (c *CookingLog) Cooked(name string, t time.Time) error {
+	newHistory := append(c.History(), cookme.CookedRecipe{Name: name, CookedAt: t})
+	return c.boltBucket.Put(asJSON(newHistory))
+}
+
+func asJSON(history cookme.CookingHistory) []byte {
+	b, _ := json.Marshal(history)
+	return b
+}
@@ -0,0 +1,60 @@
+package history_test
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/history"
+	"log"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCookingLog(t *testing.T) {
+
+	monday := time.Date(2019, time.January, 7, 19, 0, 0, 0, time.UTC)
+	tuesday := monday.Add(24 * time.Hour)
+
+	t.Run("empty log returns no history", func(t *testing.T) {
+		cookingLog, cleanup := NewTestCookingLog(t)
+		defer cleanup()
+
+		assertHistoryEqual(t, cookingLog.History(), nil)
+	})
+
+	t.Run("cooking a recipe records it in the history", func(t *testing.T) {
+		cookingLog, cleanup := NewTestCookingLog(t)
+		defer cleanup()
+
+		cookingLog.Cooked("Mac and cheese", monday)
+		cookingLog.Cooked("Cheesy milk", tuesday)
+
+		want := cookme.CookingHistory{
+			{Name: "Mac and cheese", CookedAt: monday},
+			{Name: "Cheesy milk", CookedAt: tuesday},
+		}
+
+		assertHistoryEqual(t, cookingLog.History(), want)
+	})
+}
+
+func assertHistoryEqual(t *testing.T, got, want cookme.CookingHistory) {
+	t.Helper()
+	if !cmp.Equal(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func NewTestCookingLog(t *testing.T) (cookingLog *history.CookingLog, cleanup func()) {
+	t.Helper()
+	dbFilename := cookme.RandomString() + ".db"
+	cookingLog, err := history.NewCookingLog(dbFilename)
+
+	if err != nil {
+		log.Fatalf("problem creating cooking log %+v", err)
+	}
+
+	return cookingLog, func() {
+		os.Remove(dbFilename)
+	}
+}
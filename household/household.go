@@ -0,0 +1,69 @@
+package household
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// Default is the household used when none is chosen, it keeps its data where it was before there were households
+	Default = "default"
+
+	// Public is a household whose recipes any household can read, only its own tokens can change them
+	Public = "public"
+
+	// MetadataKey is the gRPC metadata, and HTTP header, choosing the household a call is for
+	MetadataKey = "household"
+)
+
+const maxLength = 64
+
+// Valid checks id can name a household, it must be lower case letters, digits, - or _
+func Valid(id string) error {
+	if id == "" || len(id) > maxLength {
+		return fmt.Errorf("a household must be 1 to %d characters", maxLength)
+	}
+
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return fmt.Errorf("invalid household %q, use lower case letters, digits, - or _", id)
+		}
+	}
+
+	return nil
+}
+
+// Bucket is the bolt bucket holding a household's share of the data in base. The default household uses base itself
+func Bucket(base, id string) string {
+	if id == Default || id == "" {
+		return base
+	}
+	return base + "/" + id
+}
+
+// FromMetadata returns the household asked for in a gRPC call's metadata, or "" if none was
+func FromMetadata(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if values := md.Get(MetadataKey); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// Credentials asks for a household with every call
+type Credentials struct {
+	ID string
+}
+
+// GetRequestMetadata adds the household to the call's metadata
+func (c Credentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{MetadataKey: c.ID}, nil
+}
+
+// RequireTransportSecurity allows the household to be sent in plain text
+func (c Credentials) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,49 @@
+package household_test
+
+import (
+	"context"
+	"github.com/quii/monolith-to-micro/household"
+	"google.golang.org/grpc/metadata"
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	for _, id := range []string{"home", "flat-2", "the_smiths", "public", strings.Repeat("a", 64)} {
+		if err := household.Valid(id); err != nil {
+			t.Errorf("expected %q to be valid but got %v", id, err)
+		}
+	}
+
+	for _, id := range []string{"", "Home", "my house", "../tokens", "home/2", strings.Repeat("a", 65)} {
+		if err := household.Valid(id); err == nil {
+			t.Errorf("expected %q to be invalid", id)
+		}
+	}
+}
+
+func TestBucket(t *testing.T) {
+	cases := map[string]string{
+		household.Default: "recipes",
+		"":                "recipes",
+		"home":            "recipes/home",
+	}
+
+	for id, want := range cases {
+		if got := household.Bucket("recipes", id); got != want {
+			t.Errorf("got %q for %q, want %q", got, id, want)
+		}
+	}
+}
+
+func TestFromMetadata(t *testing.T) {
+	if got := household.FromMetadata(context.Background()); got != "" {
+		t.Errorf("expected no household but got %q", got)
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(household.MetadataKey, "home"))
+
+	if got := household.FromMetadata(ctx); got != "home" {
+		t.Errorf("got %q, want %q", got, "home")
+	}
+}
@@ -17,21 +17,52 @@ type Ingredient struct {
 
 // ExpiresAt returns a PerishableIngredient which expires at t
 func (i Ingredient) ExpiresAt(t time.Time) PerishableIngredient {
-	return PerishableIngredient{i, t}
+	return PerishableIngredient{Ingredient: i, ExpirationDate: t}
 }
 
 // Ingredients is a collection of Ingredients
 type Ingredients []Ingredient
 
-// PerishableIngredient represents an ingredient and when it can be used by
+// PerishableIngredient represents a batch of an ingredient and when it can be used by
 type PerishableIngredient struct {
 	Ingredient
 	ExpirationDate time.Time
+	BatchID        string
+	Quantity       int
+	Category       string
+}
+
+// HasExpired tells you if the ingredient can no longer be used at t
+func (p PerishableIngredient) HasExpired(t time.Time) bool {
+	return !p.ExpirationDate.After(t)
 }
 
 func (p PerishableIngredient) String() string {
-	expiresIn := math.Abs(math.Round(time.Since(p.ExpirationDate).Hours() / 24))
-	return fmt.Sprintf("%s expires %v days", p.Name, expiresIn)
+	return p.StringAt(time.Now())
+}
+
+// StringAt describes the ingredient and when it expires relative to now, e.g. "Milk expired 2 days ago"
+func (p PerishableIngredient) StringAt(now time.Time) string {
+	days := int(math.Round(p.ExpirationDate.Sub(now).Hours() / 24))
+
+	if p.HasExpired(now) {
+		if days == 0 {
+			return fmt.Sprintf("%s expired today", p.Name)
+		}
+		return fmt.Sprintf("%s expired %s ago", p.Name, pluralDays(-days))
+	}
+
+	if days == 0 {
+		return fmt.Sprintf("%s expires today", p.Name)
+	}
+	return fmt.Sprintf("%s expires in %s", p.Name, pluralDays(days))
+}
+
+func pluralDays(days int) string {
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
 }
 
 // PerishableIngredients is a collection of PerishableIngredient
@@ -47,6 +78,58 @@ func (ingredients PerishableIngredients) Contains(needle Ingredient) bool {
 	return false
 }
 
+// GroupByName splits the collection into batches of the same ingredient, in the order each ingredient is first seen
+func (ingredients PerishableIngredients) GroupByName() []PerishableIngredients {
+	var groups []PerishableIngredients
+	positions := make(map[string]int)
+
+	for _, ingredient := range ingredients {
+		key := strings.ToLower(ingredient.Name)
+		position, exists := positions[key]
+
+		if !exists {
+			position = len(groups)
+			positions[key] = position
+			groups = append(groups, nil)
+		}
+
+		groups[position] = append(groups[position], ingredient)
+	}
+
+	return groups
+}
+
+// Expired returns the batches which have expired by now
+func (ingredients PerishableIngredients) Expired(now time.Time) PerishableIngredients {
+	return ingredients.filter(func(i PerishableIngredient) bool {
+		return i.HasExpired(now)
+	})
+}
+
+// ExpiringWithin returns the batches which haven't expired by now but will within window
+func (ingredients PerishableIngredients) ExpiringWithin(now time.Time, window time.Duration) PerishableIngredients {
+	return ingredients.filter(func(i PerishableIngredient) bool {
+		return !i.HasExpired(now) && i.HasExpired(now.Add(window))
+	})
+}
+
+// Named returns the batches whose name contains name, ignoring case
+func (ingredients PerishableIngredients) Named(name string) PerishableIngredients {
+	name = strings.ToLower(name)
+	return ingredients.filter(func(i PerishableIngredient) bool {
+		return strings.Contains(strings.ToLower(i.Name), name)
+	})
+}
+
+func (ingredients PerishableIngredients) filter(keep func(PerishableIngredient) bool) (kept PerishableIngredients) {
+	for _, i := range ingredients {
+		if keep(i) {
+			kept = append(kept, i)
+		}
+	}
+	return
+}
+
 // SortByExpirationDate sorts _in place_ the collection of ingredients
 func (ingredients PerishableIngredients) SortByExpirationDate() PerishableIngredients {
 	sort.Slice(ingredients, func(i, j int) bool {
@@ -0,0 +1,77 @@
+package cookme_test
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"github.com/quii/monolith-to-micro"
+	"testing"
+	"time"
+)
+
+func TestPerishableIngredients(t *testing.T) {
+
+	milk := cookme.Ingredient{Name: "Milk"}
+	cheese := cookme.Ingredient{Name: "Cheese"}
+
+	oldMilk := milk.ExpiresAt(time.Now().Add(24 * time.Hour))
+	newMilk := cookme.Ingredient{Name: "milk"}.ExpiresAt(time.Now().Add(96 * time.Hour))
+	someCheese := cheese.ExpiresAt(time.Now().Add(48 * time.Hour))
+
+	t.Run("groups batches of the same ingredient together", func(t *testing.T) {
+		got := cookme.PerishableIngredients{oldMilk, someCheese, newMilk}.GroupByName()
+
+		want := []cookme.PerishableIngredients{
+			{oldMilk, newMilk},
+			{someCheese},
+		}
+
+		if !cmp.Equal(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("contains an ingredient when any batch of it is present", func(t *testing.T) {
+		ingredients := cookme.PerishableIngredients{oldMilk, newMilk}
+
+		if !ingredients.Contains(milk) {
+			t.Errorf("expected %v to contain %v", ingredients, milk)
+		}
+
+		if ingredients.Contains(cheese) {
+			t.Errorf("expected %v not to contain %v", ingredients, cheese)
+		}
+	})
+
+	t.Run("filters batches by expiry and name", func(t *testing.T) {
+		now := time.Now()
+		expiredMilk := milk.ExpiresAt(now.Add(-24 * time.Hour))
+		ingredients := cookme.PerishableIngredients{expiredMilk, oldMilk, someCheese, newMilk}
+
+		cookme.AssertPerishableIngredientsEqual(t, ingredients.Expired(now), cookme.PerishableIngredients{expiredMilk})
+		cookme.AssertPerishableIngredientsEqual(t, ingredients.ExpiringWithin(now, 72*time.Hour), cookme.PerishableIngredients{oldMilk, someCheese})
+		cookme.AssertPerishableIngredientsEqual(t, ingredients.Named("MILK"), cookme.PerishableIngredients{expiredMilk, oldMilk, newMilk})
+		cookme.AssertPerishableIngredientsEqual(t, ingredients.Named("eggs"), nil)
+	})
+}
+
+func TestPerishableIngredientString(t *testing.T) {
+	now := time.Date(2019, time.March, 10, 18, 0, 0, 0, time.UTC)
+	milk := cookme.Ingredient{Name: "Milk"}
+
+	cases := []struct {
+		expires time.Time
+		want    string
+	}{
+		{now.Add(-48 * time.Hour), "Milk expired 2 days ago"},
+		{now.Add(-24 * time.Hour), "Milk expired 1 day ago"},
+		{now.Add(-time.Hour), "Milk expired today"},
+		{now.Add(time.Hour), "Milk expires today"},
+		{now.Add(24 * time.Hour), "Milk expires in 1 day"},
+		{now.Add(72 * time.Hour), "Milk expires in 3 days"},
+	}
+
+	for _, c := range cases {
+		if got := milk.ExpiresAt(c.expires).StringAt(now); got != c.want {
+			t.Errorf("got %q, want %q", got, c.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package interceptor_test
+
+import (
+	"context"
+	"github.com/google/go-cmp/cmp"
+	"github.com/quii/monolith-to-micro/interceptor"
+	"google.golang.org/grpc"
+	"testing"
+)
+
+func TestChainUnaryServer(t *testing.T) {
+	var calls []string
+
+	record := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			calls = append(calls, name+" before")
+			res, err := handler(ctx, req)
+			calls = append(calls, name+" after")
+			return res, err
+		}
+	}
+
+	chain := interceptor.ChainUnaryServer(record("first"), record("second"))
+
+	res, _ := chain(context.Background(), "req", &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls = append(calls, "handler")
+		return req, nil
+	})
+
+	want := []string{"first before", "second before", "handler", "second after", "first after"}
+
+	if !cmp.Equal(calls, want) {
+		t.Errorf("got %v, want %v", calls, want)
+	}
+
+	if res != "req" {
+		t.Errorf("got %v, want the handler's response", res)
+	}
+}
+
+func TestChainUnaryClient(t *testing.T) {
+	var calls []string
+
+	record := func(name string) grpc.UnaryClientInterceptor {
+		return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			calls = append(calls, name)
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+	}
+
+	chain := interceptor.ChainUnaryClient(record("first"), record("second"))
+
+	chain(context.Background(), "/RecipeService/GetRecipes", nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls = append(calls, "invoker")
+		return nil
+	})
+
+	if want := []string{"first", "second", "invoker"}; !cmp.Equal(calls, want) {
+		t.Errorf("got %v, want %v", calls, want)
+	}
+}
+
+func TestChainStreams(t *testing.T) {
+	var calls []string
+
+	server := interceptor.ChainStreamServer(
+		func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			calls = append(calls, "server")
+			return handler(srv, stream)
+		},
+	)
+
+	server(nil, nil, &grpc.StreamServerInfo{}, func(srv interface{}, stream grpc.ServerStream) error {
+		calls = append(calls, "handler")
+		return nil
+	})
+
+	client := interceptor.ChainStreamClient(
+		func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			calls = append(calls, "client")
+			return streamer(ctx, desc, cc, method, opts...)
+		},
+	)
+
+	client(context.Background(), &grpc.StreamDesc{}, nil, "/RecipeService/WatchRecipes", func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		calls = append(calls, "streamer")
+		return nil, nil
+	})
+
+	if want := []string{"server", "handler", "client", "streamer"}; !cmp.Equal(calls, want) {
+		t.Errorf("got %v, want %v", calls, want)
+	}
+}
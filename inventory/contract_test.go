@@ -0,0 +1,18 @@
+package inventory_test
+
+import (
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/inventory/inventorytest"
+	"testing"
+)
+
+func TestIngredientsRepoContract(t *testing.T) {
+
+	t.Run("HouseInventory", cookme.IngredientsRepoContract{
+		NewRepo: func(t *testing.T, ingredients cookme.PerishableIngredients) (cookme.IngredientsRepo, func()) {
+			inv, cleanup := inventorytest.NewInventory(t)
+			inv.AddIngredients(ingredients...)
+			return inv, cleanup
+		},
+	}.Test)
+}
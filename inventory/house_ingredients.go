@@ -1,25 +1,50 @@
 package inventory
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/quii/monolith-to-micro"
 	"github.com/quii/monolith-to-micro/bucket"
-	"log"
+	"github.com/quii/monolith-to-micro/household"
+	"github.com/quii/monolith-to-micro/logging"
+	"strings"
+	"time"
 )
 
 // HouseInventory manages PerishableIngredients, persisting the data in the filesystem
 type HouseInventory struct {
-	boltBucket *bucket.BoltBucket
+	boltBucket  *bucket.BoltBucket
+	wasteBucket *bucket.BoltBucket
 }
 
-const bucketName = "inventory"
+const (
+	bucketName      = "inventory"
+	wasteBucketName = "waste"
+)
+
+// ErrBatchNotFound is returned when trying to delete a batch of an ingredient that isn't in the inventory
+var ErrBatchNotFound = errors.New("could not find a matching batch")
 
-// NewHouseInventory creates a new house inventory, creating the db file if needed
+// NewHouseInventory creates a new house inventory for the default household, creating the db file if needed
 func NewHouseInventory(dbFilename string) (*HouseInventory, error) {
-	bucket, err := bucket.NewBoltBucket(dbFilename, bucketName)
+	return NewHouseInventoryFor(dbFilename, household.Default)
+}
+
+// NewHouseInventoryFor creates a new house inventory for a household, kept apart from every other household's
+func NewHouseInventoryFor(dbFilename string, householdID string) (*HouseInventory, error) {
+	inventoryBucket, err := bucket.NewBoltBucket(dbFilename, household.Bucket(bucketName, householdID))
+
+	if err != nil {
+		return nil, err
+	}
+
+	wasteBucket, err := bucket.NewBoltBucket(dbFilename, household.Bucket(wasteBucketName, householdID))
 
 	inventory := &HouseInventory{
-		boltBucket: bucket,
+		boltBucket:  inventoryBucket,
+		wasteBucket: wasteBucket,
 	}
 
 	return inventory, err
@@ -27,37 +52,167 @@ func NewHouseInventory(dbFilename string) (*HouseInventory, error) {
 
 // Ingredients lists all the ingredients in the house
 func (h *HouseInventory) Ingredients() cookme.PerishableIngredients {
-	var ingredients cookme.PerishableIngredients
+	return h.IngredientsFor(context.Background())
+}
 
-	data, err := h.boltBucket.Get()
+// IngredientsFor lists all the ingredients in the house as part of the work in ctx, or none if they can't be read
+func (h *HouseInventory) IngredientsFor(ctx context.Context) cookme.PerishableIngredients {
+	ingredients, err := h.IngredientsContext(ctx)
 
 	if err != nil {
-		log.Printf("problem getting data %+v", err)
-		return nil
+		logging.Error("problem reading inventory", "err", err)
 	}
 
-	err = json.Unmarshal(data, &ingredients)
-
 	return ingredients
 }
 
-// AddIngredients adds an ingredient to the inventory
-func (h *HouseInventory) AddIngredients(ingredientsToAdd ...cookme.PerishableIngredient) {
-	newIngredients := append(h.Ingredients(), ingredientsToAdd...)
-	h.boltBucket.Put(asJSON(newIngredients))
+// IngredientsContext lists all the ingredients in the house as part of the work in ctx, or why they couldn't be read.
+// Batches which have expired are swept into the waste log first, so only what can still be cooked with is listed
+func (h *HouseInventory) IngredientsContext(ctx context.Context) (cookme.PerishableIngredients, error) {
+	now := time.Now()
+	ingredients, err := h.IngredientsWithExpired(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ingredients.Expired(now)) == 0 {
+		return ingredients, nil
+	}
+
+	wasted, fresh, err := h.sweep(ctx, now)
+
+	if err != nil {
+		logging.Error("problem sweeping expired ingredients into the waste log", "err", err)
+		return unexpired(ingredients, now), nil
+	}
+
+	if len(wasted) > 0 {
+		logging.Info("moved expired batches to the waste log", "batches", len(wasted))
+	}
+
+	return fresh, nil
+}
+
+// IngredientsWithExpired lists every batch in the house as part of the work in ctx, including any which have expired
+// but haven't been swept into the waste log yet
+func (h *HouseInventory) IngredientsWithExpired(ctx context.Context) (cookme.PerishableIngredients, error) {
+	data, err := h.boltBucket.GetContext(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return fromJSON(data)
+}
+
+// AddIngredients adds each ingredient to the inventory as a new batch, returning the batches with their IDs
+func (h *HouseInventory) AddIngredients(ingredientsToAdd ...cookme.PerishableIngredient) (cookme.PerishableIngredients, error) {
+	var added cookme.PerishableIngredients
+
+	for _, i := range ingredientsToAdd {
+		if i.BatchID == "" {
+			i.BatchID = cookme.RandomString()
+		}
+		if i.Quantity == 0 {
+			i.Quantity = 1
+		}
+		added = append(added, i)
+	}
+
+	err := h.update(func(ingredients cookme.PerishableIngredients) (cookme.PerishableIngredients, error) {
+		return append(ingredients, added...), nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return added, nil
+}
+
+// DeleteIngredient will attempt to remove every batch of an ingredient from the inventory
+func (h *HouseInventory) DeleteIngredient(ingredient string) error {
+	return h.update(func(ingredients cookme.PerishableIngredients) (cookme.PerishableIngredients, error) {
+		var newIngredients cookme.PerishableIngredients
+
+		for _, i := range ingredients {
+			if !strings.EqualFold(i.Name, ingredient) {
+				newIngredients = append(newIngredients, i)
+			}
+		}
+
+		return newIngredients, nil
+	})
+}
+
+// DeleteBatch removes a single batch of an ingredient from the inventory
+func (h *HouseInventory) DeleteBatch(ingredient string, batchID string) error {
+	return h.deleteFirst(ingredient, func(i cookme.PerishableIngredient) bool {
+		return i.BatchID == batchID
+	})
+}
+
+// DeleteOldest removes the batch of an ingredient that was added to the inventory first
+func (h *HouseInventory) DeleteOldest(ingredient string) error {
+	return h.deleteFirst(ingredient, func(i cookme.PerishableIngredient) bool {
+		return true
+	})
 }
 
-// DeleteIngredient will attempt to remove an ingredient from the inventory
-func (h *HouseInventory) DeleteIngredient(ingredient string) {
-	var newIngredients cookme.PerishableIngredients
+func (h *HouseInventory) deleteFirst(ingredient string, matches func(cookme.PerishableIngredient) bool) error {
+	return h.update(func(ingredients cookme.PerishableIngredients) (cookme.PerishableIngredients, error) {
+		var newIngredients cookme.PerishableIngredients
+		deleted := false
 
-	for _, i := range h.Ingredients() {
-		if i.Name != ingredient {
+		for _, i := range ingredients {
+			if !deleted && strings.EqualFold(i.Name, ingredient) && matches(i) {
+				deleted = true
+				continue
+			}
 			newIngredients = append(newIngredients, i)
 		}
+
+		if !deleted {
+			return nil, ErrBatchNotFound
+		}
+
+		return newIngredients, nil
+	})
+}
+
+// update changes the ingredients in one transaction, so changes made at the same time by other requests or processes
+// aren't lost
+func (h *HouseInventory) update(change func(cookme.PerishableIngredients) (cookme.PerishableIngredients, error)) error {
+	return h.boltBucket.Update(context.Background(), func(data []byte) ([]byte, error) {
+		ingredients, err := fromJSON(data)
+
+		if err != nil {
+			return nil, err
+		}
+
+		newIngredients, err := change(ingredients)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return asJSON(newIngredients), nil
+	})
+}
+
+func fromJSON(data []byte) (cookme.PerishableIngredients, error) {
+	var ingredients cookme.PerishableIngredients
+
+	if len(data) == 0 {
+		return ingredients, nil
+	}
+
+	if err := json.Unmarshal(data, &ingredients); err != nil {
+		return nil, fmt.Errorf("problem decoding ingredients, %v", err)
 	}
 
-	h.boltBucket.Put(asJSON(newIngredients))
+	return ingredients, nil
 }
 
 func asJSON(ingredients cookme.PerishableIngredients) []byte {
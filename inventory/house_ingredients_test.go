@@ -2,9 +2,12 @@ package inventory_test
 
 import (
 	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/bucket"
+	"github.com/quii/monolith-to-micro/household"
 	"github.com/quii/monolith-to-micro/inventory"
-	"log"
+	"github.com/quii/monolith-to-micro/inventory/inventorytest"
 	"os"
+	"sync"
 	"testing"
 	"time"
 )
@@ -15,42 +18,161 @@ func TestHouseInventory(t *testing.T) {
 	cheese := cookme.PerishableIngredient{Ingredient: cookme.Ingredient{Name: "Cheese"}, ExpirationDate: time.Now().Add(48 * time.Hour)}
 
 	t.Run("empty inventory returns no ingredients", func(t *testing.T) {
-		inv, cleanup := NewTestInventory(t)
+		inv, cleanup := inventorytest.NewInventory(t)
 		defer cleanup()
 
 		cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), nil)
 	})
 
 	t.Run("adding an ingredient means it gets returned", func(t *testing.T) {
-		inv, cleanup := NewTestInventory(t)
+		inv, cleanup := inventorytest.NewInventory(t)
 		defer cleanup()
 
-		inv.AddIngredients(milk, cheese)
+		added, _ := inv.AddIngredients(milk, cheese)
 
-		cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), cookme.PerishableIngredients{milk, cheese})
+		cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), added)
+	})
+
+	t.Run("adding the same ingredient twice stores two batches", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		added, _ := inv.AddIngredients(milk, milk)
+
+		if added[0].BatchID == added[1].BatchID {
+			t.Errorf("expected distinct batch ids but got %q twice", added[0].BatchID)
+		}
+
+		cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), added)
 	})
 
 	t.Run("deleting an ingredient means it no longer gets returned", func(t *testing.T) {
-		inv, cleanup := NewTestInventory(t)
+		inv, cleanup := inventorytest.NewInventory(t)
 		defer cleanup()
 
-		inv.AddIngredients(milk, cheese)
+		added, _ := inv.AddIngredients(milk, cheese, milk)
 		inv.DeleteIngredient(milk.Name)
 
-		cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), cookme.PerishableIngredients{cheese})
+		cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), cookme.PerishableIngredients{added[1]})
 	})
-}
 
-func NewTestInventory(t *testing.T) (inv *inventory.HouseInventory, cleanup func()) {
-	t.Helper()
-	dbFilename := cookme.RandomString() + ".db"
-	inv, err := inventory.NewHouseInventory(dbFilename)
+	t.Run("deleting a batch leaves the other batches", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		added, _ := inv.AddIngredients(milk, cheese, milk)
+
+		if err := inv.DeleteBatch(milk.Name, added[2].BatchID); err != nil {
+			t.Fatalf("unexpected error deleting batch %v", err)
+		}
 
-	if err != nil {
-		log.Fatalf("problem creating inventory %+v", err)
-	}
+		cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), added[:2])
+	})
+
+	t.Run("deleting the oldest batch removes the first one added", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
 
-	return inv, func() {
-		os.Remove(dbFilename)
-	}
+		added, _ := inv.AddIngredients(milk, cheese, milk)
+
+		if err := inv.DeleteOldest(milk.Name); err != nil {
+			t.Fatalf("unexpected error deleting oldest batch %v", err)
+		}
+
+		cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), added[1:])
+	})
+
+	t.Run("deleting ignores the case of the ingredient's name", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		added, _ := inv.AddIngredients(milk, cheese, milk, milk)
+
+		if err := inv.DeleteOldest("MILK"); err != nil {
+			t.Fatalf("unexpected error deleting oldest batch %v", err)
+		}
+
+		if err := inv.DeleteBatch("mILK", added[3].BatchID); err != nil {
+			t.Fatalf("unexpected error deleting batch %v", err)
+		}
+
+		cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), added[1:3])
+
+		inv.DeleteIngredient("milk")
+
+		cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), cookme.PerishableIngredients{added[1]})
+	})
+
+	t.Run("deleting a batch that doesnt exist is an error", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		added, _ := inv.AddIngredients(milk)
+
+		if err := inv.DeleteBatch(cheese.Name, added[0].BatchID); err != inventory.ErrBatchNotFound {
+			t.Errorf("got error %v, want %v", err, inventory.ErrBatchNotFound)
+		}
+
+		cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), added)
+	})
+
+	t.Run("households sharing a db have their own ingredients", func(t *testing.T) {
+		dbFilename := cookme.RandomString() + ".db"
+		defer os.Remove(dbFilename)
+
+		home, _ := inventory.NewHouseInventoryFor(dbFilename, "home")
+		office, _ := inventory.NewHouseInventoryFor(dbFilename, "office")
+		defaultHousehold, _ := inventory.NewHouseInventory(dbFilename)
+
+		added, _ := home.AddIngredients(milk)
+		office.AddIngredients(cheese)
+		office.DeleteIngredient(milk.Name)
+
+		cookme.AssertPerishableIngredientsEqual(t, home.Ingredients(), added)
+		cookme.AssertPerishableIngredientsEqual(t, defaultHousehold.Ingredients(), nil)
+	})
+
+	t.Run("ingredients added at the same time are all kept", func(t *testing.T) {
+		dbFilename := cookme.RandomString() + ".db"
+		defer os.Remove(dbFilename)
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				inv, _ := inventory.NewHouseInventory(dbFilename)
+
+				if _, err := inv.AddIngredients(milk); err != nil {
+					t.Errorf("problem adding ingredient %v", err)
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		inv, _ := inventory.NewHouseInventory(dbFilename)
+
+		if got := len(inv.Ingredients()); got != 20 {
+			t.Errorf("got %d batches, want 20", got)
+		}
+	})
+
+	t.Run("unreadable inventories aren't overwritten", func(t *testing.T) {
+		dbFilename := cookme.RandomString() + ".db"
+		defer os.Remove(dbFilename)
+
+		inv, _ := inventory.NewHouseInventory(dbFilename)
+		inventoryBucket, _ := bucket.NewBoltBucket(dbFilename, household.Bucket("inventory", household.Default))
+		inventoryBucket.Put([]byte("not json"))
+
+		if _, err := inv.AddIngredients(milk); err == nil {
+			t.Error("expected an error adding to an unreadable inventory")
+		}
+
+		if data, _ := inventoryBucket.Get(); string(data) != "not json" {
+			t.Errorf("expected the inventory to be left alone but got %q", data)
+		}
+	})
 }
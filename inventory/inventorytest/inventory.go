@@ -0,0 +1,31 @@
+// Package inventorytest makes house inventories for tests, on databases in temporary directories
+package inventorytest
+
+import (
+	"github.com/quii/monolith-to-micro/inventory"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// NewInventory is an empty HouseInventory on a database in a temporary directory. Cleaning up removes the directory
+func NewInventory(t testing.TB) (inv *inventory.HouseInventory, cleanup func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "inventorytest")
+
+	if err != nil {
+		t.Fatalf("problem creating temporary directory %+v", err)
+	}
+
+	inv, err = inventory.NewHouseInventory(filepath.Join(dir, "inventory.db"))
+
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("problem creating inventory %+v", err)
+	}
+
+	return inv, func() {
+		os.RemoveAll(dir)
+	}
+}
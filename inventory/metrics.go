@@ -0,0 +1,59 @@
+package inventory
+
+import (
+	"context"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/logging"
+	"github.com/quii/monolith-to-micro/metrics"
+	"time"
+)
+
+// ExpiringWindow is how soon batches have to expire to count as expiring in the metrics
+const ExpiringWindow = 24 * time.Hour
+
+// RegisterMetrics adds gauges of the batches in each household's inventory, how many are expiring within ExpiringWindow
+// and how many have expired, read from dbFilename when scraped
+func RegisterMetrics(r *metrics.Registry, dbFilename string, households func() []string) {
+	collect := func(count func(ingredients cookme.PerishableIngredients, now time.Time) int) func() []metrics.Sample {
+		return func() []metrics.Sample {
+			var samples []metrics.Sample
+			now := time.Now()
+
+			for _, id := range households() {
+				inv, err := NewHouseInventoryFor(dbFilename, id)
+
+				if err != nil {
+					logging.Error("problem opening inventory for metrics", "household", id, "err", err)
+					continue
+				}
+
+				// scraping shouldn't sweep, so read expired batches too rather than moving them to the waste log
+				ingredients, err := inv.IngredientsWithExpired(context.Background())
+
+				if err != nil {
+					logging.Error("problem reading inventory for metrics", "household", id, "err", err)
+					continue
+				}
+
+				samples = append(samples, metrics.Sample{LabelValues: []string{id}, Value: float64(count(ingredients, now))})
+			}
+
+			return samples
+		}
+	}
+
+	r.NewGaugeFunc("cookme_inventory_batches", "batches of ingredients in the inventory", []string{"household"},
+		collect(func(ingredients cookme.PerishableIngredients, now time.Time) int {
+			return len(ingredients)
+		}))
+
+	r.NewGaugeFunc("cookme_inventory_expiring_batches", "batches of ingredients which haven't expired but will within 24h", []string{"household"},
+		collect(func(ingredients cookme.PerishableIngredients, now time.Time) int {
+			return len(ingredients.ExpiringWithin(now, ExpiringWindow))
+		}))
+
+	r.NewGaugeFunc("cookme_inventory_expired_batches", "batches of ingredients which have expired", []string{"household"},
+		collect(func(ingredients cookme.PerishableIngredients, now time.Time) int {
+			return len(ingredients.Expired(now))
+		}))
+}
@@ -0,0 +1,61 @@
+package inventory_test
+
+import (
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/inventory"
+	"github.com/quii/monolith-to-micro/metrics"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	dbFilename := cookme.RandomString() + ".db"
+	defer os.Remove(dbFilename)
+
+	now := time.Now()
+	home, _ := inventory.NewHouseInventoryFor(dbFilename, "home")
+	home.AddIngredients(
+		cookme.Ingredient{Name: "Milk"}.ExpiresAt(now.Add(12*time.Hour)),
+		cookme.Ingredient{Name: "Cheese"}.ExpiresAt(now.Add(72*time.Hour)),
+		cookme.Ingredient{Name: "Bread"}.ExpiresAt(now.Add(-time.Hour)),
+	)
+
+	registry := metrics.NewRegistry()
+	inventory.RegisterMetrics(registry, dbFilename, func() []string { return []string{"home", "office"} })
+
+	scraped := scrape(t, registry)
+
+	for _, want := range []string{
+		`cookme_inventory_batches{household="home"} 3`,
+		`cookme_inventory_batches{household="office"} 0`,
+		`cookme_inventory_expiring_batches{household="home"} 1`,
+		`cookme_inventory_expired_batches{household="home"} 1`,
+	} {
+		if !strings.Contains(scraped, want) {
+			t.Errorf("expected %q in\n%s", want, scraped)
+		}
+	}
+
+	// the bolt stores record their transactions to the default registry
+	boltScraped := scrape(t, metrics.Default)
+
+	for _, want := range []string{
+		`cookme_bolt_transaction_duration_seconds_count{bucket="inventory",op="read"}`,
+		`cookme_bolt_transaction_bytes_count{bucket="inventory",op="write"}`,
+	} {
+		if !strings.Contains(boltScraped, want) {
+			t.Errorf("expected %q in\n%s", want, boltScraped)
+		}
+	}
+}
+
+func scrape(t *testing.T, registry *metrics.Registry) string {
+	t.Helper()
+	res := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return res.Body.String()
+}
@@ -0,0 +1,199 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/bucket"
+	"github.com/quii/monolith-to-micro/logging"
+	"sort"
+	"time"
+)
+
+// ReasonExpired is recorded against ingredients which were thrown away because they went past their expiration date
+const ReasonExpired = "expired"
+
+const uncategorised = "uncategorised"
+
+// WastedIngredient is a batch of an ingredient that was thrown away
+type WastedIngredient struct {
+	cookme.PerishableIngredient
+	WastedAt time.Time
+	Reason   string
+}
+
+// WasteLog is a history of everything that was thrown away
+type WasteLog []WastedIngredient
+
+// Sweep moves every batch that has expired by now out of the inventory and into the waste log, returning what was moved.
+// Both are changed in one transaction so a batch can't end up in both or neither
+func (h *HouseInventory) Sweep(now time.Time) WasteLog {
+	wasted, _, err := h.sweep(context.Background(), now)
+
+	if err != nil {
+		logging.Error("problem sweeping expired ingredients into the waste log", "err", err)
+		return nil
+	}
+
+	return wasted
+}
+
+// sweep is Sweep as part of the work in ctx, also returning the batches left in the inventory
+func (h *HouseInventory) sweep(ctx context.Context, now time.Time) (wasted WasteLog, fresh cookme.PerishableIngredients, err error) {
+	err = bucket.UpdateTogether(ctx, func(data [][]byte) ([][]byte, error) {
+		wasted, fresh = nil, nil
+		ingredients, err := fromJSON(data[0])
+
+		if err != nil {
+			return nil, err
+		}
+
+		fresh = unexpired(ingredients, now)
+
+		for _, i := range ingredients.Expired(now) {
+			if i.Quantity == 0 {
+				i.Quantity = 1
+			}
+
+			wasted = append(wasted, WastedIngredient{PerishableIngredient: i, WastedAt: now, Reason: ReasonExpired})
+		}
+
+		if len(wasted) == 0 {
+			return nil, nil
+		}
+
+		var waste WasteLog
+
+		if len(data[1]) > 0 {
+			if err := json.Unmarshal(data[1], &waste); err != nil {
+				return nil, fmt.Errorf("problem decoding waste log, %v", err)
+			}
+		}
+
+		return [][]byte{asJSON(fresh), wasteAsJSON(append(waste, wasted...))}, nil
+	}, h.boltBucket, h.wasteBucket)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return wasted, fresh, nil
+}
+
+func unexpired(ingredients cookme.PerishableIngredients, now time.Time) cookme.PerishableIngredients {
+	var fresh cookme.PerishableIngredients
+
+	for _, i := range ingredients {
+		if !i.HasExpired(now) {
+			fresh = append(fresh, i)
+		}
+	}
+
+	return fresh
+}
+
+// Waste lists everything that has been thrown away
+func (h *HouseInventory) Waste() WasteLog {
+	var waste WasteLog
+
+	data, err := h.wasteBucket.Get()
+
+	if err != nil {
+		logging.Error("problem getting waste data", "err", err)
+		return nil
+	}
+
+	json.Unmarshal(data, &waste)
+
+	return waste
+}
+
+// WasteTotal is how much of something was wasted
+type WasteTotal struct {
+	Name     string
+	Quantity int
+	Batches  int
+}
+
+// WastePeriod summarises what was wasted in a month, by ingredient and by category, most wasted first
+type WastePeriod struct {
+	Month       time.Time
+	Ingredients []WasteTotal
+	Categories  []WasteTotal
+}
+
+// WasteReport is a month by month summary of a WasteLog, oldest first
+type WasteReport []WastePeriod
+
+// NewWasteReport summarises the waste log
+func NewWasteReport(waste WasteLog) WasteReport {
+	byMonth := make(map[time.Time]WasteLog)
+
+	for _, w := range waste {
+		month := time.Date(w.WastedAt.Year(), w.WastedAt.Month(), 1, 0, 0, 0, 0, w.WastedAt.Location())
+		byMonth[month] = append(byMonth[month], w)
+	}
+
+	var report WasteReport
+
+	for month, wasted := range byMonth {
+		report = append(report, WastePeriod{
+			Month: month,
+			Ingredients: totalWaste(wasted, func(w WastedIngredient) string {
+				return w.Name
+			}),
+			Categories: totalWaste(wasted, func(w WastedIngredient) string {
+				if w.Category == "" {
+					return uncategorised
+				}
+				return w.Category
+			}),
+		})
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Month.Before(report[j].Month)
+	})
+
+	return report
+}
+
+func totalWaste(waste WasteLog, key func(WastedIngredient) string) []WasteTotal {
+	totals := make(map[string]*WasteTotal)
+	var names []string
+
+	for _, w := range waste {
+		name := key(w)
+		total, exists := totals[name]
+
+		if !exists {
+			total = &WasteTotal{Name: name}
+			totals[name] = total
+			names = append(names, name)
+		}
+
+		total.Quantity += w.Quantity
+		total.Batches++
+	}
+
+	var result []WasteTotal
+
+	for _, name := range names {
+		result = append(result, *totals[name])
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Quantity != result[j].Quantity {
+			return result[i].Quantity > result[j].Quantity
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+func wasteAsJSON(waste WasteLog) []byte {
+	b, _ := json.Marshal(waste)
+	return b
+}
@@ -0,0 +1,149 @@
+package inventory_test
+
+import (
+	"context"
+	"github.com/google/go-cmp/cmp"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/bucket"
+	"github.com/quii/monolith-to-micro/household"
+	"github.com/quii/monolith-to-micro/inventory"
+	"github.com/quii/monolith-to-micro/inventory/inventorytest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWaste(t *testing.T) {
+
+	now := time.Now()
+
+	sourMilk := cookme.PerishableIngredient{Ingredient: cookme.Ingredient{Name: "Milk"}, ExpirationDate: now.Add(-24 * time.Hour), Quantity: 2, Category: "dairy"}
+	freshMilk := cookme.PerishableIngredient{Ingredient: cookme.Ingredient{Name: "Milk"}, ExpirationDate: now.Add(72 * time.Hour), Quantity: 1, Category: "dairy"}
+	mouldyBread := cookme.PerishableIngredient{Ingredient: cookme.Ingredient{Name: "Bread"}, ExpirationDate: now.Add(-48 * time.Hour), Quantity: 1}
+
+	t.Run("sweeping moves expired batches into the waste log", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		added, _ := inv.AddIngredients(sourMilk, freshMilk, mouldyBread)
+		swept := inv.Sweep(now)
+
+		want := inventory.WasteLog{
+			{PerishableIngredient: added[0], WastedAt: now, Reason: inventory.ReasonExpired},
+			{PerishableIngredient: added[2], WastedAt: now, Reason: inventory.ReasonExpired},
+		}
+
+		assertWasteLogEqual(t, swept, want)
+		assertWasteLogEqual(t, inv.Waste(), want)
+		cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), cookme.PerishableIngredients{added[1]})
+	})
+
+	t.Run("sweeping again doesnt record the same waste twice", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		inv.AddIngredients(sourMilk, freshMilk)
+		inv.Sweep(now)
+
+		if swept := inv.Sweep(now); swept != nil {
+			t.Errorf("expected nothing to be swept but got %+v", swept)
+		}
+
+		if len(inv.Waste()) != 1 {
+			t.Errorf("expected 1 wasted batch but got %+v", inv.Waste())
+		}
+	})
+
+	t.Run("sweeping leaves the inventory alone when the waste log can't be written", func(t *testing.T) {
+		dbFilename := cookme.RandomString() + ".db"
+		defer os.Remove(dbFilename)
+
+		inv, _ := inventory.NewHouseInventory(dbFilename)
+		added, _ := inv.AddIngredients(sourMilk, freshMilk)
+
+		wasteBucket, _ := bucket.NewBoltBucket(dbFilename, household.Bucket("waste", household.Default))
+		wasteBucket.Put([]byte("not json"))
+
+		if swept := inv.Sweep(now); len(swept) != 0 {
+			t.Errorf("expected nothing to be swept but got %+v", swept)
+		}
+
+		all, _ := inv.IngredientsWithExpired(context.Background())
+		cookme.AssertPerishableIngredientsEqual(t, all, added)
+
+		t.Run("but still doesn't list expired batches", func(t *testing.T) {
+			cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), added[1:])
+		})
+	})
+
+	t.Run("listing the ingredients sweeps expired batches into the waste log first", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		added, _ := inv.AddIngredients(sourMilk, freshMilk)
+
+		cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), cookme.PerishableIngredients{added[1]})
+
+		waste := inv.Waste()
+
+		if len(waste) != 1 || waste[0].BatchID != added[0].BatchID || waste[0].Reason != inventory.ReasonExpired {
+			t.Errorf("expected the sour milk to be wasted but got %+v", waste)
+		}
+	})
+
+	t.Run("listing with the expired batches leaves them in the inventory", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		added, _ := inv.AddIngredients(sourMilk, freshMilk)
+		all, err := inv.IngredientsWithExpired(context.Background())
+
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		cookme.AssertPerishableIngredientsEqual(t, all, added)
+
+		if len(inv.Waste()) != 0 {
+			t.Errorf("expected nothing to be wasted but got %+v", inv.Waste())
+		}
+	})
+
+	t.Run("reports waste by month, ingredient and category", func(t *testing.T) {
+		january := time.Date(2019, time.January, 10, 0, 0, 0, 0, time.UTC)
+		february := time.Date(2019, time.February, 3, 0, 0, 0, 0, time.UTC)
+
+		waste := inventory.WasteLog{
+			{PerishableIngredient: freshMilk, WastedAt: february, Reason: inventory.ReasonExpired},
+			{PerishableIngredient: sourMilk, WastedAt: january, Reason: inventory.ReasonExpired},
+			{PerishableIngredient: mouldyBread, WastedAt: january, Reason: inventory.ReasonExpired},
+			{PerishableIngredient: sourMilk, WastedAt: january, Reason: inventory.ReasonExpired},
+		}
+
+		got := inventory.NewWasteReport(waste)
+
+		want := inventory.WasteReport{
+			{
+				Month:       time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+				Ingredients: []inventory.WasteTotal{{Name: "Milk", Quantity: 4, Batches: 2}, {Name: "Bread", Quantity: 1, Batches: 1}},
+				Categories:  []inventory.WasteTotal{{Name: "dairy", Quantity: 4, Batches: 2}, {Name: "uncategorised", Quantity: 1, Batches: 1}},
+			},
+			{
+				Month:       time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC),
+				Ingredients: []inventory.WasteTotal{{Name: "Milk", Quantity: 1, Batches: 1}},
+				Categories:  []inventory.WasteTotal{{Name: "dairy", Quantity: 1, Batches: 1}},
+			},
+		}
+
+		if !cmp.Equal(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+}
+
+func assertWasteLogEqual(t *testing.T, got, want inventory.WasteLog) {
+	t.Helper()
+	if !cmp.Equal(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,172 @@
+package logging
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"time"
+)
+
+// UnaryServerInterceptor logs every call with its method, duration, status code and request ID. The request ID is
+// taken from the call's metadata, or made up, and added to the context for the handler. A nil logger logs to Default
+func UnaryServerInterceptor(logger *Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, id := incomingRequestID(ctx)
+		start := time.Now()
+
+		res, err := handler(ctx, req)
+
+		logCall(logger, "handled call", info.FullMethod, id, start, err, serverLevel)
+		return res, err
+	}
+}
+
+// StreamServerInterceptor logs every stream like UnaryServerInterceptor logs calls, once the stream has finished
+func StreamServerInterceptor(logger *Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, id := incomingRequestID(stream.Context())
+		start := time.Now()
+
+		err := handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+
+		logCall(logger, "handled stream", info.FullMethod, id, start, err, serverLevel)
+		return err
+	}
+}
+
+// UnaryClientInterceptor passes on the request ID from ctx, or a new one, and logs every call with its method,
+// duration, status code and request ID. Successful calls are only logged at the Debug level
+func UnaryClientInterceptor(logger *Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, id := outgoingRequestID(ctx)
+		start := time.Now()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		logCall(logger, "made call", method, id, start, err, clientLevel)
+		return err
+	}
+}
+
+// StreamClientInterceptor passes on the request ID like UnaryClientInterceptor and logs opening the stream
+func StreamClientInterceptor(logger *Logger) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, id := outgoingRequestID(ctx)
+		start := time.Now()
+
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+
+		logCall(logger, "opened stream", method, id, start, err, clientLevel)
+		return stream, err
+	}
+}
+
+// Handler logs every HTTP request with its method, path, status, duration and request ID, which is taken from the
+// X-Request-ID header, or made up, and added to the request's context
+func Handler(logger *Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDKey)
+
+		if id == "" {
+			id = NewRequestID()
+		}
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		recorder.Header().Set(RequestIDKey, id)
+
+		next.ServeHTTP(recorder, r.WithContext(WithRequestID(r.Context(), id)))
+
+		level := LevelInfo
+		if recorder.status >= http.StatusInternalServerError {
+			level = LevelError
+		}
+
+		orDefault(logger).Log(level, "handled request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.status,
+			"duration", time.Since(start),
+			"request_id", id,
+		)
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func incomingRequestID(ctx context.Context) (context.Context, string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	id := NewRequestID()
+
+	if values := md.Get(RequestIDKey); len(values) > 0 && values[0] != "" {
+		id = values[0]
+	}
+
+	return WithRequestID(ctx, id), id
+}
+
+func outgoingRequestID(ctx context.Context) (context.Context, string) {
+	id := RequestID(ctx)
+
+	if id == "" {
+		id = NewRequestID()
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, RequestIDKey, id), id
+}
+
+func logCall(logger *Logger, msg, method, id string, start time.Time, err error, level func(codes.Code) Level) {
+	code := status.Code(err)
+	keyValues := []interface{}{"method", method, "duration", time.Since(start), "code", code, "request_id", id}
+
+	if err != nil {
+		keyValues = append(keyValues, "err", status.Convert(err).Message())
+	}
+
+	orDefault(logger).Log(level(code), msg, keyValues...)
+}
+
+// serverLevel logs calls the server failed as errors
+func serverLevel(code codes.Code) Level {
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unimplemented:
+		return LevelError
+	default:
+		return LevelInfo
+	}
+}
+
+// clientLevel keeps successful calls quiet
+func clientLevel(code codes.Code) Level {
+	if code == codes.OK {
+		return LevelDebug
+	}
+	return LevelWarn
+}
+
+func orDefault(logger *Logger) *Logger {
+	if logger == nil {
+		return Default()
+	}
+	return logger
+}
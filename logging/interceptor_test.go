@@ -0,0 +1,113 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/quii/monolith-to-micro/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInterceptors(t *testing.T) {
+
+	t.Run("the client passes its request id on to the server which logs it", func(t *testing.T) {
+		var clientLog, serverLog bytes.Buffer
+		client := logging.UnaryClientInterceptor(logging.New(&clientLog, logging.LevelDebug, logging.JSON))
+		server := logging.UnaryServerInterceptor(logging.New(&serverLog, logging.LevelDebug, logging.JSON))
+
+		var handledID string
+
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			handledID = logging.RequestID(ctx)
+			return nil, status.Error(codes.NotFound, "no such recipe")
+		}
+
+		// the invoker stands in for the network, handing the outgoing metadata to the server
+		invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			_, err := server(metadata.NewIncomingContext(ctx, md), req, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+			return err
+		}
+
+		ctx := logging.WithRequestID(context.Background(), "abc123")
+		err := client(ctx, "/RecipeService/DeleteRecipe", nil, nil, nil, invoker)
+
+		if status.Code(err) != codes.NotFound {
+			t.Fatalf("expected the handler's error but got %v", err)
+		}
+
+		if handledID != "abc123" {
+			t.Errorf("got request id %q in the handler, want %q", handledID, "abc123")
+		}
+
+		for name, line := range map[string]string{"client": clientLog.String(), "server": serverLog.String()} {
+			fields := decodeLine(t, line)
+
+			if fields["request_id"] != "abc123" || fields["method"] != "/RecipeService/DeleteRecipe" || fields["code"] != "NotFound" || fields["duration"] == nil {
+				t.Errorf("%s logged %v", name, fields)
+			}
+		}
+	})
+
+	t.Run("the server makes up a request id when there isn't one", func(t *testing.T) {
+		var serverLog bytes.Buffer
+		server := logging.UnaryServerInterceptor(logging.New(&serverLog, logging.LevelInfo, logging.JSON))
+
+		var handledID string
+
+		server(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/RecipeService/GetRecipes"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			handledID = logging.RequestID(ctx)
+			return nil, nil
+		})
+
+		if handledID == "" || decodeLine(t, serverLog.String())["request_id"] != handledID {
+			t.Errorf("expected the made up request id %q to be logged but got %q", handledID, serverLog.String())
+		}
+	})
+
+	t.Run("successful client calls are only logged when debugging", func(t *testing.T) {
+		var clientLog bytes.Buffer
+		client := logging.UnaryClientInterceptor(logging.New(&clientLog, logging.LevelInfo, logging.Text))
+
+		client(context.Background(), "/RecipeService/GetRecipes", nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return nil
+		})
+
+		if clientLog.Len() != 0 {
+			t.Errorf("expected nothing logged but got %q", clientLog.String())
+		}
+	})
+}
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+
+	handler := logging.Handler(logging.New(&buf, logging.LevelInfo, logging.Text), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if logging.RequestID(r.Context()) != "abc123" {
+			t.Errorf("expected the request id in the context")
+		}
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/recipes", nil)
+	req.Header.Set(logging.RequestIDKey, "abc123")
+	res := httptest.NewRecorder()
+
+	handler.ServeHTTP(res, req)
+
+	if got := res.Header().Get(logging.RequestIDKey); got != "abc123" {
+		t.Errorf("got request id header %q, want %q", got, "abc123")
+	}
+
+	for _, want := range []string{"method=GET", "path=/recipes", "status=418", "request_id=abc123"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %q in %q", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is how important a log message is, messages below a Logger's level are dropped
+type Level int
+
+const (
+	// LevelDebug is for detail only wanted when tracking down a problem
+	LevelDebug Level = iota
+	// LevelInfo is for what's happening normally
+	LevelInfo
+	// LevelWarn is for problems which were recovered from
+	LevelWarn
+	// LevelError is for problems which weren't
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel turns debug, info, warn or error into a Level
+func ParseLevel(s string) (Level, error) {
+	for i, name := range levelNames {
+		if strings.ToLower(s) == name {
+			return Level(i), nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q, expect one of %s", s, strings.Join(levelNames, ", "))
+}
+
+// Format is how log messages are written
+type Format string
+
+const (
+	// Text writes each message as a line of key=value pairs
+	Text Format = "text"
+	// JSON writes each message as a JSON object on its own line
+	JSON Format = "json"
+)
+
+// ParseFormat turns text or json into a Format
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case Text, JSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown log format %q, expect text or json", s)
+	}
+}
+
+// Logger writes levelled messages with fields, given as alternating keys and values
+type Logger struct {
+	out    io.Writer
+	mu     *sync.Mutex
+	level  Level
+	format Format
+	fields []interface{}
+	now    func() time.Time
+}
+
+// New creates a Logger writing messages at level or above to out
+func New(out io.Writer, level Level, format Format) *Logger {
+	return &Logger{out: out, mu: &sync.Mutex{}, level: level, format: format, now: time.Now}
+}
+
+// With returns a Logger adding the fields to every message
+func (l *Logger) With(keyValues ...interface{}) *Logger {
+	child := *l
+	child.fields = append(append([]interface{}{}, l.fields...), keyValues...)
+	return &child
+}
+
+// Enabled says whether messages at level are written
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.level
+}
+
+// Debug logs msg at the Debug level
+func (l *Logger) Debug(msg string, keyValues ...interface{}) {
+	l.Log(LevelDebug, msg, keyValues...)
+}
+
+// Info logs msg at the Info level
+func (l *Logger) Info(msg string, keyValues ...interface{}) {
+	l.Log(LevelInfo, msg, keyValues...)
+}
+
+// Warn logs msg at the Warn level
+func (l *Logger) Warn(msg string, keyValues ...interface{}) {
+	l.Log(LevelWarn, msg, keyValues...)
+}
+
+// Error logs msg at the Error level
+func (l *Logger) Error(msg string, keyValues ...interface{}) {
+	l.Log(LevelError, msg, keyValues...)
+}
+
+// Fatal logs msg at the Error level then exits
+func (l *Logger) Fatal(msg string, keyValues ...interface{}) {
+	l.Log(LevelError, msg, keyValues...)
+	os.Exit(1)
+}
+
+// Log writes msg with the Logger's fields and keyValues if level is enabled
+func (l *Logger) Log(level Level, msg string, keyValues ...interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	fields := append([]interface{}{"time", l.now().UTC().Format(time.RFC3339Nano), "level", level.String(), "msg", msg}, l.fields...)
+	fields = append(fields, keyValues...)
+
+	if len(fields)%2 != 0 {
+		fields = append(fields, "(missing)")
+	}
+
+	var line []byte
+
+	if l.format == JSON {
+		line = encodeJSON(fields)
+	} else {
+		line = encodeText(fields)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Write(line)
+}
+
+func encodeText(fields []interface{}) []byte {
+	var buf bytes.Buffer
+
+	for i := 0; i < len(fields); i += 2 {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(quoteText(fmt.Sprint(fields[i])))
+		buf.WriteByte('=')
+		buf.WriteString(quoteText(fmt.Sprint(value(fields[i+1]))))
+	}
+
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+func quoteText(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
+
+func encodeJSON(fields []interface{}) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	for i := 0; i < len(fields); i += 2 {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, _ := json.Marshal(fmt.Sprint(fields[i]))
+		val, err := json.Marshal(value(fields[i+1]))
+
+		if err != nil {
+			val, _ = json.Marshal(fmt.Sprint(fields[i+1]))
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// value is how a field is written, errors, durations and anything else with a String method as their text
+func value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return v
+	}
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultLogger = New(os.Stderr, LevelInfo, Text)
+)
+
+// Default is the Logger used by the package level functions, it writes text at the Info level to stderr until SetDefault
+// is called
+func Default() *Logger {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultLogger
+}
+
+// SetDefault changes the Logger used by the package level functions
+func SetDefault(logger *Logger) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultLogger = logger
+}
+
+// Debug logs msg at the Debug level to the Default logger
+func Debug(msg string, keyValues ...interface{}) {
+	Default().Log(LevelDebug, msg, keyValues...)
+}
+
+// Info logs msg at the Info level to the Default logger
+func Info(msg string, keyValues ...interface{}) {
+	Default().Log(LevelInfo, msg, keyValues...)
+}
+
+// Warn logs msg at the Warn level to the Default logger
+func Warn(msg string, keyValues ...interface{}) {
+	Default().Log(LevelWarn, msg, keyValues...)
+}
+
+// Error logs msg at the Error level to the Default logger
+func Error(msg string, keyValues ...interface{}) {
+	Default().Log(LevelError, msg, keyValues...)
+}
+
+// Fatal logs msg at the Error level to the Default logger then exits
+func Fatal(msg string, keyValues ...interface{}) {
+	Default().Fatal(msg, keyValues...)
+}
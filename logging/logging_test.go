@@ -0,0 +1,104 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/quii/monolith-to-micro/logging"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger(t *testing.T) {
+
+	t.Run("writes text as key=value pairs, quoting values with spaces", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := logging.New(&buf, logging.LevelInfo, logging.Text)
+
+		logger.Info("made call", "method", "/RecipeService/GetRecipes", "err", errors.New("not found"), "duration", 2*time.Second)
+
+		line := buf.String()
+
+		for _, want := range []string{"level=info", `msg="made call"`, "method=/RecipeService/GetRecipes", `err="not found"`, "duration=2s"} {
+			if !strings.Contains(line, want) {
+				t.Errorf("expected %q in %q", want, line)
+			}
+		}
+
+		if !strings.HasPrefix(line, "time=") || !strings.HasSuffix(line, "\n") {
+			t.Errorf("expected one line starting with the time but got %q", line)
+		}
+	})
+
+	t.Run("writes json as an object per line", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := logging.New(&buf, logging.LevelInfo, logging.JSON)
+
+		logger.With("request_id", "abc").Warn("slow", "attempts", 3)
+
+		got := decodeLine(t, buf.String())
+		delete(got, "time")
+
+		want := map[string]interface{}{"level": "warn", "msg": "slow", "request_id": "abc", "attempts": float64(3)}
+
+		if !cmp.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("drops messages below the level", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := logging.New(&buf, logging.LevelWarn, logging.Text)
+
+		logger.Debug("detail")
+		logger.Info("normal")
+		logger.Error("broken")
+
+		if lines := strings.Count(buf.String(), "\n"); lines != 1 || !strings.Contains(buf.String(), "msg=broken") {
+			t.Errorf("expected only the error but got %q", buf.String())
+		}
+	})
+
+	t.Run("With doesn't change the parent", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := logging.New(&buf, logging.LevelInfo, logging.Text)
+
+		logger.With("household", "home")
+		logger.Info("hello")
+
+		if strings.Contains(buf.String(), "household") {
+			t.Errorf("expected no household field but got %q", buf.String())
+		}
+	})
+}
+
+func TestParse(t *testing.T) {
+	if level, err := logging.ParseLevel("DEBUG"); err != nil || level != logging.LevelDebug {
+		t.Errorf("got %v %v, want %v", level, err, logging.LevelDebug)
+	}
+
+	if _, err := logging.ParseLevel("loud"); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+
+	if format, err := logging.ParseFormat("json"); err != nil || format != logging.JSON {
+		t.Errorf("got %v %v, want %v", format, err, logging.JSON)
+	}
+
+	if _, err := logging.ParseFormat("xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func decodeLine(t *testing.T, line string) map[string]interface{} {
+	t.Helper()
+	var fields map[string]interface{}
+
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		t.Fatalf("problem decoding %q, %v", line, err)
+	}
+
+	return fields
+}
@@ -0,0 +1,38 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// RequestIDKey is the gRPC metadata, and HTTP header, carrying the ID of the request a call is part of
+const RequestIDKey = "x-request-id"
+
+type requestIDKey struct{}
+
+// NewRequestID returns a random ID for a request
+func NewRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithRequestID returns a context carrying the request ID, calls made with it pass the ID on
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "" if there isn't one
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// FromContext returns the Default logger, adding the request ID if ctx has one
+func FromContext(ctx context.Context) *Logger {
+	if id := RequestID(ctx); id != "" {
+		return Default().With("request_id", id)
+	}
+	return Default()
+}
@@ -0,0 +1,93 @@
+package cookme
+
+import "strings"
+
+// Matcher finds which recipes can be cooked. It prepares the recipes once so each query only has to look at every
+// ingredient in the inventory and every recipe once, rather than comparing every pair of ingredients
+type Matcher struct {
+	recipes     Recipes
+	ingredients map[string]int
+	required    []bitset
+}
+
+// NewMatcher prepares recipes for matching
+func NewMatcher(recipes Recipes) *Matcher {
+	m := &Matcher{
+		recipes:     recipes,
+		ingredients: make(map[string]int),
+		required:    make([]bitset, len(recipes)),
+	}
+
+	for i, recipe := range recipes {
+		for _, ingredient := range recipe.Ingredients {
+			key := normalise(ingredient.Name)
+			id, exists := m.ingredients[key]
+
+			if !exists {
+				id = len(m.ingredients)
+				m.ingredients[key] = id
+			}
+
+			m.required[i] = m.required[i].set(id)
+		}
+	}
+
+	return m
+}
+
+// Find returns the recipes which can be cooked with the ingredients, in the order the recipes were given
+func (m *Matcher) Find(ingredients PerishableIngredients) (foundRecipes Recipes) {
+	available := make(bitset, bitsetWords(len(m.ingredients)))
+
+	for _, ingredient := range ingredients {
+		if id, exists := m.ingredients[normalise(ingredient.Name)]; exists {
+			available = available.set(id)
+		}
+	}
+
+	for i, required := range m.required {
+		if required.subsetOf(available) {
+			foundRecipes = append(foundRecipes, m.recipes[i])
+		}
+	}
+
+	return
+}
+
+func normalise(name string) string {
+	return strings.ToLower(name)
+}
+
+// bitset is a set of small integers, one bit each
+type bitset []uint64
+
+func bitsetWords(size int) int {
+	return (size + 63) / 64
+}
+
+func (b bitset) set(i int) bitset {
+	word := i / 64
+
+	for len(b) <= word {
+		b = append(b, 0)
+	}
+
+	b[word] |= 1 << uint(i%64)
+	return b
+}
+
+func (b bitset) subsetOf(other bitset) bool {
+	for i, word := range b {
+		if i >= len(other) {
+			if word != 0 {
+				return false
+			}
+			continue
+		}
+
+		if word&^other[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
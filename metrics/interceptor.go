@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RPCMetrics counts the calls a server handles and how long they take, per method and status code
+type RPCMetrics struct {
+	requests *Counter
+	duration *Histogram
+}
+
+// NewRPCMetrics registers cookme_rpc_requests_total and cookme_rpc_duration_seconds
+func NewRPCMetrics(r *Registry) *RPCMetrics {
+	return &RPCMetrics{
+		requests: r.NewCounter("cookme_rpc_requests_total", "gRPC calls handled", "method", "code"),
+		duration: r.NewHistogram("cookme_rpc_duration_seconds", "time taken to handle gRPC calls, streams until they finish", DurationBuckets, "method", "code"),
+	}
+}
+
+// UnaryServerInterceptor records every call
+func (m *RPCMetrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		res, err := handler(ctx, req)
+		m.record(info.FullMethod, err, start)
+		return res, err
+	}
+}
+
+// StreamServerInterceptor records every stream once it has finished
+func (m *RPCMetrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, stream)
+		m.record(info.FullMethod, err, start)
+		return err
+	}
+}
+
+func (m *RPCMetrics) record(method string, err error, start time.Time) {
+	code := status.Code(err).String()
+	m.requests.Inc(method, code)
+	m.duration.Observe(time.Since(start).Seconds(), method, code)
+}
+
+// HTTPMetrics counts the requests a server handles and how long they take, per method and status
+type HTTPMetrics struct {
+	requests *Counter
+	duration *Histogram
+}
+
+// NewHTTPMetrics registers cookme_http_requests_total and cookme_http_request_duration_seconds
+func NewHTTPMetrics(r *Registry) *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: r.NewCounter("cookme_http_requests_total", "HTTP requests handled", "method", "code"),
+		duration: r.NewHistogram("cookme_http_request_duration_seconds", "time taken to handle HTTP requests", DurationBuckets, "method", "code"),
+	}
+}
+
+// Handler records every request to next
+func (m *HTTPMetrics) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		code := strconv.Itoa(recorder.status)
+		m.requests.Inc(r.Method, code)
+		m.duration.Observe(time.Since(start).Seconds(), r.Method, code)
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,148 @@
+package metrics_test
+
+import (
+	"context"
+	"github.com/quii/monolith-to-micro/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+
+	t.Run("writes counters with their labels", func(t *testing.T) {
+		registry := metrics.NewRegistry()
+		calls := registry.NewCounter("calls_total", "calls made", "method")
+
+		calls.Inc("b")
+		calls.Add(2, "a")
+		calls.Inc("a")
+
+		assertScraped(t, registry, `# HELP calls_total calls made
+# TYPE calls_total counter
+calls_total{method="a"} 3
+calls_total{method="b"} 1
+`)
+	})
+
+	t.Run("writes histograms as cumulative buckets with a sum and count", func(t *testing.T) {
+		registry := metrics.NewRegistry()
+		sizes := registry.NewHistogram("size_bytes", "sizes", []float64{10, 100})
+
+		sizes.Observe(5)
+		sizes.Observe(50)
+		sizes.Observe(500)
+
+		assertScraped(t, registry, `# HELP size_bytes sizes
+# TYPE size_bytes histogram
+size_bytes_bucket{le="10"} 1
+size_bytes_bucket{le="100"} 2
+size_bytes_bucket{le="+Inf"} 3
+size_bytes_sum 555
+size_bytes_count 3
+`)
+	})
+
+	t.Run("collects gauges when scraped and escapes label values", func(t *testing.T) {
+		registry := metrics.NewRegistry()
+		value := 1.5
+
+		registry.NewGaugeFunc("temperature", "how hot", []string{"room"}, func() []metrics.Sample {
+			return []metrics.Sample{{LabelValues: []string{`the "big" kitchen`}, Value: value}}
+		})
+
+		value = 2.5
+
+		assertScraped(t, registry, `# HELP temperature how hot
+# TYPE temperature gauge
+temperature{room="the \"big\" kitchen"} 2.5
+`)
+	})
+
+	t.Run("registering a name twice panics", func(t *testing.T) {
+		registry := metrics.NewRegistry()
+		registry.NewCounter("calls_total", "calls made")
+
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic")
+			}
+		}()
+
+		registry.NewCounter("calls_total", "calls made again")
+	})
+}
+
+func TestRPCMetrics(t *testing.T) {
+	registry := metrics.NewRegistry()
+	rpcMetrics := metrics.NewRPCMetrics(registry)
+	interceptor := rpcMetrics.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/RecipeService/RateRecipe"}
+
+	interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+
+	interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "no such recipe")
+	})
+
+	scraped := scrape(t, registry)
+
+	for _, want := range []string{
+		`cookme_rpc_requests_total{method="/RecipeService/RateRecipe",code="OK"} 1`,
+		`cookme_rpc_requests_total{method="/RecipeService/RateRecipe",code="NotFound"} 1`,
+		`cookme_rpc_duration_seconds_count{method="/RecipeService/RateRecipe",code="NotFound"} 1`,
+	} {
+		assertContains(t, scraped, want)
+	}
+}
+
+func TestHTTPMetrics(t *testing.T) {
+	registry := metrics.NewRegistry()
+
+	handler := metrics.NewHTTPMetrics(registry).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/recipes", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/recipes", nil))
+
+	scraped := scrape(t, registry)
+
+	assertContains(t, scraped, `cookme_http_requests_total{method="GET",code="200"} 1`)
+	assertContains(t, scraped, `cookme_http_requests_total{method="POST",code="201"} 1`)
+	assertContains(t, scraped, `cookme_http_request_duration_seconds_count{method="GET",code="200"} 1`)
+}
+
+func scrape(t *testing.T, registry *metrics.Registry) string {
+	t.Helper()
+	res := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if contentType := res.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("got content type %q, want the prometheus text format", contentType)
+	}
+
+	return res.Body.String()
+}
+
+func assertScraped(t *testing.T, registry *metrics.Registry, want string) {
+	t.Helper()
+	if got := scrape(t, registry); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func assertContains(t *testing.T, scraped, want string) {
+	t.Helper()
+	if !strings.Contains(scraped, want) {
+		t.Errorf("expected %q in\n%s", want, scraped)
+	}
+}
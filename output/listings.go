@@ -0,0 +1,116 @@
+package output
+
+import (
+	"fmt"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/auth"
+	"github.com/quii/monolith-to-micro/inventory"
+	"github.com/quii/monolith-to-micro/recipe"
+	"strings"
+	"time"
+)
+
+// Recipes lists recipes, in the order given
+func Recipes(recipes cookme.Recipes) Listing {
+	listing := Listing{Fields: []string{"name", "ingredients", "ingredient_count", "rating", "favourite"}}
+
+	for _, r := range recipes {
+		ingredients := ingredientNames(r.Ingredients)
+		listing.Rows = append(listing.Rows, []interface{}{r.Name, ingredients, len(ingredients), r.Rating, r.Favourite})
+		listing.Text = append(listing.Text, fmt.Sprintf(" - %s (%d ingredients: %s)", r.Name, len(ingredients), strings.Join(ingredients, ", ")))
+	}
+
+	return listing
+}
+
+// Ingredients lists batches of ingredients, saying which had expired by now
+func Ingredients(ingredients cookme.PerishableIngredients, now time.Time) Listing {
+	listing := Listing{Fields: []string{"name", "batch_id", "quantity", "category", "expires", "expired"}}
+
+	for _, i := range ingredients {
+		listing.Rows = append(listing.Rows, []interface{}{i.Name, i.BatchID, i.Quantity, i.Category, i.ExpirationDate, i.HasExpired(now)})
+		listing.Text = append(listing.Text, fmt.Sprintf(" - [%s] %d x %s", i.BatchID, i.Quantity, i.StringAt(now)))
+	}
+
+	return listing
+}
+
+// Matches lists recipes using some of the wanted ingredients
+func Matches(matches []recipe.Match, wanted int) Listing {
+	listing := Listing{Fields: []string{"name", "ingredients", "matching_ingredients"}}
+
+	for _, m := range matches {
+		listing.Rows = append(listing.Rows, []interface{}{m.Recipe.Name, ingredientNames(m.Recipe.Ingredients), m.MatchingIngredients})
+		listing.Text = append(listing.Text, fmt.Sprintf(" - %s uses %d of %d", m.Recipe.Name, m.MatchingIngredients, wanted))
+	}
+
+	return listing
+}
+
+// History lists what has been cooked
+func History(history cookme.CookingHistory) Listing {
+	listing := Listing{Fields: []string{"name", "cooked_at"}}
+
+	for _, c := range history {
+		listing.Rows = append(listing.Rows, []interface{}{c.Name, c.CookedAt})
+		listing.Text = append(listing.Text, fmt.Sprintf(" - %s", c))
+	}
+
+	return listing
+}
+
+// Wasted lists batches that were thrown away
+func Wasted(waste inventory.WasteLog) Listing {
+	listing := Listing{Fields: []string{"name", "batch_id", "quantity", "category", "reason", "wasted_at"}}
+
+	for _, w := range waste {
+		listing.Rows = append(listing.Rows, []interface{}{w.Name, w.BatchID, w.Quantity, w.Category, w.Reason, w.WastedAt})
+		listing.Text = append(listing.Text, fmt.Sprintf("Threw away %d %s, it %s", w.Quantity, w.Name, w.Reason))
+	}
+
+	return listing
+}
+
+// WasteReport lists the waste totals of each month, by ingredient then by category
+func WasteReport(report inventory.WasteReport) Listing {
+	listing := Listing{Fields: []string{"month", "by", "name", "quantity", "batches"}}
+
+	for _, period := range report {
+		month := period.Month.Format("2006-01")
+		listing.Text = append(listing.Text, fmt.Sprintf("Waste in %s", period.Month.Format("January 2006")))
+
+		for _, group := range []struct {
+			by     string
+			totals []inventory.WasteTotal
+		}{{"ingredient", period.Ingredients}, {"category", period.Categories}} {
+			listing.Text = append(listing.Text, fmt.Sprintf("  by %s", group.by))
+
+			for _, total := range group.totals {
+				listing.Rows = append(listing.Rows, []interface{}{month, group.by, total.Name, total.Quantity, total.Batches})
+				listing.Text = append(listing.Text, fmt.Sprintf("   - %s: %d (%d batches)", total.Name, total.Quantity, total.Batches))
+			}
+		}
+	}
+
+	return listing
+}
+
+// Tokens lists tokens without their secrets
+func Tokens(tokens []auth.Token) Listing {
+	listing := Listing{Fields: []string{"id", "household", "role", "created_at"}}
+
+	for _, t := range tokens {
+		listing.Rows = append(listing.Rows, []interface{}{t.ID, t.Household, string(t.Role), t.CreatedAt})
+		listing.Text = append(listing.Text, fmt.Sprintf(" - %s %s for %s, created %s", t.ID, t.Role, t.Household, t.CreatedAt.Format("2 January 2006")))
+	}
+
+	return listing
+}
+
+func ingredientNames(ingredients cookme.Ingredients) []string {
+	names := []string{}
+	for _, i := range ingredients {
+		names = append(names, i.Name)
+	}
+	return names
+}
@@ -0,0 +1,203 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Format is how a Listing is written
+type Format string
+
+// The formats a Listing can be written in
+const (
+	Text  Format = "text"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+	Table Format = "table"
+	CSV   Format = "csv"
+)
+
+// Formats lists every Format
+var Formats = []Format{Text, JSON, YAML, Table, CSV}
+
+// ParseFormat returns the Format called name
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output %q, expect text, json, yaml, table or csv", name)
+}
+
+// Listing is the result of a command. Rows hold values in the same order as Fields, which are the stable schema used in
+// every format but text. Text is the lines written for people
+type Listing struct {
+	Fields []string
+	Rows   [][]interface{}
+	Text   []string
+}
+
+// Write writes the listing to out in format. Values can be strings, numbers, bools, times or string slices
+func Write(out io.Writer, format Format, listing Listing) error {
+	switch format {
+	case Text:
+		for _, line := range listing.Text {
+			if _, err := fmt.Fprintln(out, line); err != nil {
+				return err
+			}
+		}
+		return nil
+	case JSON:
+		return writeJSON(out, listing)
+	case YAML:
+		return writeYAML(out, listing)
+	case Table:
+		return writeTable(out, listing)
+	case CSV:
+		return writeCSV(out, listing)
+	default:
+		return fmt.Errorf("unknown output %q", format)
+	}
+}
+
+// object is a row which keeps its fields in order when marshalled to JSON
+type object struct {
+	fields []string
+	values []interface{}
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+
+	for i, field := range o.fields {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+
+		key, _ := json.Marshal(field)
+		value, err := json.Marshal(normalise(o.values[i]))
+
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(key)
+		buf.WriteString(":")
+		buf.Write(value)
+	}
+
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+func writeJSON(out io.Writer, listing Listing) error {
+	objects := []object{}
+
+	for _, row := range listing.Rows {
+		objects = append(objects, object{fields: listing.Fields, values: row})
+	}
+
+	b, err := json.MarshalIndent(objects, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "%s\n", b)
+	return err
+}
+
+// writeYAML writes a sequence of mappings. Strings are double quoted as JSON, which is also valid YAML, so they never
+// change type or need escaping rules of their own
+func writeYAML(out io.Writer, listing Listing) error {
+	if len(listing.Rows) == 0 {
+		_, err := fmt.Fprintln(out, "[]")
+		return err
+	}
+
+	for _, row := range listing.Rows {
+		for i, field := range listing.Fields {
+			indent := "  "
+			if i == 0 {
+				indent = "- "
+			}
+
+			value, err := json.Marshal(normalise(row[i]))
+
+			if err != nil {
+				return err
+			}
+
+			if _, err := fmt.Fprintf(out, "%s%s: %s\n", indent, field, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func writeTable(out io.Writer, listing Listing) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(listing.Fields, "\t")))
+
+	for _, row := range listing.Rows {
+		var cells []string
+		for _, v := range row {
+			cells = append(cells, cell(v, ", "))
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+
+	return w.Flush()
+}
+
+func writeCSV(out io.Writer, listing Listing) error {
+	w := csv.NewWriter(out)
+	w.Write(listing.Fields)
+
+	for _, row := range listing.Rows {
+		var cells []string
+		for _, v := range row {
+			cells = append(cells, cell(v, ";"))
+		}
+		w.Write(cells)
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// cell formats a value for table or csv, joining lists with sep
+func cell(v interface{}, sep string) string {
+	switch v := normalise(v).(type) {
+	case []string:
+		return strings.Join(v, sep)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// normalise makes times RFC 3339 strings and nil lists empty so they're written the same way in every format
+func normalise(v interface{}) interface{} {
+	switch v := v.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case []string:
+		if v == nil {
+			return []string{}
+		}
+		return v
+	default:
+		return v
+	}
+}
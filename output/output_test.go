@@ -0,0 +1,104 @@
+package output_test
+
+import (
+	"bytes"
+	"flag"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/auth"
+	"github.com/quii/monolith-to-micro/inventory"
+	"github.com/quii/monolith-to-micro/output"
+	"github.com/quii/monolith-to-micro/recipe"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the current output")
+
+func TestListings(t *testing.T) {
+
+	now := time.Date(2019, time.March, 10, 18, 0, 0, 0, time.UTC)
+
+	milk := cookme.Ingredient{Name: "Milk"}
+	cheese := cookme.Ingredient{Name: "Cheese"}
+	pasta := cookme.Ingredient{Name: "Pasta"}
+
+	macAndCheese := cookme.NewRecipe("Mac and cheese", pasta, cheese)
+	macAndCheese.Rating = 4
+	macAndCheese.Favourite = true
+	cheesyMilk := cookme.NewRecipe(`Cheesy "milk", with a comma`, milk, cheese)
+
+	spoiltMilk := inventory.WastedIngredient{
+		PerishableIngredient: cookme.PerishableIngredient{Ingredient: milk, ExpirationDate: now.Add(-48 * time.Hour), BatchID: "a1", Quantity: 2, Category: "dairy"},
+		WastedAt:             now.Add(-24 * time.Hour),
+		Reason:               inventory.ReasonExpired,
+	}
+
+	listings := map[string]output.Listing{
+		"recipes":    output.Recipes(cookme.Recipes{macAndCheese, cheesyMilk}),
+		"no-recipes": output.Recipes(nil),
+		"ingredients": output.Ingredients(cookme.PerishableIngredients{
+			{Ingredient: milk, ExpirationDate: now.Add(-24 * time.Hour), BatchID: "a1", Quantity: 2, Category: "dairy"},
+			{Ingredient: pasta, ExpirationDate: now.Add(30 * 24 * time.Hour), BatchID: "b2", Quantity: 1},
+		}, now),
+		"matches": output.Matches([]recipe.Match{
+			{Recipe: macAndCheese, MatchingIngredients: 2},
+			{Recipe: cheesyMilk, MatchingIngredients: 1},
+		}, 2),
+		"history": output.History(cookme.CookingHistory{
+			{Name: macAndCheese.Name, CookedAt: now.Add(-72 * time.Hour)},
+		}),
+		"wasted":       output.Wasted(inventory.WasteLog{spoiltMilk}),
+		"waste-report": output.WasteReport(inventory.NewWasteReport(inventory.WasteLog{spoiltMilk})),
+		"tokens": output.Tokens([]auth.Token{
+			{ID: "1a2b3c4d", Household: "home", Role: auth.Editor, Hash: "secret hash", CreatedAt: now},
+		}),
+	}
+
+	for name, listing := range listings {
+		for _, format := range output.Formats {
+			t.Run(name+" as "+string(format), func(t *testing.T) {
+				var got bytes.Buffer
+
+				if err := output.Write(&got, format, listing); err != nil {
+					t.Fatalf("problem writing %v", err)
+				}
+
+				assertGolden(t, filepath.Join("testdata", name+"."+string(format)), got.Bytes())
+			})
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, format := range output.Formats {
+		if got, err := output.ParseFormat(string(format)); got != format || err != nil {
+			t.Errorf("got %q %v, want %q", got, err, format)
+		}
+	}
+
+	if _, err := output.ParseFormat("xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func assertGolden(t *testing.T, filename string, got []byte) {
+	t.Helper()
+
+	if *update {
+		if err := ioutil.WriteFile(filename, got, 0644); err != nil {
+			t.Fatalf("problem updating golden file %v", err)
+		}
+	}
+
+	want, err := ioutil.ReadFile(filename)
+
+	if err != nil {
+		t.Fatalf("problem reading golden file, run the tests with -update to create it %v", err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("output doesn't match %s\ngot:\n%s\nwant:\n%s", filename, got, want)
+	}
+}
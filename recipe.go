@@ -5,10 +5,15 @@ import (
 	"testing"
 )
 
+// MaxRating is the highest rating a recipe can be given, the lowest is 1
+const MaxRating = 5
+
 // Recipe represents a recipe with its required ingredients
 type Recipe struct {
 	Name        string
 	Ingredients Ingredients
+	Rating      int
+	Favourite   bool
 }
 
 // NewRecipe is creates a recipe with some ingredients
@@ -23,6 +28,16 @@ func (r Recipe) String() string {
 // Recipes is a slice of recipes
 type Recipes []Recipe
 
+// Favourites returns just the recipes that have been starred
+func (recipes Recipes) Favourites() (favourites Recipes) {
+	for _, r := range recipes {
+		if r.Favourite {
+			favourites = append(favourites, r)
+		}
+	}
+	return
+}
+
 // AssertRecipesEqual is a test helper for checking if 2 lists of recipes are the same
 func AssertRecipesEqual(t *testing.T, got, want Recipes) {
 	t.Helper()
@@ -0,0 +1,121 @@
+package recipe_test
+
+import (
+	"context"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/auth"
+	"github.com/quii/monolith-to-micro/auth/authtest"
+	"github.com/quii/monolith-to-micro/recipe"
+	"github.com/quii/monolith-to-micro/recipe/recipetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"testing"
+	"time"
+)
+
+func TestClientWithTokens(t *testing.T) {
+
+	macAndCheese := cookme.NewRecipe("Mac and cheese", cookme.Ingredient{Name: "Pasta"}, cookme.Ingredient{Name: "Cheese"})
+
+	tokens, cleanupTokens := authtest.NewTokens(t)
+	defer cleanupTokens()
+
+	readerSecret, _, _ := tokens.Create("home", auth.Reader, time.Now())
+	editorSecret, _, _ := tokens.Create("home", auth.Editor, time.Now())
+	revokedSecret, revoked, _ := tokens.Create("home", auth.Editor, time.Now())
+	tokens.Revoke(revoked.ID)
+
+	read := func(client *recipe.Client) error {
+		_, err := client.RecipesContext(context.Background())
+		return err
+	}
+
+	edit := func(client *recipe.Client) error {
+		return client.AddContext(context.Background(), macAndCheese.Name, []string{"Pasta", "Cheese"})
+	}
+
+	watch := func(client *recipe.Client) error {
+		events, err := client.WatchContext(context.Background())
+
+		if err != nil {
+			return err
+		}
+
+		// errors from opening a stream only show up once it is read from
+		_, err = events.Recv()
+		return err
+	}
+
+	cases := []struct {
+		name   string
+		secret string
+		call   func(*recipe.Client) error
+		want   codes.Code
+	}{
+		{name: "reading without a token", call: read, want: codes.Unauthenticated},
+		{name: "watching without a token", call: watch, want: codes.Unauthenticated},
+		{name: "reading with an unknown token", secret: "guess", call: read, want: codes.Unauthenticated},
+		{name: "reading with a revoked token", secret: revokedSecret, call: read, want: codes.Unauthenticated},
+		{name: "reading as a reader", secret: readerSecret, call: read, want: codes.OK},
+		{name: "editing as a reader", secret: readerSecret, call: edit, want: codes.PermissionDenied},
+		{name: "editing as an editor", secret: editorSecret, call: edit, want: codes.OK},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			book, cleanupBook := NewTestRecipeBook(t)
+			defer cleanupBook()
+
+			client, cleanup := newTestAuthClient(t, book, tokens, c.secret)
+			defer cleanup()
+
+			assertStatusCode(t, c.call(client), c.want)
+		})
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	server := grpc.NewServer()
+	recipe.RegisterRecipeServiceServer(server, &recipe.Book{})
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	reflection.Register(server)
+
+	for service, info := range server.GetServiceInfo() {
+		for _, method := range info.Methods {
+			if _, ok := recipe.Policy["/"+service+"/"+method.Name]; !ok {
+				t.Errorf("%s/%s isn't in the policy so nobody can call it", service, method.Name)
+			}
+		}
+	}
+
+	for _, method := range server.GetServiceInfo()["RecipeService"].Methods {
+		if recipe.Policy["/RecipeService/"+method.Name] == auth.Anyone {
+			t.Errorf("%s can be called without a token", method.Name)
+		}
+	}
+}
+
+func newTestAuthClient(t *testing.T, book *recipe.Book, verifier auth.Verifier, secret string) (client *recipe.Client, cleanup func()) {
+	t.Helper()
+
+	server, stop := recipetest.NewServer(t, book,
+		grpc.UnaryInterceptor(auth.UnaryServerInterceptor(verifier, recipe.Policy)),
+		grpc.StreamInterceptor(auth.StreamServerInterceptor(verifier, recipe.Policy)),
+	)
+
+	var options []recipe.ClientOption
+
+	if secret != "" {
+		options = append(options, recipe.WithToken(secret))
+	}
+
+	client, closeClient := server.NewClient(t, options...)
+
+	return client, func() {
+		closeClient()
+		stop()
+	}
+}
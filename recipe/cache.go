@@ -0,0 +1,310 @@
+package recipe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/bucket"
+	"github.com/quii/monolith-to-micro/household"
+	"github.com/quii/monolith-to-micro/logging"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// Service is the recipe service as seen by a client, *Client implements it
+type Service interface {
+	RecipesContext(ctx context.Context) (cookme.Recipes, error)
+	AddContext(ctx context.Context, name string, ingredients []string) error
+	DeleteContext(ctx context.Context, name string) error
+	RateContext(ctx context.Context, name string, rating int) error
+	SetFavouriteContext(ctx context.Context, name string, favourite bool) error
+}
+
+var _ Service = &Client{}
+
+// CachedClient is a RecipeRepo which remembers the last recipes fetched from a Service so it can carry on working when the
+// service is down. Changes made while the service is down are queued and replayed once it comes back
+type CachedClient struct {
+	service     Service
+	cacheBucket *bucket.BoltBucket
+	queueBucket *bucket.BoltBucket
+}
+
+const (
+	cacheBucketName = "recipe-cache"
+	queueBucketName = "recipe-queue"
+)
+
+type cachedRecipes struct {
+	Recipes   cookme.Recipes
+	FetchedAt time.Time
+}
+
+type change struct {
+	Op          string
+	Name        string
+	Ingredients []string `json:",omitempty"`
+	Rating      int      `json:",omitempty"`
+	Favourite   bool     `json:",omitempty"`
+}
+
+const (
+	addOp       = "add"
+	deleteOp    = "delete"
+	rateOp      = "rate"
+	favouriteOp = "favourite"
+)
+
+// NewCachedClient wraps service with a cache and queue stored in a bolt db at dbFilename
+func NewCachedClient(service Service, dbFilename string) (*CachedClient, error) {
+	return NewCachedClientFor(service, dbFilename, household.Default)
+}
+
+// NewCachedClientFor wraps service with a cache and queue for a household, so switching households doesn't mix up
+// their recipes or replay changes to the wrong one
+func NewCachedClientFor(service Service, dbFilename string, householdID string) (*CachedClient, error) {
+	cacheBucket, err := bucket.NewBoltBucket(dbFilename, household.Bucket(cacheBucketName, householdID))
+
+	if err != nil {
+		return nil, err
+	}
+
+	queueBucket, err := bucket.NewBoltBucket(dbFilename, household.Bucket(queueBucketName, householdID))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &CachedClient{service: service, cacheBucket: cacheBucket, queueBucket: queueBucket}, nil
+}
+
+// Recipes returns the recipes from the service, or the last recipes it returned if it is down
+func (c *CachedClient) Recipes() cookme.Recipes {
+	return c.RecipesFor(context.Background())
+}
+
+// RecipesFor is Recipes as part of the work in ctx, returning none if they can't be fetched or found in the cache. Use
+// RecipesContext to find out why
+func (c *CachedClient) RecipesFor(ctx context.Context) cookme.Recipes {
+	recipes, err := c.RecipesContext(ctx)
+
+	if err != nil {
+		logging.Error("problem getting recipes", "err", err)
+	}
+
+	return recipes
+}
+
+// RecipesContext returns the recipes from the service, or the ones saved from before if it can't be reached
+func (c *CachedClient) RecipesContext(ctx context.Context) (cookme.Recipes, error) {
+	if _, err := c.replay(ctx); err != nil {
+		logging.Error("problem replaying queued recipe changes", "err", err)
+	}
+
+	recipes, err := c.service.RecipesContext(ctx)
+
+	if err == nil {
+		c.cacheBucket.PutContext(ctx, asCacheJSON(cachedRecipes{Recipes: recipes, FetchedAt: time.Now()}))
+		return recipes, nil
+	}
+
+	if !canUseCache(err) {
+		return nil, err
+	}
+
+	cached := c.cached()
+
+	if cached.FetchedAt.IsZero() {
+		return nil, fmt.Errorf("recipe service is unavailable and there are no recipes saved from before, %v", err)
+	}
+
+	logging.Warn("recipe service is unavailable, showing saved recipes which may be stale", "fetched_at", cached.FetchedAt.Format(time.RFC1123))
+
+	return cached.Recipes, nil
+}
+
+// Add lets you add a recipe, queueing it if the service is down
+func (c *CachedClient) Add(name string, ingredients []string) error {
+	return c.send(change{Op: addOp, Name: name, Ingredients: ingredients})
+}
+
+// Delete removes a recipe, queueing it if the service is down
+func (c *CachedClient) Delete(name string) error {
+	return c.send(change{Op: deleteOp, Name: name})
+}
+
+// Rate gives a recipe a rating from 1 to 5, queueing it if the service is down
+func (c *CachedClient) Rate(name string, rating int) error {
+	return c.send(change{Op: rateOp, Name: name, Rating: rating})
+}
+
+// SetFavourite stars or unstars a recipe, queueing it if the service is down
+func (c *CachedClient) SetFavourite(name string, favourite bool) error {
+	return c.send(change{Op: favouriteOp, Name: name, Favourite: favourite})
+}
+
+// Pending returns how many changes are waiting for the service to come back
+func (c *CachedClient) Pending() int {
+	data, err := c.queueBucket.Get()
+
+	if err != nil {
+		logging.Error("problem reading queued recipe changes", "err", err)
+		return 0
+	}
+
+	queue, err := queueFromJSON(data)
+
+	if err != nil {
+		logging.Error("problem reading queued recipe changes", "err", err)
+	}
+
+	return len(queue)
+}
+
+// send makes the change, returning nil once it's queued if the service is down. Otherwise the service's error is
+// returned, e.g. InvalidArgument for a bad rating
+func (c *CachedClient) send(ch change) error {
+	ctx := context.Background()
+	sent, err := c.replay(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if !sent {
+		return c.enqueue(ctx, ch)
+	}
+
+	err = c.apply(ctx, ch)
+
+	if isUnavailable(err) {
+		return c.enqueue(ctx, ch)
+	}
+
+	return err
+}
+
+// replay sends queued changes to the service in order, returning false if the service is still down. The queue is
+// taken and cleared in one transaction before sending, so processes sharing it can't both send the same change. Changes
+// that couldn't be sent go back in front of any queued in the meantime
+func (c *CachedClient) replay(ctx context.Context) (bool, error) {
+	queue, err := c.take(ctx)
+
+	if err != nil {
+		return false, err
+	}
+
+	for len(queue) > 0 {
+		err := c.apply(ctx, queue[0])
+
+		if isUnavailable(err) {
+			return false, c.changeQueue(ctx, func(queued []change) []change {
+				return append(queue, queued...)
+			})
+		}
+
+		if err != nil {
+			logging.Warn("dropping queued change", "recipe", queue[0].Name, "err", err)
+		}
+
+		queue = queue[1:]
+	}
+
+	return true, nil
+}
+
+func (c *CachedClient) apply(ctx context.Context, ch change) error {
+	switch ch.Op {
+	case addOp:
+		return c.service.AddContext(ctx, ch.Name, ch.Ingredients)
+	case deleteOp:
+		return c.service.DeleteContext(ctx, ch.Name)
+	case rateOp:
+		return c.service.RateContext(ctx, ch.Name, ch.Rating)
+	case favouriteOp:
+		return c.service.SetFavouriteContext(ctx, ch.Name, ch.Favourite)
+	default:
+		return fmt.Errorf("unknown change %q", ch.Op)
+	}
+}
+
+func (c *CachedClient) enqueue(ctx context.Context, ch change) error {
+	logging.Warn("recipe service is unavailable, the change will be sent when it is back", "recipe", ch.Name)
+
+	return c.changeQueue(ctx, func(queue []change) []change {
+		return append(queue, ch)
+	})
+}
+
+// take empties the queue, returning what was in it
+func (c *CachedClient) take(ctx context.Context) (taken []change, err error) {
+	err = c.queueBucket.Update(ctx, func(data []byte) ([]byte, error) {
+		queue, err := queueFromJSON(data)
+
+		if err != nil || len(queue) == 0 {
+			return nil, err
+		}
+
+		taken = queue
+		return asQueueJSON(nil), nil
+	})
+
+	return taken, err
+}
+
+func (c *CachedClient) changeQueue(ctx context.Context, edit func(queue []change) []change) error {
+	return c.queueBucket.Update(ctx, func(data []byte) ([]byte, error) {
+		queue, err := queueFromJSON(data)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return asQueueJSON(edit(queue)), nil
+	})
+}
+
+func queueFromJSON(data []byte) ([]change, error) {
+	var queue []change
+
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	if err := json.Unmarshal(data, &queue); err != nil {
+		return nil, fmt.Errorf("problem decoding queued recipe changes, %v", err)
+	}
+
+	return queue, nil
+}
+
+func (c *CachedClient) cached() cachedRecipes {
+	var cached cachedRecipes
+	data, _ := c.cacheBucket.Get()
+	json.Unmarshal(data, &cached)
+	return cached
+}
+
+// isUnavailable is true when the service couldn't be reached, so a change certainly wasn't made and can be queued. A
+// change which timed out may have been made, so queueing it could make it twice
+func isUnavailable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// canUseCache is true when the recipes couldn't be fetched in time, so the saved ones are better than nothing
+func canUseCache(err error) bool {
+	code := status.Code(err)
+	return code == codes.Unavailable || code == codes.DeadlineExceeded
+}
+
+func asCacheJSON(cached cachedRecipes) []byte {
+	b, _ := json.Marshal(cached)
+	return b
+}
+
+func asQueueJSON(queue []change) []byte {
+	b, _ := json.Marshal(queue)
+	return b
+}
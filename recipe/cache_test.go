@@ -0,0 +1,264 @@
+package recipe_test
+
+import (
+	"context"
+	"github.com/google/go-cmp/cmp"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/recipe"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestCachedClient(t *testing.T) {
+
+	macAndCheese := cookme.NewRecipe("Mac and cheese", cookme.Ingredient{Name: "Pasta"}, cookme.Ingredient{Name: "Cheese"})
+	cheesyMilk := cookme.NewRecipe("Cheesy milk", cookme.Ingredient{Name: "Milk"}, cookme.Ingredient{Name: "Cheese"})
+
+	t.Run("returns recipes from the service when it is up", func(t *testing.T) {
+		service := &stubService{recipes: cookme.Recipes{macAndCheese}}
+		client, cleanup := NewTestCachedClient(t, service)
+		defer cleanup()
+
+		AssertRecipesEqual(t, client.Recipes(), cookme.Recipes{macAndCheese})
+	})
+
+	t.Run("returns the last recipes fetched when the service is down", func(t *testing.T) {
+		service := &stubService{recipes: cookme.Recipes{macAndCheese}}
+		client, cleanup := NewTestCachedClient(t, service)
+		defer cleanup()
+
+		client.Recipes()
+		service.recipes = cookme.Recipes{cheesyMilk}
+		service.down = true
+
+		AssertRecipesEqual(t, client.Recipes(), cookme.Recipes{macAndCheese})
+	})
+
+	t.Run("returns why it couldn't get the recipes when the service refuses", func(t *testing.T) {
+		service := &stubService{recipes: cookme.Recipes{macAndCheese}}
+		client, cleanup := NewTestCachedClient(t, service)
+		defer cleanup()
+
+		client.Recipes()
+		service.forbidden = true
+
+		_, err := client.RecipesContext(context.Background())
+		assertStatusCode(t, err, codes.PermissionDenied)
+
+		AssertRecipesEqual(t, client.Recipes(), nil)
+	})
+
+	t.Run("returns an error when the service is down and nothing was saved before", func(t *testing.T) {
+		client, cleanup := NewTestCachedClient(t, &stubService{down: true})
+		defer cleanup()
+
+		if _, err := client.RecipesContext(context.Background()); err == nil {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("queues changes while the service is down and replays them in order when it is back", func(t *testing.T) {
+		service := &stubService{down: true}
+		client, cleanup := NewTestCachedClient(t, service)
+		defer cleanup()
+
+		client.Add(macAndCheese.Name, []string{"Pasta", "Cheese"})
+		client.Rate(macAndCheese.Name, 5)
+		client.Delete(cheesyMilk.Name)
+
+		if client.Pending() != 3 {
+			t.Errorf("expected 3 pending changes but got %d", client.Pending())
+		}
+
+		service.down = false
+		client.SetFavourite(macAndCheese.Name, true)
+
+		want := []string{"add Mac and cheese", "rate Mac and cheese", "delete Cheesy milk", "favourite Mac and cheese"}
+
+		if !cmp.Equal(service.calls, want) {
+			t.Errorf("got calls %v, want %v", service.calls, want)
+		}
+
+		if client.Pending() != 0 {
+			t.Errorf("expected no pending changes but got %d", client.Pending())
+		}
+	})
+
+	t.Run("drops queued changes which the service rejects", func(t *testing.T) {
+		service := &stubService{down: true}
+		client, cleanup := NewTestCachedClient(t, service)
+		defer cleanup()
+
+		client.Rate(macAndCheese.Name, 5)
+
+		service.down = false
+		service.reject = true
+		client.Recipes()
+
+		if client.Pending() != 0 {
+			t.Errorf("expected no pending changes but got %d", client.Pending())
+		}
+	})
+
+	t.Run("doesn't queue changes which timed out as they may have been made", func(t *testing.T) {
+		service := &stubService{slow: true}
+		client, cleanup := NewTestCachedClient(t, service)
+		defer cleanup()
+
+		client.Add(macAndCheese.Name, []string{"Pasta", "Cheese"})
+
+		service.slow = false
+		client.Recipes()
+
+		if want := []string{"add Mac and cheese"}; !cmp.Equal(service.calls, want) {
+			t.Errorf("got calls %v, want %v", service.calls, want)
+		}
+	})
+
+	t.Run("returns the service's error when it rejects a change, but not when the change is queued", func(t *testing.T) {
+		service := &stubService{reject: true}
+		client, cleanup := NewTestCachedClient(t, service)
+		defer cleanup()
+
+		assertStatusCode(t, client.Rate(macAndCheese.Name, 5), codes.NotFound)
+
+		service.down = true
+
+		if err := client.Rate(macAndCheese.Name, 5); err != nil {
+			t.Errorf("unexpected error queueing a change %v", err)
+		}
+	})
+
+	t.Run("clients sharing a queue don't both replay the same change", func(t *testing.T) {
+		service := &stubService{down: true}
+		client, other, cleanup := newTestCachedClients(t, service)
+		defer cleanup()
+
+		client.Add(macAndCheese.Name, []string{"Pasta", "Cheese"})
+
+		service.down = false
+		service.onCall = func() { other.Recipes() }
+		client.Recipes()
+
+		if want := []string{"add Mac and cheese"}; !cmp.Equal(service.calls, want) {
+			t.Errorf("got calls %v, want %v", service.calls, want)
+		}
+	})
+
+	t.Run("changes queued by another client during a replay are kept", func(t *testing.T) {
+		service := &stubService{down: true}
+		client, other, cleanup := newTestCachedClients(t, service)
+		defer cleanup()
+
+		client.Add(macAndCheese.Name, []string{"Pasta", "Cheese"})
+
+		service.down = false
+		service.onCall = func() {
+			service.down = true
+			other.Delete(cheesyMilk.Name)
+		}
+		client.Recipes()
+
+		service.down = false
+		client.Recipes()
+
+		if want := []string{"add Mac and cheese", "delete Cheesy milk"}; !cmp.Equal(service.calls, want) {
+			t.Errorf("got calls %v, want %v", service.calls, want)
+		}
+	})
+}
+
+type stubService struct {
+	recipes   cookme.Recipes
+	down      bool
+	reject    bool
+	slow      bool
+	forbidden bool
+	calls     []string
+
+	// onCall runs before the next change is made, e.g. to have another client act in the middle of a replay
+	onCall func()
+}
+
+func (s *stubService) RecipesContext(ctx context.Context) (cookme.Recipes, error) {
+	if s.down {
+		return nil, status.Error(codes.Unavailable, "down")
+	}
+	if s.forbidden {
+		return nil, status.Error(codes.PermissionDenied, "token revoked")
+	}
+	return s.recipes, nil
+}
+
+func (s *stubService) AddContext(ctx context.Context, name string, ingredients []string) error {
+	return s.call("add " + name)
+}
+
+func (s *stubService) DeleteContext(ctx context.Context, name string) error {
+	return s.call("delete " + name)
+}
+
+func (s *stubService) RateContext(ctx context.Context, name string, rating int) error {
+	return s.call("rate " + name)
+}
+
+func (s *stubService) SetFavouriteContext(ctx context.Context, name string, favourite bool) error {
+	return s.call("favourite " + name)
+}
+
+func (s *stubService) call(description string) error {
+	if onCall := s.onCall; onCall != nil {
+		s.onCall = nil
+		onCall()
+	}
+	if s.down {
+		return status.Error(codes.Unavailable, "down")
+	}
+	if s.reject {
+		return status.Error(codes.NotFound, "no such recipe")
+	}
+	s.calls = append(s.calls, description)
+	if s.slow {
+		return status.Error(codes.DeadlineExceeded, "made the change but took too long to say so")
+	}
+	return nil
+}
+
+// newTestCachedClients returns two clients sharing a db, as the cookme command and web UI do
+func newTestCachedClients(t *testing.T, service recipe.Service) (client, other *recipe.CachedClient, cleanup func()) {
+	t.Helper()
+	dbFilename := cookme.RandomString() + ".db"
+
+	client, err := recipe.NewCachedClient(service, dbFilename)
+
+	if err != nil {
+		t.Fatalf("problem creating db %+v", err)
+	}
+
+	other, err = recipe.NewCachedClient(service, dbFilename)
+
+	if err != nil {
+		t.Fatalf("problem creating db %+v", err)
+	}
+
+	return client, other, func() {
+		os.Remove(dbFilename)
+	}
+}
+
+func NewTestCachedClient(t *testing.T, service recipe.Service) (client *recipe.CachedClient, cleanup func()) {
+	t.Helper()
+	dbFilename := cookme.RandomString() + ".db"
+	client, err := recipe.NewCachedClient(service, dbFilename)
+
+	if err != nil {
+		log.Fatalf("problem creating db %+v", err)
+	}
+
+	return client, func() {
+		os.Remove(dbFilename)
+	}
+}
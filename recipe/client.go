@@ -2,73 +2,328 @@ package recipe
 
 import (
 	"context"
+	"crypto/tls"
 	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/auth"
+	"github.com/quii/monolith-to-micro/household"
+	"github.com/quii/monolith-to-micro/interceptor"
+	"github.com/quii/monolith-to-micro/logging"
+	"github.com/quii/monolith-to-micro/tracing"
 	"google.golang.org/grpc"
-	"log"
+	"google.golang.org/grpc/credentials"
+	"time"
 )
 
 // Client is a RecipeRepo connecting to the recipe server
 type Client struct {
-	c RecipeServiceClient
+	c       RecipeServiceClient
+	retries retryPolicy
+}
+
+// ClientOption configures how a Client talks to the recipe server
+type ClientOption func(*clientConfig)
+
+type clientConfig struct {
+	retryPolicy
+	transport   grpc.DialOption
+	logger      *logging.Logger
+	tracer      *tracing.Tracer
+	dialOptions []grpc.DialOption
+}
+
+// WithTimeout sets the deadline for each attempt of a call to the server
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetries sets how many times idempotent calls are retried while the server is unavailable, and the backoff between
+// attempts which doubles from initialBackoff up to maxBackoff
+func WithRetries(maxRetries int, initialBackoff, maxBackoff time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.maxRetries = maxRetries
+		c.initialBackoff = initialBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// WithDialOptions adds extra options used when connecting to the server
+func WithDialOptions(options ...grpc.DialOption) ClientOption {
+	return func(c *clientConfig) {
+		c.dialOptions = append(c.dialOptions, options...)
+	}
+}
+
+// WithTLS connects to the server over TLS instead of in plain text
+func WithTLS(config *tls.Config) ClientOption {
+	return func(c *clientConfig) {
+		c.transport = grpc.WithTransportCredentials(credentials.NewTLS(config))
+	}
+}
+
+// WithToken sends a token with every call, for servers requiring one
+func WithToken(secret string) ClientOption {
+	return func(c *clientConfig) {
+		c.dialOptions = append(c.dialOptions, grpc.WithPerRPCCredentials(auth.Credentials{Secret: secret}))
+	}
+}
+
+// WithHousehold uses a household's recipes rather than those of the token's household, or the default one
+func WithHousehold(id string) ClientOption {
+	return func(c *clientConfig) {
+		c.dialOptions = append(c.dialOptions, grpc.WithPerRPCCredentials(household.Credentials{ID: id}))
+	}
+}
+
+// WithLogger logs the calls to logger rather than the Default one
+func WithLogger(logger *logging.Logger) ClientOption {
+	return func(c *clientConfig) {
+		c.logger = logger
+	}
+}
+
+// WithTracer traces the calls with tracer rather than the Default one
+func WithTracer(tracer *tracing.Tracer) ClientOption {
+	return func(c *clientConfig) {
+		c.tracer = tracer
+	}
 }
 
 // NewClient creates a new client to the recipe server, make sure to call defer close()
-func NewClient(address string) (client *Client, close func() error) {
-	conn, err := grpc.Dial(address, grpc.WithInsecure())
+func NewClient(address string, options ...ClientOption) (client *Client, close func() error) {
+	config := clientConfig{
+		retryPolicy: retryPolicy{
+			timeout:        5 * time.Second,
+			maxRetries:     5,
+			initialBackoff: 100 * time.Millisecond,
+			maxBackoff:     2 * time.Second,
+		},
+		transport: grpc.WithInsecure(),
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	dialOptions := append(config.dialOptions, config.transport,
+		grpc.WithUnaryInterceptor(interceptor.ChainUnaryClient(
+			tracing.UnaryClientInterceptor(config.tracer),
+			logging.UnaryClientInterceptor(config.logger),
+			config.retryPolicy.interceptor,
+		)),
+		grpc.WithStreamInterceptor(interceptor.ChainStreamClient(
+			tracing.StreamClientInterceptor(config.tracer),
+			logging.StreamClientInterceptor(config.logger),
+		)),
+	)
+
+	conn, err := grpc.Dial(address, dialOptions...)
 
 	if err != nil {
-		log.Fatalf("could not connect to %s, %v", address, err)
+		logging.Fatal("could not connect to the recipe service", "address", address, "err", err)
 	}
 
 	recipeClient := NewRecipeServiceClient(conn)
 
-	return &Client{c: recipeClient}, conn.Close
+	return &Client{c: recipeClient, retries: config.retryPolicy}, conn.Close
 }
 
 // Recipes returns all recipes available from the server
 func (c *Client) Recipes() cookme.Recipes {
-	res, err := c.c.GetRecipes(context.Background(), &GetRecipesRequest{})
+	return c.RecipesFor(context.Background())
+}
+
+// RecipesFor returns all recipes available from the server as part of the work in ctx, or none if they can't be fetched.
+// Use RecipesContext to find out why
+func (c *Client) RecipesFor(ctx context.Context) cookme.Recipes {
+	recipes, err := c.RecipesContext(ctx)
 
 	if err != nil {
-		log.Fatalf("problem getting recipes %v", err)
+		logging.Error("problem getting recipes", "err", err)
+	}
+
+	return recipes
+}
+
+// RecipesContext returns all recipes available from the server
+func (c *Client) RecipesContext(ctx context.Context) (cookme.Recipes, error) {
+	res, err := c.c.GetRecipes(ctx, &GetRecipesRequest{})
+
+	if err != nil {
+		return nil, err
 	}
 
 	var recipes cookme.Recipes
 
 	for _, r := range res.Recipes {
-		var ingredients cookme.Ingredients
-		for _, i := range r.Ingredients {
-			ingredients = append(ingredients, cookme.Ingredient{Name: i.Name})
-		}
-		recipes = append(recipes, cookme.Recipe{
-			Name:        r.Name,
-			Ingredients: ingredients,
+		recipes = append(recipes, ConvertRecipeFromGRPC(r))
+	}
+
+	return recipes, nil
+}
+
+// SearchContext returns a page of recipes whose name or ingredients contain query. Pass the returned token back in to get
+// the next page, an empty token means there are no more pages
+func (c *Client) SearchContext(ctx context.Context, query string, order GetRecipesRequest_SortOrder, pageSize int, pageToken string) (recipes cookme.Recipes, nextPageToken string, err error) {
+	res, err := c.c.GetRecipes(ctx, &GetRecipesRequest{
+		Query:     query,
+		Sort:      order,
+		PageSize:  int32(pageSize),
+		PageToken: pageToken,
+	})
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, r := range res.Recipes {
+		recipes = append(recipes, ConvertRecipeFromGRPC(r))
+	}
+
+	return recipes, res.NextPageToken, nil
+}
+
+// RecipesUsingContext returns the recipes on the server using any of the ingredients, those using the most of them first
+func (c *Client) RecipesUsingContext(ctx context.Context, ingredients []string) ([]Match, error) {
+	res, err := c.c.FindRecipesUsing(ctx, &FindRecipesUsingRequest{Ingredients: ingredients})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Match
+
+	for _, m := range res.Matches {
+		matches = append(matches, Match{
+			Recipe:              ConvertRecipeFromGRPC(m.Recipe),
+			MatchingIngredients: int(m.MatchingIngredients),
 		})
 	}
 
-	return recipes
+	return matches, nil
 }
 
 // Add lets you add a recipe to the server
 func (c *Client) Add(name string, ingredients []string) {
+	if err := c.AddContext(context.Background(), name, ingredients); err != nil {
+		logging.Error("problem adding recipe", "recipe", name, "err", err)
+	}
+}
+
+// AddContext lets you add a recipe to the server. It is never retried as adding twice would duplicate the recipe
+func (c *Client) AddContext(ctx context.Context, name string, ingredients []string) error {
 	recipe := &Recipe{Name: name}
 
 	for _, i := range ingredients {
 		recipe.Ingredients = append(recipe.Ingredients, &Ingredient{Name: i})
 	}
 
-	_, err := c.c.AddRecipe(context.Background(), &AddRecipeRequest{Recipe: recipe})
-
-	if err != nil {
-		log.Println(err)
-	}
+	_, err := c.c.AddRecipe(ctx, &AddRecipeRequest{Recipe: recipe})
+	return err
 }
 
 // Delete removes a recipe from the server
 func (c *Client) Delete(name string) {
-	_, err := c.c.DeleteRecipe(context.Background(), &DeleteRecipeRequest{Name: name})
+	if err := c.DeleteContext(context.Background(), name); err != nil {
+		logging.Error("problem deleting recipe", "recipe", name, "err", err)
+	}
+}
+
+// DeleteContext removes a recipe from the server
+func (c *Client) DeleteContext(ctx context.Context, name string) error {
+	_, err := c.c.DeleteRecipe(ctx, &DeleteRecipeRequest{Name: name})
+	return err
+}
+
+// Rate gives a recipe on the server a rating from 1 to 5
+func (c *Client) Rate(name string, rating int) {
+	if err := c.RateContext(context.Background(), name, rating); err != nil {
+		logging.Error("problem rating recipe", "recipe", name, "err", err)
+	}
+}
+
+// RateContext gives a recipe on the server a rating from 1 to 5
+func (c *Client) RateContext(ctx context.Context, name string, rating int) error {
+	_, err := c.c.RateRecipe(ctx, &RateRecipeRequest{Name: name, Rating: int32(rating)})
+	return err
+}
+
+// SetFavourite stars or unstars a recipe on the server
+func (c *Client) SetFavourite(name string, favourite bool) {
+	if err := c.SetFavouriteContext(context.Background(), name, favourite); err != nil {
+		logging.Error("problem setting favourite", "recipe", name, "err", err)
+	}
+}
+
+// SetFavouriteContext stars or unstars a recipe on the server
+func (c *Client) SetFavouriteContext(ctx context.Context, name string, favourite bool) error {
+	_, err := c.c.SetFavourite(ctx, &SetFavouriteRequest{Name: name, Favourite: favourite})
+	return err
+}
+
+// WatchContext starts watching for changes to the recipes on the server, returning once the server is watching so no
+// changes made afterwards are missed. Receive the changes from the stream until ctx is done
+func (c *Client) WatchContext(ctx context.Context) (RecipeService_WatchRecipesClient, error) {
+	stream, err := c.c.WatchRecipes(ctx, &WatchRecipesRequest{})
 
 	if err != nil {
-		log.Println(err)
+		return nil, err
 	}
+
+	if _, err := stream.Header(); err != nil {
+		return nil, err
+	}
+
+	return stream, nil
+}
+
+// Changes signals whenever the recipes on the server change until ctx is done, when it's closed. Changes made while the
+// last signal hasn't been received are merged into it. It also signals each time watching starts, as changes may have
+// been missed before then. Watching again after it fails waits with the same backoff as retries
+func (c *Client) Changes(ctx context.Context) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+
+	signal := func() {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+
+	go func() {
+		defer close(changes)
+		failures := 0
+
+		for {
+			stream, err := c.WatchContext(ctx)
+
+			if err == nil {
+				signal()
+				failures = 0
+
+				for err == nil {
+					if _, err = stream.Recv(); err == nil {
+						signal()
+					}
+				}
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			logging.Warn("problem watching recipes, watching again", "err", err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.retries.backoff(failures)):
+			}
+
+			failures++
+		}
+	}()
+
+	return changes
 }
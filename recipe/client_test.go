@@ -0,0 +1,176 @@
+package recipe_test
+
+import (
+	"context"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/recipe"
+	"github.com/quii/monolith-to-micro/recipe/recipetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient(t *testing.T) {
+
+	macAndCheese := cookme.NewRecipe("Mac and cheese", cookme.Ingredient{Name: "Pasta"}, cookme.Ingredient{Name: "Cheese"})
+
+	retries := recipe.WithRetries(3, time.Millisecond, 5*time.Millisecond)
+
+	t.Run("retries getting recipes while the server is unavailable", func(t *testing.T) {
+		book, cleanupBook := NewTestRecipeBook(t)
+		defer cleanupBook()
+		book.Add(macAndCheese)
+
+		server := &flakyServer{failures: 2}
+		client, cleanup := newTestClient(t, book, server, retries)
+		defer cleanup()
+
+		got, err := client.RecipesContext(context.Background())
+
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		AssertRecipesEqual(t, got, cookme.Recipes{macAndCheese})
+		assertCalls(t, server, 3)
+	})
+
+	t.Run("gives up once it runs out of retries", func(t *testing.T) {
+		book, cleanupBook := NewTestRecipeBook(t)
+		defer cleanupBook()
+
+		server := &flakyServer{failures: 10}
+		client, cleanup := newTestClient(t, book, server, retries)
+		defer cleanup()
+
+		err := client.DeleteContext(context.Background(), macAndCheese.Name)
+
+		assertStatusCode(t, err, codes.Unavailable)
+		assertCalls(t, server, 4)
+	})
+
+	t.Run("doesnt retry adding recipes as that would add duplicates", func(t *testing.T) {
+		book, cleanupBook := NewTestRecipeBook(t)
+		defer cleanupBook()
+
+		server := &flakyServer{failures: 1}
+		client, cleanup := newTestClient(t, book, server, retries)
+		defer cleanup()
+
+		err := client.AddContext(context.Background(), macAndCheese.Name, []string{"Pasta", "Cheese"})
+
+		assertStatusCode(t, err, codes.Unavailable)
+		assertCalls(t, server, 1)
+		AssertRecipesEqual(t, book.Recipes(), nil)
+	})
+
+	t.Run("searches a page of recipes at a time", func(t *testing.T) {
+		book, cleanupBook := NewTestRecipeBook(t)
+		defer cleanupBook()
+
+		cheesyMilk := cookme.NewRecipe("Cheesy milk", cookme.Ingredient{Name: "Milk"}, cookme.Ingredient{Name: "Cheese"})
+		book.Add(macAndCheese)
+		book.Add(cheesyMilk)
+
+		client, cleanup := newTestClient(t, book, &flakyServer{})
+		defer cleanup()
+
+		firstPage, next, err := client.SearchContext(context.Background(), "cheese", recipe.GetRecipesRequest_NAME, 1, "")
+
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		AssertRecipesEqual(t, firstPage, cookme.Recipes{cheesyMilk})
+
+		secondPage, next, _ := client.SearchContext(context.Background(), "cheese", recipe.GetRecipesRequest_NAME, 1, next)
+
+		AssertRecipesEqual(t, secondPage, cookme.Recipes{macAndCheese})
+
+		if next != "" {
+			t.Errorf("expected no more pages but got token %q", next)
+		}
+	})
+
+	t.Run("times out calls which take too long", func(t *testing.T) {
+		book, cleanupBook := NewTestRecipeBook(t)
+		defer cleanupBook()
+
+		server := &flakyServer{delay: time.Second}
+		client, cleanup := newTestClient(t, book, server, retries, recipe.WithTimeout(10*time.Millisecond))
+		defer cleanup()
+
+		_, err := client.RecipesContext(context.Background())
+
+		assertStatusCode(t, err, codes.DeadlineExceeded)
+	})
+}
+
+// flakyServer fails the first few calls it receives as if it was still starting up, and can be made slow
+type flakyServer struct {
+	mu        sync.Mutex
+	failures  int
+	delay     time.Duration
+	calls     int
+	holdSends chan struct{}
+}
+
+func (f *flakyServer) intercept(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	f.mu.Lock()
+	f.calls++
+	fail := f.calls <= f.failures
+	f.mu.Unlock()
+
+	if fail {
+		return nil, status.Error(codes.Unavailable, "still starting up")
+	}
+
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return handler(ctx, req)
+}
+
+func (f *flakyServer) interceptStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &heldStream{ServerStream: stream, hold: f.holdSends})
+}
+
+// heldStream doesn't send any messages until hold is closed, as if the client wasn't reading them
+type heldStream struct {
+	grpc.ServerStream
+	hold chan struct{}
+}
+
+func (h *heldStream) SendMsg(m interface{}) error {
+	if h.hold != nil {
+		<-h.hold
+	}
+	return h.ServerStream.SendMsg(m)
+}
+
+func assertCalls(t *testing.T, server *flakyServer, want int) {
+	t.Helper()
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.calls != want {
+		t.Errorf("got %d calls to the server, want %d", server.calls, want)
+	}
+}
+
+func newTestClient(t *testing.T, book *recipe.Book, server *flakyServer, options ...recipe.ClientOption) (client *recipe.Client, cleanup func()) {
+	t.Helper()
+
+	bufconnServer, stop := recipetest.NewServer(t, book, grpc.UnaryInterceptor(server.intercept), grpc.StreamInterceptor(server.interceptStream))
+	client, closeClient := bufconnServer.NewClient(t, options...)
+
+	return client, func() {
+		closeClient()
+		stop()
+	}
+}
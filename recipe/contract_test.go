@@ -0,0 +1,80 @@
+package recipe_test
+
+import (
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/recipe"
+	"github.com/quii/monolith-to-micro/recipe/recipetest"
+	"testing"
+)
+
+func TestRecipeRepoContract(t *testing.T) {
+
+	t.Run("Book", cookme.RecipeRepoContract{
+		NewRepo: func(t *testing.T, recipes cookme.Recipes) (cookme.RecipeRepo, func()) {
+			book, cleanup := NewTestRecipeBook(t)
+			addRecipes(book, recipes)
+			return book, cleanup
+		},
+	}.Test)
+
+	t.Run("Client", cookme.RecipeRepoContract{
+		NewRepo: func(t *testing.T, recipes cookme.Recipes) (cookme.RecipeRepo, func()) {
+			client, cleanup := newTestBookClient(t, recipes)
+			return client, cleanup
+		},
+	}.Test)
+
+	t.Run("Client of a household in a Library", cookme.RecipeRepoContract{
+		NewRepo: func(t *testing.T, recipes cookme.Recipes) (cookme.RecipeRepo, func()) {
+			library, cleanupLibrary := NewTestLibrary(t)
+			book, err := library.Book("home")
+
+			if err != nil {
+				t.Fatalf("problem opening book %+v", err)
+			}
+
+			addRecipes(book, recipes)
+
+			server, stop := recipetest.NewServer(t, library)
+			client, closeClient := server.NewClient(t, recipe.WithHousehold("home"))
+
+			return client, func() {
+				closeClient()
+				stop()
+				cleanupLibrary()
+			}
+		},
+	}.Test)
+
+	t.Run("CachedClient", cookme.RecipeRepoContract{
+		NewRepo: func(t *testing.T, recipes cookme.Recipes) (cookme.RecipeRepo, func()) {
+			client, cleanupClient := newTestBookClient(t, recipes)
+			cachedClient, cleanupCache := NewTestCachedClient(t, client)
+
+			return cachedClient, func() {
+				cleanupCache()
+				cleanupClient()
+			}
+		},
+	}.Test)
+}
+
+func addRecipes(book *recipe.Book, recipes cookme.Recipes) {
+	for _, r := range recipes {
+		book.Add(r)
+	}
+}
+
+// newTestBookClient is a client of a book served over bufconn, which holds recipes
+func newTestBookClient(t *testing.T, recipes cookme.Recipes) (client *recipe.Client, cleanup func()) {
+	t.Helper()
+
+	server, book, stop := recipetest.NewBookServer(t)
+	addRecipes(book, recipes)
+	client, closeClient := server.NewClient(t)
+
+	return client, func() {
+		closeClient()
+		stop()
+	}
+}
@@ -0,0 +1,110 @@
+package recipe
+
+import (
+	"github.com/quii/monolith-to-micro"
+	"sort"
+	"strings"
+)
+
+// Match is a recipe which uses some of the ingredients asked for
+type Match struct {
+	Recipe              cookme.Recipe
+	MatchingIngredients int
+}
+
+// ingredientIndex maps each ingredient to the recipes which use it, so recipes can be found without reading the whole book
+type ingredientIndex struct {
+	recipes      map[string]cookme.Recipes
+	byIngredient map[string]map[string]bool
+}
+
+func newIngredientIndex(recipes cookme.Recipes) *ingredientIndex {
+	index := &ingredientIndex{
+		recipes:      make(map[string]cookme.Recipes),
+		byIngredient: make(map[string]map[string]bool),
+	}
+
+	for _, r := range recipes {
+		index.add(r)
+	}
+
+	return index
+}
+
+func (idx *ingredientIndex) add(r cookme.Recipe) {
+	idx.recipes[r.Name] = append(idx.recipes[r.Name], r)
+
+	for _, i := range r.Ingredients {
+		key := strings.ToLower(i.Name)
+
+		if idx.byIngredient[key] == nil {
+			idx.byIngredient[key] = make(map[string]bool)
+		}
+
+		idx.byIngredient[key][r.Name] = true
+	}
+}
+
+func (idx *ingredientIndex) remove(name string) {
+	for _, r := range idx.recipes[name] {
+		for _, i := range r.Ingredients {
+			key := strings.ToLower(i.Name)
+			delete(idx.byIngredient[key], name)
+
+			if len(idx.byIngredient[key]) == 0 {
+				delete(idx.byIngredient, key)
+			}
+		}
+	}
+
+	delete(idx.recipes, name)
+}
+
+// replace swaps the indexed recipes called name for a new version which uses the same ingredients
+func (idx *ingredientIndex) replace(name string, recipes cookme.Recipes) {
+	var named cookme.Recipes
+
+	for _, r := range recipes {
+		if r.Name == name {
+			named = append(named, r)
+		}
+	}
+
+	idx.recipes[name] = named
+}
+
+// using returns the recipes using any of the ingredients, those using the most of them first
+func (idx *ingredientIndex) using(ingredients []string) []Match {
+	counts := make(map[string]int)
+	seen := make(map[string]bool)
+
+	for _, i := range ingredients {
+		key := strings.ToLower(i)
+
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		for name := range idx.byIngredient[key] {
+			counts[name]++
+		}
+	}
+
+	var matches []Match
+
+	for name, count := range counts {
+		for _, r := range idx.recipes[name] {
+			matches = append(matches, Match{Recipe: r, MatchingIngredients: count})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].MatchingIngredients != matches[j].MatchingIngredients {
+			return matches[i].MatchingIngredients > matches[j].MatchingIngredients
+		}
+		return matches[i].Recipe.Name < matches[j].Recipe.Name
+	})
+
+	return matches
+}
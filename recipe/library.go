@@ -0,0 +1,168 @@
+package recipe
+
+import (
+	"context"
+	"github.com/quii/monolith-to-micro/auth"
+	"github.com/quii/monolith-to-micro/household"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sort"
+	"sync"
+)
+
+// Library serves the RecipeService for many households, each call goes to the Book of the household chosen by its
+// metadata or token. Any household can read the recipes of household.Public
+type Library struct {
+	dbFilename string
+
+	mu       sync.Mutex
+	books    map[string]*Book
+	stopping bool
+}
+
+// NewLibrary returns a new library whose books are backed by a bolt db at dbFilename
+func NewLibrary(dbFilename string) *Library {
+	return &Library{dbFilename: dbFilename, books: make(map[string]*Book)}
+}
+
+// Book returns the household's book, opening it the first time it is used
+func (l *Library) Book(householdID string) (*Book, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if book, exists := l.books[householdID]; exists {
+		return book, nil
+	}
+
+	book, err := NewBookFor(l.dbFilename, householdID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if l.stopping {
+		book.StopWatching()
+	}
+
+	l.books[householdID] = book
+	return book, nil
+}
+
+// StopWatching disconnects everyone watching any of the books, including books opened afterwards
+func (l *Library) StopWatching() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stopping = true
+
+	for _, book := range l.books {
+		book.StopWatching()
+	}
+}
+
+// Households lists the households whose books have been opened
+func (l *Library) Households() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var households []string
+	for id := range l.books {
+		households = append(households, id)
+	}
+
+	sort.Strings(households)
+	return households
+}
+
+func (l *Library) bookFor(ctx context.Context, readOnly bool) (*Book, error) {
+	id, err := auth.SelectHousehold(ctx, household.FromMetadata(ctx), readOnly)
+
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := l.Book(id)
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "problem opening the recipes of %s, %v", id, err)
+	}
+
+	return book, nil
+}
+
+// GetRecipes returns the household's recipes
+func (l *Library) GetRecipes(ctx context.Context, in *GetRecipesRequest) (*GetRecipesResponse, error) {
+	book, err := l.bookFor(ctx, true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return book.GetRecipes(ctx, in)
+}
+
+// AddRecipe adds a recipe to the household's book
+func (l *Library) AddRecipe(ctx context.Context, in *AddRecipeRequest) (*AddRecipeResponse, error) {
+	book, err := l.bookFor(ctx, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return book.AddRecipe(ctx, in)
+}
+
+// DeleteRecipe deletes a recipe from the household's book
+func (l *Library) DeleteRecipe(ctx context.Context, in *DeleteRecipeRequest) (*DeleteRecipeResponse, error) {
+	book, err := l.bookFor(ctx, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return book.DeleteRecipe(ctx, in)
+}
+
+// RateRecipe rates one of the household's recipes
+func (l *Library) RateRecipe(ctx context.Context, in *RateRecipeRequest) (*RateRecipeResponse, error) {
+	book, err := l.bookFor(ctx, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return book.RateRecipe(ctx, in)
+}
+
+// SetFavourite stars or unstars one of the household's recipes
+func (l *Library) SetFavourite(ctx context.Context, in *SetFavouriteRequest) (*SetFavouriteResponse, error) {
+	book, err := l.bookFor(ctx, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return book.SetFavourite(ctx, in)
+}
+
+// FindRecipesUsing returns the household's recipes using any of the ingredients
+func (l *Library) FindRecipesUsing(ctx context.Context, in *FindRecipesUsingRequest) (*FindRecipesUsingResponse, error) {
+	book, err := l.bookFor(ctx, true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return book.FindRecipesUsing(ctx, in)
+}
+
+// WatchRecipes streams changes to the household's book
+func (l *Library) WatchRecipes(in *WatchRecipesRequest, stream RecipeService_WatchRecipesServer) error {
+	book, err := l.bookFor(stream.Context(), true)
+
+	if err != nil {
+		return err
+	}
+
+	return book.WatchRecipes(in, stream)
+}
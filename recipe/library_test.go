@@ -0,0 +1,159 @@
+package recipe_test
+
+import (
+	"context"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/auth"
+	"github.com/quii/monolith-to-micro/household"
+	"github.com/quii/monolith-to-micro/metrics"
+	"github.com/quii/monolith-to-micro/recipe"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLibrary(t *testing.T) {
+
+	macAndCheese := cookme.NewRecipe("Mac and cheese", cookme.Ingredient{Name: "Pasta"}, cookme.Ingredient{Name: "Cheese"})
+	toast := cookme.NewRecipe("Toast", cookme.Ingredient{Name: "Bread"})
+
+	add := func(library *recipe.Library, ctx context.Context, r cookme.Recipe) error {
+		_, err := library.AddRecipe(ctx, &recipe.AddRecipeRequest{Recipe: recipe.ConvertRecipeToGRPC(r)})
+		return err
+	}
+
+	recipes := func(library *recipe.Library, ctx context.Context) (cookme.Recipes, error) {
+		res, err := library.GetRecipes(ctx, &recipe.GetRecipesRequest{})
+
+		if err != nil {
+			return nil, err
+		}
+
+		var got cookme.Recipes
+		for _, r := range res.GetRecipes() {
+			got = append(got, recipe.ConvertRecipeFromGRPC(r))
+		}
+
+		return got, nil
+	}
+
+	t.Run("households only see their own recipes", func(t *testing.T) {
+		library, cleanup := NewTestLibrary(t)
+		defer cleanup()
+
+		home, office := asHousehold("home", ""), asHousehold("office", "")
+
+		add(library, home, macAndCheese)
+		add(library, office, toast)
+
+		got, _ := recipes(library, home)
+		AssertRecipesEqual(t, got, cookme.Recipes{macAndCheese})
+
+		got, _ = recipes(library, office)
+		AssertRecipesEqual(t, got, cookme.Recipes{toast})
+	})
+
+	t.Run("the default household keeps the recipes of a book made before households", func(t *testing.T) {
+		dbFilename := cookme.RandomString() + ".db"
+		defer os.Remove(dbFilename)
+
+		book, _ := recipe.NewBook(dbFilename)
+		book.Add(macAndCheese)
+
+		got, _ := recipes(recipe.NewLibrary(dbFilename), context.Background())
+		AssertRecipesEqual(t, got, cookme.Recipes{macAndCheese})
+	})
+
+	t.Run("a token can read the public recipes but not change them", func(t *testing.T) {
+		library, cleanup := NewTestLibrary(t)
+		defer cleanup()
+
+		add(library, asHousehold(household.Public, ""), toast)
+
+		got, err := recipes(library, asHousehold(household.Public, "home"))
+		assertStatusCode(t, err, codes.OK)
+		AssertRecipesEqual(t, got, cookme.Recipes{toast})
+
+		assertStatusCode(t, add(library, asHousehold(household.Public, "home"), macAndCheese), codes.PermissionDenied)
+	})
+
+	t.Run("a token can't use another household", func(t *testing.T) {
+		library, cleanup := NewTestLibrary(t)
+		defer cleanup()
+
+		_, err := recipes(library, asHousehold("office", "home"))
+		assertStatusCode(t, err, codes.PermissionDenied)
+	})
+
+	t.Run("stopping watching covers books opened afterwards", func(t *testing.T) {
+		library, cleanup := NewTestLibrary(t)
+		defer cleanup()
+
+		home, _ := library.Book("home")
+		homeEvents, _ := home.Watch()
+
+		library.StopWatching()
+
+		office, _ := library.Book("office")
+		officeEvents, _ := office.Watch()
+
+		for _, events := range []<-chan *recipe.RecipeEvent{homeEvents, officeEvents} {
+			if _, open := <-events; open {
+				t.Error("expected the watcher to be disconnected")
+			}
+		}
+	})
+}
+
+func TestLibraryMetrics(t *testing.T) {
+	library, cleanup := NewTestLibrary(t)
+	defer cleanup()
+
+	pancakes := cookme.NewRecipe("Pancakes", cookme.Ingredient{Name: "Flour"})
+	pancakes.Favourite = true
+
+	home, _ := library.Book("home")
+	home.Add(cookme.NewRecipe("Toast", cookme.Ingredient{Name: "Bread"}))
+	home.Add(pancakes)
+	library.Book("office")
+
+	registry := metrics.NewRegistry()
+	recipe.RegisterMetrics(registry, library)
+
+	res := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	for _, want := range []string{
+		`cookme_recipes{household="home"} 2`,
+		`cookme_recipes{household="office"} 0`,
+		`cookme_favourite_recipes{household="home"} 1`,
+	} {
+		if !strings.Contains(res.Body.String(), want) {
+			t.Errorf("expected %q in\n%s", want, res.Body.String())
+		}
+	}
+}
+
+// asHousehold is the context of a call asking for a household, with a token for tokenHousehold unless that's empty
+func asHousehold(requested, tokenHousehold string) context.Context {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(household.MetadataKey, requested))
+
+	if tokenHousehold != "" {
+		ctx = auth.NewContext(ctx, auth.Token{Household: tokenHousehold, Role: auth.Editor})
+	}
+
+	return ctx
+}
+
+func NewTestLibrary(t *testing.T) (library *recipe.Library, cleanup func()) {
+	t.Helper()
+	dbFilename := cookme.RandomString() + ".db"
+
+	return recipe.NewLibrary(dbFilename), func() {
+		os.Remove(dbFilename)
+	}
+}
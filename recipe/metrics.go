@@ -0,0 +1,35 @@
+package recipe
+
+import (
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/metrics"
+)
+
+// RegisterMetrics adds gauges of the recipes in the book of each household the library has opened
+func RegisterMetrics(r *metrics.Registry, library *Library) {
+	collect := func(count func(recipes cookme.Recipes) int) func() []metrics.Sample {
+		return func() []metrics.Sample {
+			var samples []metrics.Sample
+
+			for _, id := range library.Households() {
+				book, err := library.Book(id)
+
+				if err != nil {
+					continue
+				}
+
+				samples = append(samples, metrics.Sample{LabelValues: []string{id}, Value: float64(count(book.Recipes()))})
+			}
+
+			return samples
+		}
+	}
+
+	r.NewGaugeFunc("cookme_recipes", "recipes in the book", []string{"household"}, collect(func(recipes cookme.Recipes) int {
+		return len(recipes)
+	}))
+
+	r.NewGaugeFunc("cookme_favourite_recipes", "recipes starred as favourites", []string{"household"}, collect(func(recipes cookme.Recipes) int {
+		return len(recipes.Favourites())
+	}))
+}
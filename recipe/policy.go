@@ -0,0 +1,19 @@
+package recipe
+
+import "github.com/quii/monolith-to-micro/auth"
+
+// Policy is the role needed to call each RecipeService method, changing the book needs an editor. Health checks and
+// reflection, which the recipe service also serves, don't need a token
+var Policy = auth.Policy{
+	"/grpc.health.v1.Health/Check":                                   auth.Anyone,
+	"/grpc.health.v1.Health/Watch":                                   auth.Anyone,
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo": auth.Anyone,
+
+	"/RecipeService/GetRecipes":       auth.Reader,
+	"/RecipeService/FindRecipesUsing": auth.Reader,
+	"/RecipeService/WatchRecipes":     auth.Reader,
+	"/RecipeService/AddRecipe":        auth.Editor,
+	"/RecipeService/DeleteRecipe":     auth.Editor,
+	"/RecipeService/RateRecipe":       auth.Editor,
+	"/RecipeService/SetFavourite":     auth.Editor,
+}
@@ -23,6 +23,61 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+type GetRecipesRequest_SortOrder int32
+
+const (
+	GetRecipesRequest_ADDED  GetRecipesRequest_SortOrder = 0
+	GetRecipesRequest_NAME   GetRecipesRequest_SortOrder = 1
+	GetRecipesRequest_RATING GetRecipesRequest_SortOrder = 2
+)
+
+var GetRecipesRequest_SortOrder_name = map[int32]string{
+	0: "ADDED",
+	1: "NAME",
+	2: "RATING",
+}
+var GetRecipesRequest_SortOrder_value = map[string]int32{
+	"ADDED":  0,
+	"NAME":   1,
+	"RATING": 2,
+}
+
+func (x GetRecipesRequest_SortOrder) String() string {
+	return proto.EnumName(GetRecipesRequest_SortOrder_name, int32(x))
+}
+func (GetRecipesRequest_SortOrder) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_recipe_043ee46b1b7a2399, []int{2, 0}
+}
+
+type RecipeEvent_EventType int32
+
+const (
+	RecipeEvent_UNKNOWN RecipeEvent_EventType = 0
+	RecipeEvent_ADDED   RecipeEvent_EventType = 1
+	RecipeEvent_UPDATED RecipeEvent_EventType = 2
+	RecipeEvent_DELETED RecipeEvent_EventType = 3
+)
+
+var RecipeEvent_EventType_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "ADDED",
+	2: "UPDATED",
+	3: "DELETED",
+}
+var RecipeEvent_EventType_value = map[string]int32{
+	"UNKNOWN": 0,
+	"ADDED":   1,
+	"UPDATED": 2,
+	"DELETED": 3,
+}
+
+func (x RecipeEvent_EventType) String() string {
+	return proto.EnumName(RecipeEvent_EventType_name, int32(x))
+}
+func (RecipeEvent_EventType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_recipe_043ee46b1b7a2399, []int{16, 0}
+}
+
 type Ingredient struct {
 	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -34,7 +89,7 @@ func (m *Ingredient) Reset()         { *m = Ingredient{} }
 func (m *Ingredient) String() string { return proto.CompactTextString(m) }
 func (*Ingredient) ProtoMessage()    {}
 func (*Ingredient) Descriptor() ([]byte, []int) {
-	return fileDescriptor_recipe_4c025e03ac3df284, []int{0}
+	return fileDescriptor_recipe_043ee46b1b7a2399, []int{0}
 }
 func (m *Ingredient) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Ingredient.Unmarshal(m, b)
@@ -64,6 +119,8 @@ func (m *Ingredient) GetName() string {
 type Recipe struct {
 	Name                 string        `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
 	Ingredients          []*Ingredient `protobuf:"bytes,2,rep,name=Ingredients,proto3" json:"Ingredients,omitempty"`
+	Rating               int32         `protobuf:"varint,3,opt,name=Rating,proto3" json:"Rating,omitempty"`
+	Favourite            bool          `protobuf:"varint,4,opt,name=Favourite,proto3" json:"Favourite,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
 	XXX_unrecognized     []byte        `json:"-"`
 	XXX_sizecache        int32         `json:"-"`
@@ -73,7 +130,7 @@ func (m *Recipe) Reset()         { *m = Recipe{} }
 func (m *Recipe) String() string { return proto.CompactTextString(m) }
 func (*Recipe) ProtoMessage()    {}
 func (*Recipe) Descriptor() ([]byte, []int) {
-	return fileDescriptor_recipe_4c025e03ac3df284, []int{1}
+	return fileDescriptor_recipe_043ee46b1b7a2399, []int{1}
 }
 func (m *Recipe) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Recipe.Unmarshal(m, b)
@@ -107,17 +164,35 @@ func (m *Recipe) GetIngredients() []*Ingredient {
 	return nil
 }
 
+func (m *Recipe) GetRating() int32 {
+	if m != nil {
+		return m.Rating
+	}
+	return 0
+}
+
+func (m *Recipe) GetFavourite() bool {
+	if m != nil {
+		return m.Favourite
+	}
+	return false
+}
+
 type GetRecipesRequest struct {
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	PageSize             int32                       `protobuf:"varint,1,opt,name=PageSize,proto3" json:"PageSize,omitempty"`
+	PageToken            string                      `protobuf:"bytes,2,opt,name=PageToken,proto3" json:"PageToken,omitempty"`
+	Query                string                      `protobuf:"bytes,3,opt,name=Query,proto3" json:"Query,omitempty"`
+	Sort                 GetRecipesRequest_SortOrder `protobuf:"varint,4,opt,name=Sort,proto3,enum=GetRecipesRequest_SortOrder" json:"Sort,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                    `json:"-"`
+	XXX_unrecognized     []byte                      `json:"-"`
+	XXX_sizecache        int32                       `json:"-"`
 }
 
 func (m *GetRecipesRequest) Reset()         { *m = GetRecipesRequest{} }
 func (m *GetRecipesRequest) String() string { return proto.CompactTextString(m) }
 func (*GetRecipesRequest) ProtoMessage()    {}
 func (*GetRecipesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_recipe_4c025e03ac3df284, []int{2}
+	return fileDescriptor_recipe_043ee46b1b7a2399, []int{2}
 }
 func (m *GetRecipesRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetRecipesRequest.Unmarshal(m, b)
@@ -137,8 +212,37 @@ func (m *GetRecipesRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_GetRecipesRequest proto.InternalMessageInfo
 
+func (m *GetRecipesRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *GetRecipesRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+func (m *GetRecipesRequest) GetQuery() string {
+	if m != nil {
+		return m.Query
+	}
+	return ""
+}
+
+func (m *GetRecipesRequest) GetSort() GetRecipesRequest_SortOrder {
+	if m != nil {
+		return m.Sort
+	}
+	return GetRecipesRequest_ADDED
+}
+
 type GetRecipesResponse struct {
 	Recipes              []*Recipe `protobuf:"bytes,1,rep,name=Recipes,proto3" json:"Recipes,omitempty"`
+	NextPageToken        string    `protobuf:"bytes,2,opt,name=NextPageToken,proto3" json:"NextPageToken,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
 	XXX_unrecognized     []byte    `json:"-"`
 	XXX_sizecache        int32     `json:"-"`
@@ -148,7 +252,7 @@ func (m *GetRecipesResponse) Reset()         { *m = GetRecipesResponse{} }
 func (m *GetRecipesResponse) String() string { return proto.CompactTextString(m) }
 func (*GetRecipesResponse) ProtoMessage()    {}
 func (*GetRecipesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_recipe_4c025e03ac3df284, []int{3}
+	return fileDescriptor_recipe_043ee46b1b7a2399, []int{3}
 }
 func (m *GetRecipesResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_GetRecipesResponse.Unmarshal(m, b)
@@ -175,6 +279,13 @@ func (m *GetRecipesResponse) GetRecipes() []*Recipe {
 	return nil
 }
 
+func (m *GetRecipesResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 type AddRecipeRequest struct {
 	Recipe               *Recipe  `protobuf:"bytes,1,opt,name=Recipe,proto3" json:"Recipe,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -186,7 +297,7 @@ func (m *AddRecipeRequest) Reset()         { *m = AddRecipeRequest{} }
 func (m *AddRecipeRequest) String() string { return proto.CompactTextString(m) }
 func (*AddRecipeRequest) ProtoMessage()    {}
 func (*AddRecipeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_recipe_4c025e03ac3df284, []int{4}
+	return fileDescriptor_recipe_043ee46b1b7a2399, []int{4}
 }
 func (m *AddRecipeRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_AddRecipeRequest.Unmarshal(m, b)
@@ -223,7 +334,7 @@ func (m *AddRecipeResponse) Reset()         { *m = AddRecipeResponse{} }
 func (m *AddRecipeResponse) String() string { return proto.CompactTextString(m) }
 func (*AddRecipeResponse) ProtoMessage()    {}
 func (*AddRecipeResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_recipe_4c025e03ac3df284, []int{5}
+	return fileDescriptor_recipe_043ee46b1b7a2399, []int{5}
 }
 func (m *AddRecipeResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_AddRecipeResponse.Unmarshal(m, b)
@@ -254,7 +365,7 @@ func (m *DeleteRecipeRequest) Reset()         { *m = DeleteRecipeRequest{} }
 func (m *DeleteRecipeRequest) String() string { return proto.CompactTextString(m) }
 func (*DeleteRecipeRequest) ProtoMessage()    {}
 func (*DeleteRecipeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_recipe_4c025e03ac3df284, []int{6}
+	return fileDescriptor_recipe_043ee46b1b7a2399, []int{6}
 }
 func (m *DeleteRecipeRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DeleteRecipeRequest.Unmarshal(m, b)
@@ -291,7 +402,7 @@ func (m *DeleteRecipeResponse) Reset()         { *m = DeleteRecipeResponse{} }
 func (m *DeleteRecipeResponse) String() string { return proto.CompactTextString(m) }
 func (*DeleteRecipeResponse) ProtoMessage()    {}
 func (*DeleteRecipeResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_recipe_4c025e03ac3df284, []int{7}
+	return fileDescriptor_recipe_043ee46b1b7a2399, []int{7}
 }
 func (m *DeleteRecipeResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_DeleteRecipeResponse.Unmarshal(m, b)
@@ -311,6 +422,356 @@ func (m *DeleteRecipeResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_DeleteRecipeResponse proto.InternalMessageInfo
 
+type RateRecipeRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Rating               int32    `protobuf:"varint,2,opt,name=Rating,proto3" json:"Rating,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RateRecipeRequest) Reset()         { *m = RateRecipeRequest{} }
+func (m *RateRecipeRequest) String() string { return proto.CompactTextString(m) }
+func (*RateRecipeRequest) ProtoMessage()    {}
+func (*RateRecipeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_recipe_043ee46b1b7a2399, []int{8}
+}
+func (m *RateRecipeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RateRecipeRequest.Unmarshal(m, b)
+}
+func (m *RateRecipeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RateRecipeRequest.Marshal(b, m, deterministic)
+}
+func (dst *RateRecipeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RateRecipeRequest.Merge(dst, src)
+}
+func (m *RateRecipeRequest) XXX_Size() int {
+	return xxx_messageInfo_RateRecipeRequest.Size(m)
+}
+func (m *RateRecipeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RateRecipeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RateRecipeRequest proto.InternalMessageInfo
+
+func (m *RateRecipeRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *RateRecipeRequest) GetRating() int32 {
+	if m != nil {
+		return m.Rating
+	}
+	return 0
+}
+
+type RateRecipeResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RateRecipeResponse) Reset()         { *m = RateRecipeResponse{} }
+func (m *RateRecipeResponse) String() string { return proto.CompactTextString(m) }
+func (*RateRecipeResponse) ProtoMessage()    {}
+func (*RateRecipeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_recipe_043ee46b1b7a2399, []int{9}
+}
+func (m *RateRecipeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RateRecipeResponse.Unmarshal(m, b)
+}
+func (m *RateRecipeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RateRecipeResponse.Marshal(b, m, deterministic)
+}
+func (dst *RateRecipeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RateRecipeResponse.Merge(dst, src)
+}
+func (m *RateRecipeResponse) XXX_Size() int {
+	return xxx_messageInfo_RateRecipeResponse.Size(m)
+}
+func (m *RateRecipeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RateRecipeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RateRecipeResponse proto.InternalMessageInfo
+
+type SetFavouriteRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Favourite            bool     `protobuf:"varint,2,opt,name=Favourite,proto3" json:"Favourite,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetFavouriteRequest) Reset()         { *m = SetFavouriteRequest{} }
+func (m *SetFavouriteRequest) String() string { return proto.CompactTextString(m) }
+func (*SetFavouriteRequest) ProtoMessage()    {}
+func (*SetFavouriteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_recipe_043ee46b1b7a2399, []int{10}
+}
+func (m *SetFavouriteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetFavouriteRequest.Unmarshal(m, b)
+}
+func (m *SetFavouriteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetFavouriteRequest.Marshal(b, m, deterministic)
+}
+func (dst *SetFavouriteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetFavouriteRequest.Merge(dst, src)
+}
+func (m *SetFavouriteRequest) XXX_Size() int {
+	return xxx_messageInfo_SetFavouriteRequest.Size(m)
+}
+func (m *SetFavouriteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetFavouriteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetFavouriteRequest proto.InternalMessageInfo
+
+func (m *SetFavouriteRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *SetFavouriteRequest) GetFavourite() bool {
+	if m != nil {
+		return m.Favourite
+	}
+	return false
+}
+
+type SetFavouriteResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetFavouriteResponse) Reset()         { *m = SetFavouriteResponse{} }
+func (m *SetFavouriteResponse) String() string { return proto.CompactTextString(m) }
+func (*SetFavouriteResponse) ProtoMessage()    {}
+func (*SetFavouriteResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_recipe_043ee46b1b7a2399, []int{11}
+}
+func (m *SetFavouriteResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetFavouriteResponse.Unmarshal(m, b)
+}
+func (m *SetFavouriteResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetFavouriteResponse.Marshal(b, m, deterministic)
+}
+func (dst *SetFavouriteResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetFavouriteResponse.Merge(dst, src)
+}
+func (m *SetFavouriteResponse) XXX_Size() int {
+	return xxx_messageInfo_SetFavouriteResponse.Size(m)
+}
+func (m *SetFavouriteResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetFavouriteResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetFavouriteResponse proto.InternalMessageInfo
+
+type FindRecipesUsingRequest struct {
+	Ingredients          []string `protobuf:"bytes,1,rep,name=Ingredients,proto3" json:"Ingredients,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FindRecipesUsingRequest) Reset()         { *m = FindRecipesUsingRequest{} }
+func (m *FindRecipesUsingRequest) String() string { return proto.CompactTextString(m) }
+func (*FindRecipesUsingRequest) ProtoMessage()    {}
+func (*FindRecipesUsingRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_recipe_043ee46b1b7a2399, []int{12}
+}
+func (m *FindRecipesUsingRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FindRecipesUsingRequest.Unmarshal(m, b)
+}
+func (m *FindRecipesUsingRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FindRecipesUsingRequest.Marshal(b, m, deterministic)
+}
+func (dst *FindRecipesUsingRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FindRecipesUsingRequest.Merge(dst, src)
+}
+func (m *FindRecipesUsingRequest) XXX_Size() int {
+	return xxx_messageInfo_FindRecipesUsingRequest.Size(m)
+}
+func (m *FindRecipesUsingRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FindRecipesUsingRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FindRecipesUsingRequest proto.InternalMessageInfo
+
+func (m *FindRecipesUsingRequest) GetIngredients() []string {
+	if m != nil {
+		return m.Ingredients
+	}
+	return nil
+}
+
+type RecipeMatch struct {
+	Recipe               *Recipe  `protobuf:"bytes,1,opt,name=Recipe,proto3" json:"Recipe,omitempty"`
+	MatchingIngredients  int32    `protobuf:"varint,2,opt,name=MatchingIngredients,proto3" json:"MatchingIngredients,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RecipeMatch) Reset()         { *m = RecipeMatch{} }
+func (m *RecipeMatch) String() string { return proto.CompactTextString(m) }
+func (*RecipeMatch) ProtoMessage()    {}
+func (*RecipeMatch) Descriptor() ([]byte, []int) {
+	return fileDescriptor_recipe_043ee46b1b7a2399, []int{13}
+}
+func (m *RecipeMatch) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RecipeMatch.Unmarshal(m, b)
+}
+func (m *RecipeMatch) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RecipeMatch.Marshal(b, m, deterministic)
+}
+func (dst *RecipeMatch) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RecipeMatch.Merge(dst, src)
+}
+func (m *RecipeMatch) XXX_Size() int {
+	return xxx_messageInfo_RecipeMatch.Size(m)
+}
+func (m *RecipeMatch) XXX_DiscardUnknown() {
+	xxx_messageInfo_RecipeMatch.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RecipeMatch proto.InternalMessageInfo
+
+func (m *RecipeMatch) GetRecipe() *Recipe {
+	if m != nil {
+		return m.Recipe
+	}
+	return nil
+}
+
+func (m *RecipeMatch) GetMatchingIngredients() int32 {
+	if m != nil {
+		return m.MatchingIngredients
+	}
+	return 0
+}
+
+type FindRecipesUsingResponse struct {
+	Matches              []*RecipeMatch `protobuf:"bytes,1,rep,name=Matches,proto3" json:"Matches,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *FindRecipesUsingResponse) Reset()         { *m = FindRecipesUsingResponse{} }
+func (m *FindRecipesUsingResponse) String() string { return proto.CompactTextString(m) }
+func (*FindRecipesUsingResponse) ProtoMessage()    {}
+func (*FindRecipesUsingResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_recipe_043ee46b1b7a2399, []int{14}
+}
+func (m *FindRecipesUsingResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FindRecipesUsingResponse.Unmarshal(m, b)
+}
+func (m *FindRecipesUsingResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FindRecipesUsingResponse.Marshal(b, m, deterministic)
+}
+func (dst *FindRecipesUsingResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FindRecipesUsingResponse.Merge(dst, src)
+}
+func (m *FindRecipesUsingResponse) XXX_Size() int {
+	return xxx_messageInfo_FindRecipesUsingResponse.Size(m)
+}
+func (m *FindRecipesUsingResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_FindRecipesUsingResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FindRecipesUsingResponse proto.InternalMessageInfo
+
+func (m *FindRecipesUsingResponse) GetMatches() []*RecipeMatch {
+	if m != nil {
+		return m.Matches
+	}
+	return nil
+}
+
+type WatchRecipesRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchRecipesRequest) Reset()         { *m = WatchRecipesRequest{} }
+func (m *WatchRecipesRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchRecipesRequest) ProtoMessage()    {}
+func (*WatchRecipesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_recipe_043ee46b1b7a2399, []int{15}
+}
+func (m *WatchRecipesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchRecipesRequest.Unmarshal(m, b)
+}
+func (m *WatchRecipesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchRecipesRequest.Marshal(b, m, deterministic)
+}
+func (dst *WatchRecipesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchRecipesRequest.Merge(dst, src)
+}
+func (m *WatchRecipesRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchRecipesRequest.Size(m)
+}
+func (m *WatchRecipesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchRecipesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchRecipesRequest proto.InternalMessageInfo
+
+type RecipeEvent struct {
+	Type                 RecipeEvent_EventType `protobuf:"varint,1,opt,name=Type,proto3,enum=RecipeEvent_EventType" json:"Type,omitempty"`
+	Recipe               *Recipe               `protobuf:"bytes,2,opt,name=Recipe,proto3" json:"Recipe,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *RecipeEvent) Reset()         { *m = RecipeEvent{} }
+func (m *RecipeEvent) String() string { return proto.CompactTextString(m) }
+func (*RecipeEvent) ProtoMessage()    {}
+func (*RecipeEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_recipe_043ee46b1b7a2399, []int{16}
+}
+func (m *RecipeEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RecipeEvent.Unmarshal(m, b)
+}
+func (m *RecipeEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RecipeEvent.Marshal(b, m, deterministic)
+}
+func (dst *RecipeEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RecipeEvent.Merge(dst, src)
+}
+func (m *RecipeEvent) XXX_Size() int {
+	return xxx_messageInfo_RecipeEvent.Size(m)
+}
+func (m *RecipeEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_RecipeEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RecipeEvent proto.InternalMessageInfo
+
+func (m *RecipeEvent) GetType() RecipeEvent_EventType {
+	if m != nil {
+		return m.Type
+	}
+	return RecipeEvent_UNKNOWN
+}
+
+func (m *RecipeEvent) GetRecipe() *Recipe {
+	if m != nil {
+		return m.Recipe
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Ingredient)(nil), "Ingredient")
 	proto.RegisterType((*Recipe)(nil), "Recipe")
@@ -320,6 +781,17 @@ func init() {
 	proto.RegisterType((*AddRecipeResponse)(nil), "AddRecipeResponse")
 	proto.RegisterType((*DeleteRecipeRequest)(nil), "DeleteRecipeRequest")
 	proto.RegisterType((*DeleteRecipeResponse)(nil), "DeleteRecipeResponse")
+	proto.RegisterType((*RateRecipeRequest)(nil), "RateRecipeRequest")
+	proto.RegisterType((*RateRecipeResponse)(nil), "RateRecipeResponse")
+	proto.RegisterType((*SetFavouriteRequest)(nil), "SetFavouriteRequest")
+	proto.RegisterType((*SetFavouriteResponse)(nil), "SetFavouriteResponse")
+	proto.RegisterType((*FindRecipesUsingRequest)(nil), "FindRecipesUsingRequest")
+	proto.RegisterType((*RecipeMatch)(nil), "RecipeMatch")
+	proto.RegisterType((*FindRecipesUsingResponse)(nil), "FindRecipesUsingResponse")
+	proto.RegisterType((*WatchRecipesRequest)(nil), "WatchRecipesRequest")
+	proto.RegisterType((*RecipeEvent)(nil), "RecipeEvent")
+	proto.RegisterEnum("GetRecipesRequest_SortOrder", GetRecipesRequest_SortOrder_name, GetRecipesRequest_SortOrder_value)
+	proto.RegisterEnum("RecipeEvent_EventType", RecipeEvent_EventType_name, RecipeEvent_EventType_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -337,6 +809,10 @@ type RecipeServiceClient interface {
 	GetRecipes(ctx context.Context, in *GetRecipesRequest, opts ...grpc.CallOption) (*GetRecipesResponse, error)
 	AddRecipe(ctx context.Context, in *AddRecipeRequest, opts ...grpc.CallOption) (*AddRecipeResponse, error)
 	DeleteRecipe(ctx context.Context, in *DeleteRecipeRequest, opts ...grpc.CallOption) (*DeleteRecipeResponse, error)
+	RateRecipe(ctx context.Context, in *RateRecipeRequest, opts ...grpc.CallOption) (*RateRecipeResponse, error)
+	SetFavourite(ctx context.Context, in *SetFavouriteRequest, opts ...grpc.CallOption) (*SetFavouriteResponse, error)
+	FindRecipesUsing(ctx context.Context, in *FindRecipesUsingRequest, opts ...grpc.CallOption) (*FindRecipesUsingResponse, error)
+	WatchRecipes(ctx context.Context, in *WatchRecipesRequest, opts ...grpc.CallOption) (RecipeService_WatchRecipesClient, error)
 }
 
 type recipeServiceClient struct {
@@ -374,11 +850,74 @@ func (c *recipeServiceClient) DeleteRecipe(ctx context.Context, in *DeleteRecipe
 	return out, nil
 }
 
+func (c *recipeServiceClient) RateRecipe(ctx context.Context, in *RateRecipeRequest, opts ...grpc.CallOption) (*RateRecipeResponse, error) {
+	out := new(RateRecipeResponse)
+	err := c.cc.Invoke(ctx, "/RecipeService/RateRecipe", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *recipeServiceClient) SetFavourite(ctx context.Context, in *SetFavouriteRequest, opts ...grpc.CallOption) (*SetFavouriteResponse, error) {
+	out := new(SetFavouriteResponse)
+	err := c.cc.Invoke(ctx, "/RecipeService/SetFavourite", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *recipeServiceClient) FindRecipesUsing(ctx context.Context, in *FindRecipesUsingRequest, opts ...grpc.CallOption) (*FindRecipesUsingResponse, error) {
+	out := new(FindRecipesUsingResponse)
+	err := c.cc.Invoke(ctx, "/RecipeService/FindRecipesUsing", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *recipeServiceClient) WatchRecipes(ctx context.Context, in *WatchRecipesRequest, opts ...grpc.CallOption) (RecipeService_WatchRecipesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_RecipeService_serviceDesc.Streams[0], "/RecipeService/WatchRecipes", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &recipeServiceWatchRecipesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RecipeService_WatchRecipesClient interface {
+	Recv() (*RecipeEvent, error)
+	grpc.ClientStream
+}
+
+type recipeServiceWatchRecipesClient struct {
+	grpc.ClientStream
+}
+
+func (x *recipeServiceWatchRecipesClient) Recv() (*RecipeEvent, error) {
+	m := new(RecipeEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // RecipeServiceServer is the server API for RecipeService service.
 type RecipeServiceServer interface {
 	GetRecipes(context.Context, *GetRecipesRequest) (*GetRecipesResponse, error)
 	AddRecipe(context.Context, *AddRecipeRequest) (*AddRecipeResponse, error)
 	DeleteRecipe(context.Context, *DeleteRecipeRequest) (*DeleteRecipeResponse, error)
+	RateRecipe(context.Context, *RateRecipeRequest) (*RateRecipeResponse, error)
+	SetFavourite(context.Context, *SetFavouriteRequest) (*SetFavouriteResponse, error)
+	FindRecipesUsing(context.Context, *FindRecipesUsingRequest) (*FindRecipesUsingResponse, error)
+	WatchRecipes(*WatchRecipesRequest, RecipeService_WatchRecipesServer) error
 }
 
 func RegisterRecipeServiceServer(s *grpc.Server, srv RecipeServiceServer) {
@@ -439,6 +978,81 @@ func _RecipeService_DeleteRecipe_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RecipeService_RateRecipe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RateRecipeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecipeServiceServer).RateRecipe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/RecipeService/RateRecipe",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecipeServiceServer).RateRecipe(ctx, req.(*RateRecipeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RecipeService_SetFavourite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetFavouriteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecipeServiceServer).SetFavourite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/RecipeService/SetFavourite",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecipeServiceServer).SetFavourite(ctx, req.(*SetFavouriteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RecipeService_FindRecipesUsing_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindRecipesUsingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecipeServiceServer).FindRecipesUsing(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/RecipeService/FindRecipesUsing",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecipeServiceServer).FindRecipesUsing(ctx, req.(*FindRecipesUsingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RecipeService_WatchRecipes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRecipesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RecipeServiceServer).WatchRecipes(m, &recipeServiceWatchRecipesServer{stream})
+}
+
+type RecipeService_WatchRecipesServer interface {
+	Send(*RecipeEvent) error
+	grpc.ServerStream
+}
+
+type recipeServiceWatchRecipesServer struct {
+	grpc.ServerStream
+}
+
+func (x *recipeServiceWatchRecipesServer) Send(m *RecipeEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _RecipeService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "RecipeService",
 	HandlerType: (*RecipeServiceServer)(nil),
@@ -455,30 +1069,73 @@ var _RecipeService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteRecipe",
 			Handler:    _RecipeService_DeleteRecipe_Handler,
 		},
+		{
+			MethodName: "RateRecipe",
+			Handler:    _RecipeService_RateRecipe_Handler,
+		},
+		{
+			MethodName: "SetFavourite",
+			Handler:    _RecipeService_SetFavourite_Handler,
+		},
+		{
+			MethodName: "FindRecipesUsing",
+			Handler:    _RecipeService_FindRecipesUsing_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchRecipes",
+			Handler:       _RecipeService_WatchRecipes_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "recipe/recipe.proto",
 }
 
-func init() { proto.RegisterFile("recipe/recipe.proto", fileDescriptor_recipe_4c025e03ac3df284) }
-
-var fileDescriptor_recipe_4c025e03ac3df284 = []byte{
-	// 262 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x92, 0xc1, 0x4a, 0xc4, 0x30,
-	0x18, 0x84, 0xa9, 0xca, 0x2e, 0x3b, 0x55, 0x70, 0xff, 0xae, 0x52, 0x7a, 0xb1, 0xe6, 0xb4, 0x1e,
-	0x8c, 0xd0, 0x45, 0x3c, 0x78, 0x12, 0x04, 0x11, 0xc1, 0x43, 0x7c, 0x02, 0xdd, 0xfe, 0x48, 0x41,
-	0xdb, 0xda, 0x44, 0x5f, 0xcd, 0xd7, 0x13, 0x93, 0xae, 0xcd, 0xda, 0x9c, 0xda, 0xcc, 0x9f, 0x99,
-	0x7c, 0x13, 0x82, 0xa4, 0xe3, 0x75, 0xd5, 0xf2, 0x85, 0xfb, 0xc8, 0xb6, 0x6b, 0x4c, 0x23, 0x72,
-	0xe0, 0xbe, 0x7e, 0xed, 0xb8, 0xac, 0xb8, 0x36, 0x44, 0xd8, 0x7b, 0x7c, 0x7e, 0xe7, 0x34, 0xca,
-	0xa3, 0xe5, 0x4c, 0xd9, 0x7f, 0xf1, 0x80, 0x89, 0xb2, 0x8e, 0xd0, 0x94, 0xce, 0x11, 0x0f, 0x7e,
-	0x9d, 0xee, 0xe4, 0xbb, 0xcb, 0xb8, 0x88, 0xe5, 0xa0, 0x29, 0x7f, 0x2e, 0x12, 0xcc, 0xef, 0xd8,
-	0xb8, 0x3c, 0xad, 0xf8, 0xe3, 0x93, 0xb5, 0x11, 0x57, 0x20, 0x5f, 0xd4, 0x6d, 0x53, 0x6b, 0xa6,
-	0x53, 0x4c, 0x7b, 0x29, 0x8d, 0x6c, 0xea, 0x54, 0xba, 0xb5, 0xda, 0xe8, 0x62, 0x85, 0xc3, 0x9b,
-	0xb2, 0xec, 0x55, 0x17, 0x46, 0x27, 0x1b, 0x5c, 0x8b, 0xe9, 0xb9, 0x7a, 0xf9, 0x17, 0xc1, 0x33,
-	0xb9, 0xc3, 0xc4, 0x19, 0x92, 0x5b, 0x7e, 0x63, 0xc3, 0xdb, 0x61, 0xa1, 0xfb, 0x38, 0xc6, 0x62,
-	0x7b, 0xab, 0x8b, 0x28, 0xbe, 0x23, 0x1c, 0x38, 0xe9, 0x89, 0xbb, 0xaf, 0x6a, 0xcd, 0x74, 0x09,
-	0x0c, 0xbd, 0x88, 0xe4, 0xa8, 0x79, 0x96, 0xc8, 0x40, 0xf1, 0x02, 0xb3, 0x3f, 0x40, 0x9a, 0xcb,
-	0xff, 0x0d, 0x33, 0x92, 0x23, 0x7e, 0xba, 0xc6, 0xbe, 0x0f, 0x45, 0x0b, 0x19, 0xa8, 0x93, 0x1d,
-	0xc9, 0x10, 0xf9, 0xcb, 0xc4, 0x3e, 0x85, 0xd5, 0x4f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x2c, 0xf4,
-	0xb1, 0x42, 0x21, 0x02, 0x00, 0x00,
+func init() { proto.RegisterFile("recipe/recipe.proto", fileDescriptor_recipe_043ee46b1b7a2399) }
+
+var fileDescriptor_recipe_043ee46b1b7a2399 = []byte{
+	// 657 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x95, 0xdd, 0x6e, 0xd3, 0x4a,
+	0x10, 0xc7, 0xeb, 0x7c, 0xd6, 0x93, 0xb4, 0x72, 0xc6, 0x6e, 0x8e, 0x8f, 0x55, 0x89, 0xb0, 0x42,
+	0x28, 0x20, 0x58, 0xaa, 0x14, 0xae, 0x2a, 0x84, 0x82, 0x9c, 0x46, 0x15, 0xd4, 0x2d, 0x9b, 0x54,
+	0xbd, 0x42, 0xc2, 0x34, 0xab, 0x60, 0x01, 0x4e, 0xb1, 0xdd, 0x8a, 0x72, 0xc7, 0x63, 0xf0, 0x0a,
+	0xbc, 0x06, 0x2f, 0x86, 0xbc, 0x76, 0xe2, 0x75, 0xec, 0x02, 0x37, 0x89, 0x67, 0x66, 0xe7, 0x3f,
+	0xe3, 0xd9, 0xdf, 0x24, 0xa0, 0x07, 0xfc, 0xc2, 0xbb, 0xe4, 0x4f, 0x92, 0x2f, 0x7a, 0x19, 0x2c,
+	0xa2, 0x05, 0xe9, 0x01, 0x1c, 0xf9, 0xf3, 0x80, 0xcf, 0x3c, 0xee, 0x47, 0x88, 0x50, 0x73, 0xdc,
+	0xcf, 0xdc, 0x54, 0x7a, 0x4a, 0x5f, 0x65, 0xe2, 0x99, 0x7c, 0x57, 0xa0, 0xc1, 0x44, 0x4a, 0x59,
+	0x18, 0x1f, 0x43, 0x2b, 0x13, 0x08, 0xcd, 0x4a, 0xaf, 0xda, 0x6f, 0x0d, 0x5a, 0x34, 0xf3, 0x31,
+	0x39, 0x8e, 0x5d, 0x68, 0x30, 0x37, 0xf2, 0xfc, 0xb9, 0x59, 0xed, 0x29, 0xfd, 0x3a, 0x4b, 0x2d,
+	0xdc, 0x05, 0xf5, 0xd0, 0xbd, 0x5e, 0x5c, 0x05, 0x5e, 0xc4, 0xcd, 0x5a, 0x4f, 0xe9, 0x6f, 0xb2,
+	0xcc, 0x41, 0x7e, 0x29, 0xd0, 0x19, 0xf3, 0x28, 0x69, 0x23, 0x64, 0xfc, 0xcb, 0x15, 0x0f, 0x23,
+	0xb4, 0x60, 0xf3, 0xd4, 0x9d, 0xf3, 0x89, 0xf7, 0x2d, 0x69, 0xa9, 0xce, 0x56, 0x76, 0xac, 0x17,
+	0x3f, 0x4f, 0x17, 0x1f, 0xb9, 0x6f, 0x56, 0x44, 0xbf, 0x99, 0x03, 0x0d, 0xa8, 0xbf, 0xb9, 0xe2,
+	0xc1, 0x8d, 0x68, 0x42, 0x65, 0x89, 0x81, 0x7b, 0x50, 0x9b, 0x2c, 0x82, 0x48, 0x94, 0xdf, 0x1e,
+	0xec, 0xd2, 0x42, 0x45, 0x1a, 0x87, 0x4f, 0x82, 0x19, 0x0f, 0x98, 0x38, 0x49, 0x1e, 0x81, 0xba,
+	0x72, 0xa1, 0x0a, 0xf5, 0xa1, 0x6d, 0x8f, 0x6c, 0x6d, 0x03, 0x37, 0xa1, 0xe6, 0x0c, 0x8f, 0x47,
+	0x9a, 0x82, 0x00, 0x0d, 0x36, 0x9c, 0x1e, 0x39, 0x63, 0xad, 0x42, 0xde, 0x02, 0xca, 0x92, 0xe1,
+	0xe5, 0xc2, 0x0f, 0x39, 0xde, 0x85, 0x66, 0xea, 0x32, 0x15, 0x31, 0xbc, 0x26, 0x4d, 0x6c, 0xb6,
+	0xf4, 0xe3, 0x3d, 0xd8, 0x72, 0xf8, 0xd7, 0x68, 0xfd, 0x85, 0xf2, 0x4e, 0xb2, 0x0f, 0xda, 0x70,
+	0x36, 0x4b, 0x73, 0xd3, 0x11, 0xdd, 0x59, 0xde, 0x9d, 0x18, 0x90, 0xa4, 0x9d, 0xba, 0x89, 0x0e,
+	0x1d, 0x29, 0x29, 0x69, 0x89, 0x3c, 0x00, 0xdd, 0xe6, 0x9f, 0x78, 0xc4, 0xf3, 0x62, 0x65, 0x74,
+	0x74, 0xc1, 0xc8, 0x1f, 0x4d, 0x25, 0x5e, 0x40, 0x87, 0xb9, 0xff, 0x20, 0x20, 0x01, 0x51, 0x91,
+	0x81, 0x20, 0x06, 0x20, 0x73, 0x0b, 0xb2, 0x63, 0xd0, 0x27, 0x3c, 0x5a, 0x81, 0xf1, 0x27, 0xe1,
+	0x1c, 0x51, 0x95, 0x75, 0xa2, 0xba, 0x60, 0xe4, 0x85, 0xd2, 0x02, 0x07, 0xf0, 0xdf, 0xa1, 0xe7,
+	0xa7, 0x03, 0x09, 0xcf, 0x42, 0xcf, 0x9f, 0x2f, 0x8b, 0xf4, 0xf2, 0xa4, 0xc7, 0x97, 0xa5, 0xe6,
+	0xe0, 0x26, 0xef, 0xa0, 0x95, 0x24, 0x1e, 0xbb, 0xd1, 0xc5, 0x87, 0xbf, 0x0e, 0x1f, 0xf7, 0x40,
+	0x17, 0x27, 0x3d, 0x7f, 0x9e, 0xdf, 0xa1, 0x78, 0x10, 0x65, 0x21, 0xf2, 0x12, 0xcc, 0x62, 0x7b,
+	0x29, 0x48, 0xf7, 0xa1, 0x29, 0x52, 0x56, 0x20, 0xb5, 0xa9, 0xd4, 0x0d, 0x5b, 0x06, 0xc9, 0x0e,
+	0xe8, 0xe7, 0xc2, 0x93, 0x63, 0x9b, 0xfc, 0x50, 0x96, 0xdd, 0x8f, 0xae, 0xe3, 0xdf, 0x82, 0x87,
+	0x50, 0x9b, 0xde, 0xa4, 0xbd, 0x6f, 0x0f, 0xba, 0x54, 0x8a, 0x51, 0xf1, 0x19, 0x47, 0x99, 0x38,
+	0x23, 0xbd, 0x69, 0xa5, 0x1c, 0xb3, 0xe7, 0xa0, 0xae, 0x72, 0xb0, 0x05, 0xcd, 0x33, 0xe7, 0x95,
+	0x73, 0x72, 0xee, 0x68, 0x1b, 0xd9, 0xd6, 0x28, 0xc2, 0x7f, 0x6a, 0x0f, 0xa7, 0x23, 0x5b, 0xab,
+	0xc4, 0x86, 0x3d, 0x7a, 0x3d, 0x8a, 0x8d, 0xea, 0xe0, 0x67, 0x15, 0xb6, 0x12, 0xa5, 0x09, 0x0f,
+	0xae, 0xbd, 0x0b, 0x8e, 0xcf, 0x00, 0xb2, 0x5d, 0x42, 0x2c, 0xee, 0xaa, 0xa5, 0xd3, 0x92, 0x65,
+	0x1b, 0x80, 0xba, 0xc2, 0x1d, 0x3b, 0x74, 0x7d, 0x5f, 0x2c, 0xa4, 0x85, 0x6d, 0xc0, 0x03, 0x68,
+	0xcb, 0x88, 0xa3, 0x41, 0x4b, 0x96, 0xc3, 0xda, 0xa1, 0x65, 0x7b, 0x10, 0xf7, 0x99, 0x61, 0x8c,
+	0x48, 0x0b, 0x4b, 0x61, 0xe9, 0xb4, 0xc8, 0x79, 0x5c, 0x53, 0xc6, 0x13, 0x0d, 0x5a, 0x82, 0xbd,
+	0xb5, 0x43, 0xcb, 0x18, 0xc6, 0x31, 0x68, 0xeb, 0x90, 0xa0, 0x49, 0x6f, 0xc1, 0xda, 0xfa, 0x9f,
+	0xde, 0x4a, 0xd4, 0x53, 0x68, 0xcb, 0xa4, 0xa0, 0x41, 0x4b, 0xc0, 0xb1, 0xda, 0x32, 0x1a, 0x7b,
+	0xca, 0xfb, 0x86, 0xf8, 0x67, 0xd9, 0xff, 0x3d, 0x00, 0x27, 0x1c, 0x92, 0x06, 0x70, 0x06, 0x00,
+	0x00,
 }
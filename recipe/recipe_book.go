@@ -2,85 +2,515 @@ package recipe
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/quii/monolith-to-micro"
 	"github.com/quii/monolith-to-micro/bucket"
+	"github.com/quii/monolith-to-micro/household"
+	"github.com/quii/monolith-to-micro/logging"
+	"github.com/quii/monolith-to-micro/tracing"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrRecipeNotFound is returned when trying to change a recipe that isn't in the book
+	ErrRecipeNotFound = errors.New("recipe not found")
+
+	// ErrInvalidRating is returned when a rating is outside of 1 to cookme.MaxRating
+	ErrInvalidRating = errors.New("rating must be between 1 and 5")
+
+	errInvalidPageToken = errors.New("invalid page token")
 )
 
 // Book contains recipes
 type Book struct {
 	boltBucket *bucket.BoltBucket
 	recipes    cookme.Recipes
+
+	mu       sync.Mutex
+	watchers *watchers
+	index    *ingredientIndex
 }
 
 const boltBucketName = "recipes"
 
-// NewBook returns a new recipe book backed by a bolt db at dbFilename
+// NewBook returns a new recipe book for the default household backed by a bolt db at dbFilename
 func NewBook(dbFilename string) (*Book, error) {
-	boltBucket, err := bucket.NewBoltBucket(dbFilename, boltBucketName)
+	return NewBookFor(dbFilename, household.Default)
+}
+
+// NewBookFor returns a new recipe book for a household, kept apart from every other household's
+func NewBookFor(dbFilename string, householdID string) (*Book, error) {
+	boltBucket, err := bucket.NewBoltBucket(dbFilename, household.Bucket(boltBucketName, householdID))
+
+	if err != nil {
+		return nil, err
+	}
+
+	book := &Book{boltBucket: boltBucket, watchers: newWatchers(watchBuffer)}
+	recipes, err := book.load(context.Background())
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &Book{boltBucket: boltBucket}, nil
+	book.index = newIngredientIndex(recipes)
+
+	return book, nil
 }
 
-// GetRecipes allows Book to act as a RecipeServiceServer
-func (b *Book) GetRecipes(c context.Context, r *GetRecipesRequest) (*GetRecipesResponse, error) {
+// GetRecipes allows Book to act as a RecipeServiceServer. Results are paged when a PageSize is given, otherwise every
+// matching recipe is returned
+func (b *Book) GetRecipes(ctx context.Context, r *GetRecipesRequest) (res *GetRecipesResponse, err error) {
+	ctx, span := tracing.Start(ctx, "Book.GetRecipes", "query", r.Query, "page_size", int(r.PageSize))
+	defer endSpan(span, &err)
+
+	offset, err := decodePageToken(r.PageToken)
+
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if r.PageSize < 0 {
+		return nil, status.Error(codes.InvalidArgument, "page size can't be negative")
+	}
+
+	found, total, err := b.find(ctx, r.Query, r.Sort, offset, int(r.PageSize))
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	span.SetAttributes("recipes", len(found), "total", total)
+
 	var recipes []*Recipe
 
-	for _, r := range b.Recipes() {
-		recipes = append(recipes, convertRecipeToGRPC(r))
+	for _, r := range found {
+		recipes = append(recipes, ConvertRecipeToGRPC(r))
+	}
+
+	res = &GetRecipesResponse{Recipes: recipes}
+
+	if next := offset + len(found); r.PageSize > 0 && next < total {
+		res.NextPageToken = encodePageToken(next)
 	}
 
-	return &GetRecipesResponse{Recipes: recipes}, nil
+	return res, nil
 }
 
 // AddRecipe will add a book over RPC
-func (b *Book) AddRecipe(ctx context.Context, in *AddRecipeRequest) (*AddRecipeResponse, error) {
-	newRecipe := cookme.Recipe{Name: in.Recipe.Name}
+func (b *Book) AddRecipe(ctx context.Context, in *AddRecipeRequest) (res *AddRecipeResponse, err error) {
+	ctx, span := tracing.Start(ctx, "Book.AddRecipe", "recipe", in.GetRecipe().GetName())
+	defer endSpan(span, &err)
+
+	recipe := ConvertRecipeFromGRPC(in.Recipe)
 
-	for _, i := range in.Recipe.Ingredients {
-		newRecipe.Ingredients = append(newRecipe.Ingredients, cookme.Ingredient{Name: i.Name})
+	// recipes can be added without a rating, but any rating they do have must be one they could be rated
+	if recipe.Rating != 0 && !validRating(recipe.Rating) {
+		return nil, toStatus(ErrInvalidRating)
 	}
 
-	b.Add(newRecipe)
+	if err := b.add(ctx, recipe); err != nil {
+		return nil, toStatus(err)
+	}
 
 	return &AddRecipeResponse{}, nil
 }
 
 // DeleteRecipe will delete a recipe over RPC
-func (b *Book) DeleteRecipe(ctx context.Context, in *DeleteRecipeRequest) (*DeleteRecipeResponse, error) {
-	b.Delete(in.Name)
+func (b *Book) DeleteRecipe(ctx context.Context, in *DeleteRecipeRequest) (res *DeleteRecipeResponse, err error) {
+	ctx, span := tracing.Start(ctx, "Book.DeleteRecipe", "recipe", in.Name)
+	defer endSpan(span, &err)
+
+	if err := b.delete(ctx, in.Name); err != nil {
+		return nil, toStatus(err)
+	}
 	return &DeleteRecipeResponse{}, nil
 }
 
+// RateRecipe will rate a recipe over RPC
+func (b *Book) RateRecipe(ctx context.Context, in *RateRecipeRequest) (res *RateRecipeResponse, err error) {
+	ctx, span := tracing.Start(ctx, "Book.RateRecipe", "recipe", in.Name, "rating", int(in.Rating))
+	defer endSpan(span, &err)
+
+	if err := b.rate(ctx, in.Name, int(in.Rating)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &RateRecipeResponse{}, nil
+}
+
+// SetFavourite will star or unstar a recipe over RPC
+func (b *Book) SetFavourite(ctx context.Context, in *SetFavouriteRequest) (res *SetFavouriteResponse, err error) {
+	ctx, span := tracing.Start(ctx, "Book.SetFavourite", "recipe", in.Name, "favourite", in.Favourite)
+	defer endSpan(span, &err)
+
+	if err := b.favourite(ctx, in.Name, in.Favourite); err != nil {
+		return nil, toStatus(err)
+	}
+	return &SetFavouriteResponse{}, nil
+}
+
+// FindRecipesUsing returns recipes using any of the ingredients over RPC, those using the most of them first
+func (b *Book) FindRecipesUsing(ctx context.Context, in *FindRecipesUsingRequest) (*FindRecipesUsingResponse, error) {
+	_, span := tracing.Start(ctx, "Book.FindRecipesUsing", "ingredients", strings.Join(in.Ingredients, ","))
+	defer span.End()
+
+	res := &FindRecipesUsingResponse{}
+
+	// the index is in memory so there's no bolt read to trace
+	for _, m := range b.RecipesUsing(in.Ingredients...) {
+		res.Matches = append(res.Matches, &RecipeMatch{
+			Recipe:              ConvertRecipeToGRPC(m.Recipe),
+			MatchingIngredients: int32(m.MatchingIngredients),
+		})
+	}
+
+	return res, nil
+}
+
+// WatchRecipes streams changes to the book over RPC until the client goes away. Clients which can't keep up are
+// disconnected with ResourceExhausted and should fetch the recipes again before re-watching
+func (b *Book) WatchRecipes(in *WatchRecipesRequest, stream RecipeService_WatchRecipesServer) (err error) {
+	_, span := tracing.Start(stream.Context(), "Book.WatchRecipes")
+	defer endSpan(span, &err)
+
+	events, stop := b.Watch()
+	defer stop()
+
+	// headers let the client know it is now watching and won't miss any changes
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok && b.watchers.isClosed() {
+				return status.Error(codes.Unavailable, "the recipe service is shutting down")
+			}
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "watcher fell too far behind")
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Watch returns a channel of changes to the book from now on, call stop when you no longer want them. If events aren't
+// read quickly enough the channel is closed rather than holding up changes to the book
+func (b *Book) Watch() (events <-chan *RecipeEvent, stop func()) {
+	return b.watchers.subscribe()
+}
+
+// StopWatching disconnects everyone watching the book, and anyone who starts watching afterwards, so a server can shut
+// down without waiting for watchers to go away. Calls to WatchRecipes end with Unavailable
+func (b *Book) StopWatching() {
+	b.watchers.closeAll()
+}
+
 // Recipes returns all recipes
 func (b *Book) Recipes() cookme.Recipes {
-	var recipes cookme.Recipes
-	stuff, _ := b.boltBucket.Get()
-	json.Unmarshal(stuff, &recipes)
+	return b.RecipesFor(context.Background())
+}
+
+// RecipesFor returns all recipes as part of the work in ctx, or none if they can't be read
+func (b *Book) RecipesFor(ctx context.Context) cookme.Recipes {
+	recipes, err := b.load(ctx)
+
+	if err != nil {
+		logging.Error("problem reading recipes", "err", err)
+	}
+
 	return recipes
 }
 
+// RecipesContext returns all recipes as part of the work in ctx, or why they couldn't be read
+func (b *Book) RecipesContext(ctx context.Context) (cookme.Recipes, error) {
+	return b.load(ctx)
+}
+
+func (b *Book) load(ctx context.Context) (cookme.Recipes, error) {
+	data, err := b.boltBucket.GetContext(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return recipesFromJSON(data)
+}
+
+// recipesFromJSON decodes the book's data, a book that has never been written to has no recipes
+func recipesFromJSON(data []byte) (cookme.Recipes, error) {
+	var recipes cookme.Recipes
+
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	if err := json.Unmarshal(data, &recipes); err != nil {
+		return nil, fmt.Errorf("problem decoding recipes, %v", err)
+	}
+
+	return recipes, nil
+}
+
+// change replaces the recipes with what change makes of them in one transaction, so a failed read can't be mistaken
+// for an empty book and other processes sharing the db can't change them in between
+func (b *Book) change(ctx context.Context, change func(recipes cookme.Recipes) (cookme.Recipes, error)) error {
+	return b.boltBucket.Update(ctx, func(data []byte) ([]byte, error) {
+		recipes, err := recipesFromJSON(data)
+
+		if err != nil {
+			return nil, err
+		}
+
+		newRecipes, err := change(recipes)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return asJSON(newRecipes), nil
+	})
+}
+
+// Find returns up to limit recipes, skipping the first offset, whose name or ingredients contain query, along with how
+// many recipes matched in total. A limit of 0 means no limit
+func (b *Book) Find(query string, order GetRecipesRequest_SortOrder, offset, limit int) (cookme.Recipes, int, error) {
+	return b.find(context.Background(), query, order, offset, limit)
+}
+
+func (b *Book) find(ctx context.Context, query string, order GetRecipesRequest_SortOrder, offset, limit int) (cookme.Recipes, int, error) {
+	recipes, err := b.load(ctx)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var matches cookme.Recipes
+	query = strings.ToLower(query)
+
+	for _, r := range recipes {
+		if matchesQuery(r, query) {
+			matches = append(matches, r)
+		}
+	}
+
+	sortRecipes(matches, order)
+
+	total := len(matches)
+
+	if offset >= total {
+		return nil, total, nil
+	}
+
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+
+	return matches[offset:end], total, nil
+}
+
+// RecipesUsing returns the recipes using any of the ingredients, those using the most of them first
+func (b *Book) RecipesUsing(ingredients ...string) []Match {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.index.using(ingredients)
+}
+
 // Add will add a recipe to the book
-func (b *Book) Add(recipe cookme.Recipe) {
-	newRecipes := append(b.Recipes(), recipe)
-	b.boltBucket.Put(asJSON(newRecipes))
+func (b *Book) Add(recipe cookme.Recipe) error {
+	return b.add(context.Background(), recipe)
+}
+
+func (b *Book) add(ctx context.Context, recipe cookme.Recipe) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	err := b.change(ctx, func(recipes cookme.Recipes) (cookme.Recipes, error) {
+		return append(recipes, recipe), nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	b.index.add(recipe)
+	b.watchers.publish(RecipeEvent_ADDED, recipe)
+	return nil
 }
 
 // Delete will remove a recipe from the book
-func (b *Book) Delete(name string) {
-	var newRecipes cookme.Recipes
+func (b *Book) Delete(name string) error {
+	return b.delete(context.Background(), name)
+}
+
+func (b *Book) delete(ctx context.Context, name string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var deleted cookme.Recipes
+
+	err := b.change(ctx, func(recipes cookme.Recipes) (cookme.Recipes, error) {
+		var newRecipes cookme.Recipes
+
+		for _, r := range recipes {
+			if r.Name != name {
+				newRecipes = append(newRecipes, r)
+			} else {
+				deleted = append(deleted, r)
+			}
+		}
+
+		return newRecipes, nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	b.index.remove(name)
+	b.watchers.publish(RecipeEvent_DELETED, deleted...)
+	return nil
+}
+
+// Rate gives a recipe a rating from 1 to cookme.MaxRating
+func (b *Book) Rate(name string, rating int) error {
+	return b.rate(context.Background(), name, rating)
+}
+
+func (b *Book) rate(ctx context.Context, name string, rating int) error {
+	if !validRating(rating) {
+		return ErrInvalidRating
+	}
+
+	return b.update(ctx, name, func(r *cookme.Recipe) {
+		r.Rating = rating
+	})
+}
 
-	for _, r := range b.Recipes() {
-		if r.Name != name {
-			newRecipes = append(newRecipes, r)
+func validRating(rating int) bool {
+	return rating >= 1 && rating <= cookme.MaxRating
+}
+
+// Favourite stars or unstars a recipe
+func (b *Book) Favourite(name string, favourite bool) error {
+	return b.favourite(context.Background(), name, favourite)
+}
+
+func (b *Book) favourite(ctx context.Context, name string, favourite bool) error {
+	return b.update(ctx, name, func(r *cookme.Recipe) {
+		r.Favourite = favourite
+	})
+}
+
+func (b *Book) update(ctx context.Context, name string, change func(r *cookme.Recipe)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var updated cookme.Recipes
+	var changed cookme.Recipe
+
+	err := b.change(ctx, func(recipes cookme.Recipes) (cookme.Recipes, error) {
+		for i := range recipes {
+			if recipes[i].Name == name {
+				change(&recipes[i])
+				updated, changed = recipes, recipes[i]
+				return recipes, nil
+			}
 		}
+
+		return nil, ErrRecipeNotFound
+	})
+
+	if err != nil {
+		return err
 	}
 
-	b.boltBucket.Put(asJSON(newRecipes))
+	b.index.replace(name, updated)
+	b.watchers.publish(RecipeEvent_UPDATED, changed)
+	return nil
+}
+
+// endSpan ends a handler's span, marking it failed if the handler returned an error
+func endSpan(span *tracing.Span, err *error) {
+	span.SetError(*err)
+	span.End()
+}
+
+func matchesQuery(r cookme.Recipe, query string) bool {
+	if query == "" || strings.Contains(strings.ToLower(r.Name), query) {
+		return true
+	}
+
+	for _, i := range r.Ingredients {
+		if strings.Contains(strings.ToLower(i.Name), query) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortRecipes(recipes cookme.Recipes, order GetRecipesRequest_SortOrder) {
+	switch order {
+	case GetRecipesRequest_NAME:
+		sort.SliceStable(recipes, func(i, j int) bool {
+			return strings.ToLower(recipes[i].Name) < strings.ToLower(recipes[j].Name)
+		})
+	case GetRecipesRequest_RATING:
+		sort.SliceStable(recipes, func(i, j int) bool {
+			return recipes[i].Rating > recipes[j].Rating
+		})
+	}
+}
+
+func encodePageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodePageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+
+	if err != nil {
+		return 0, errInvalidPageToken
+	}
+
+	offset, err := strconv.Atoi(string(decoded))
+
+	if err != nil || offset < 0 {
+		return 0, errInvalidPageToken
+	}
+
+	return offset, nil
+}
+
+func toStatus(err error) error {
+	switch err {
+	case ErrRecipeNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case ErrInvalidRating:
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
 }
 
 func asJSON(recipes cookme.Recipes) []byte {
@@ -88,11 +518,26 @@ func asJSON(recipes cookme.Recipes) []byte {
 	return b
 }
 
-func convertRecipeToGRPC(r cookme.Recipe) *Recipe {
+// ConvertRecipeToGRPC turns a cookme.Recipe into its protobuf message
+func ConvertRecipeToGRPC(r cookme.Recipe) *Recipe {
 	var ingredients []*Ingredient
 	for _, i := range r.Ingredients {
 		ingredients = append(ingredients, &Ingredient{Name: i.Name})
 	}
-	recipe := &Recipe{Name: r.Name, Ingredients: ingredients}
+	recipe := &Recipe{Name: r.Name, Ingredients: ingredients, Rating: int32(r.Rating), Favourite: r.Favourite}
 	return recipe
 }
+
+// ConvertRecipeFromGRPC turns a protobuf recipe message into a cookme.Recipe
+func ConvertRecipeFromGRPC(r *Recipe) cookme.Recipe {
+	var ingredients cookme.Ingredients
+	for _, i := range r.Ingredients {
+		ingredients = append(ingredients, cookme.Ingredient{Name: i.Name})
+	}
+	return cookme.Recipe{
+		Name:        r.Name,
+		Ingredients: ingredients,
+		Rating:      int(r.Rating),
+		Favourite:   r.Favourite,
+	}
+}
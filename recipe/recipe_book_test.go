@@ -1,11 +1,18 @@
 package recipe_test
 
 import (
+	"context"
+	"fmt"
 	"github.com/google/go-cmp/cmp"
 	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/bucket"
+	"github.com/quii/monolith-to-micro/household"
 	"github.com/quii/monolith-to-micro/recipe"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"log"
 	"os"
+	"sync"
 	"testing"
 )
 
@@ -53,6 +60,337 @@ func TestRecipeBook(t *testing.T) {
 	})
 }
 
+func TestRecipeRatings(t *testing.T) {
+
+	macAndCheese := cookme.NewRecipe("Mac and cheese", cookme.Ingredient{Name: "Pasta"}, cookme.Ingredient{Name: "Cheese"})
+
+	t.Run("rating a recipe is remembered", func(t *testing.T) {
+		book, cleanup := NewTestRecipeBook(t)
+		defer cleanup()
+
+		book.Add(macAndCheese)
+
+		if err := book.Rate(macAndCheese.Name, 4); err != nil {
+			t.Fatalf("unexpected error rating recipe %v", err)
+		}
+
+		want := macAndCheese
+		want.Rating = 4
+
+		AssertRecipesEqual(t, book.Recipes(), cookme.Recipes{want})
+	})
+
+	t.Run("starring a recipe makes it a favourite", func(t *testing.T) {
+		book, cleanup := NewTestRecipeBook(t)
+		defer cleanup()
+
+		book.Add(macAndCheese)
+		book.Favourite(macAndCheese.Name, true)
+
+		AssertRecipesEqual(t, book.Recipes().Favourites(), cookme.Recipes{{Name: macAndCheese.Name, Ingredients: macAndCheese.Ingredients, Favourite: true}})
+	})
+
+	t.Run("ratings outside 1 to 5 are invalid arguments over RPC", func(t *testing.T) {
+		book, cleanup := NewTestRecipeBook(t)
+		defer cleanup()
+
+		book.Add(macAndCheese)
+
+		_, err := book.RateRecipe(context.Background(), &recipe.RateRecipeRequest{Name: macAndCheese.Name, Rating: 6})
+
+		assertStatusCode(t, err, codes.InvalidArgument)
+		AssertRecipesEqual(t, book.Recipes(), cookme.Recipes{macAndCheese})
+	})
+
+	t.Run("adding a recipe rated outside 1 to 5 is an invalid argument over RPC", func(t *testing.T) {
+		book, cleanup := NewTestRecipeBook(t)
+		defer cleanup()
+
+		badlyRated := macAndCheese
+		badlyRated.Rating = 6
+
+		_, err := book.AddRecipe(context.Background(), &recipe.AddRecipeRequest{Recipe: recipe.ConvertRecipeToGRPC(badlyRated)})
+
+		assertStatusCode(t, err, codes.InvalidArgument)
+
+		if got := book.Recipes(); len(got) != 0 {
+			t.Errorf("expected no recipes but got %+v", got)
+		}
+	})
+
+	t.Run("adding a rated favourite keeps its rating and star over RPC", func(t *testing.T) {
+		book, cleanup := NewTestRecipeBook(t)
+		defer cleanup()
+
+		rated := macAndCheese
+		rated.Rating = 4
+		rated.Favourite = true
+
+		_, err := book.AddRecipe(context.Background(), &recipe.AddRecipeRequest{Recipe: recipe.ConvertRecipeToGRPC(rated)})
+
+		assertStatusCode(t, err, codes.OK)
+		AssertRecipesEqual(t, book.Recipes(), cookme.Recipes{rated})
+	})
+
+	t.Run("starring a recipe that doesnt exist is not found over RPC", func(t *testing.T) {
+		book, cleanup := NewTestRecipeBook(t)
+		defer cleanup()
+
+		_, err := book.SetFavourite(context.Background(), &recipe.SetFavouriteRequest{Name: macAndCheese.Name, Favourite: true})
+
+		assertStatusCode(t, err, codes.NotFound)
+	})
+
+	t.Run("recipes added at the same time by books sharing a db are all kept", func(t *testing.T) {
+		dbFilename := cookme.RandomString() + ".db"
+		defer os.Remove(dbFilename)
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 10; i++ {
+			// a book each, as separate processes would have, so only the db keeps them apart
+			book, err := recipe.NewBook(dbFilename)
+
+			if err != nil {
+				t.Fatalf("problem creating book %v", err)
+			}
+
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if err := book.Add(cookme.NewRecipe(fmt.Sprintf("Recipe %d", i))); err != nil {
+					t.Errorf("unexpected error adding recipe %v", err)
+				}
+			}(i)
+		}
+
+		wg.Wait()
+
+		book, _ := recipe.NewBook(dbFilename)
+
+		if got := len(book.Recipes()); got != 10 {
+			t.Errorf("expected 10 recipes but got %d", got)
+		}
+	})
+
+	t.Run("unreadable books aren't overwritten and fail over RPC", func(t *testing.T) {
+		dbFilename := cookme.RandomString() + ".db"
+		defer os.Remove(dbFilename)
+
+		book, _ := recipe.NewBook(dbFilename)
+		recipesBucket, _ := bucket.NewBoltBucket(dbFilename, household.Bucket("recipes", household.Default))
+		recipesBucket.Put([]byte("not json"))
+
+		_, err := book.AddRecipe(context.Background(), &recipe.AddRecipeRequest{Recipe: recipe.ConvertRecipeToGRPC(macAndCheese)})
+		assertStatusCode(t, err, codes.Internal)
+
+		_, err = book.DeleteRecipe(context.Background(), &recipe.DeleteRecipeRequest{Name: macAndCheese.Name})
+		assertStatusCode(t, err, codes.Internal)
+
+		_, err = book.GetRecipes(context.Background(), &recipe.GetRecipesRequest{})
+		assertStatusCode(t, err, codes.Internal)
+
+		if data, _ := recipesBucket.Get(); string(data) != "not json" {
+			t.Errorf("expected the book to be left alone but got %q", data)
+		}
+	})
+}
+
+func TestRecipeSearch(t *testing.T) {
+
+	pasta := cookme.Ingredient{Name: "Pasta"}
+	cheese := cookme.Ingredient{Name: "Cheese"}
+	milk := cookme.Ingredient{Name: "Milk"}
+
+	macAndCheese := cookme.Recipe{Name: "Mac and cheese", Ingredients: cookme.Ingredients{pasta, cheese}, Rating: 3}
+	cheesyMilk := cookme.Recipe{Name: "Cheesy milk", Ingredients: cookme.Ingredients{milk, cheese}, Rating: 1}
+	pastaBake := cookme.Recipe{Name: "Pasta bake", Ingredients: cookme.Ingredients{pasta}, Rating: 5}
+
+	book, cleanup := NewTestRecipeBook(t)
+	defer cleanup()
+
+	book.Add(macAndCheese)
+	book.Add(cheesyMilk)
+	book.Add(pastaBake)
+
+	t.Run("finds recipes by name or ingredient, ignoring case", func(t *testing.T) {
+		got, total, err := book.Find("PASTA", recipe.GetRecipesRequest_ADDED, 0, 0)
+
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		AssertRecipesEqual(t, got, cookme.Recipes{macAndCheese, pastaBake})
+		assertTotal(t, total, 2)
+	})
+
+	t.Run("sorts by name", func(t *testing.T) {
+		got, _, _ := book.Find("", recipe.GetRecipesRequest_NAME, 0, 0)
+		AssertRecipesEqual(t, got, cookme.Recipes{cheesyMilk, macAndCheese, pastaBake})
+	})
+
+	t.Run("sorts by rating, best first", func(t *testing.T) {
+		got, _, _ := book.Find("", recipe.GetRecipesRequest_RATING, 0, 0)
+		AssertRecipesEqual(t, got, cookme.Recipes{pastaBake, macAndCheese, cheesyMilk})
+	})
+
+	t.Run("pages through results over RPC", func(t *testing.T) {
+		var got cookme.Recipes
+		pageToken := ""
+		pages := 0
+
+		for {
+			res, err := book.GetRecipes(context.Background(), &recipe.GetRecipesRequest{
+				Sort:      recipe.GetRecipesRequest_NAME,
+				PageSize:  2,
+				PageToken: pageToken,
+			})
+
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			for _, r := range res.Recipes {
+				got = append(got, cookme.Recipe{Name: r.Name})
+			}
+
+			pages++
+			pageToken = res.NextPageToken
+
+			if pageToken == "" {
+				break
+			}
+		}
+
+		AssertRecipesEqual(t, got, cookme.Recipes{{Name: cheesyMilk.Name}, {Name: macAndCheese.Name}, {Name: pastaBake.Name}})
+
+		if pages != 2 {
+			t.Errorf("got %d pages, want 2", pages)
+		}
+	})
+
+	t.Run("returns everything when no page size is given", func(t *testing.T) {
+		res, _ := book.GetRecipes(context.Background(), &recipe.GetRecipesRequest{})
+
+		if len(res.Recipes) != 3 || res.NextPageToken != "" {
+			t.Errorf("expected all 3 recipes and no next page but got %v", res)
+		}
+	})
+
+	t.Run("invalid page tokens are invalid arguments", func(t *testing.T) {
+		_, err := book.GetRecipes(context.Background(), &recipe.GetRecipesRequest{PageSize: 1, PageToken: "not a token"})
+		assertStatusCode(t, err, codes.InvalidArgument)
+	})
+}
+
+func TestRecipesUsing(t *testing.T) {
+
+	pasta := cookme.Ingredient{Name: "Pasta"}
+	cheese := cookme.Ingredient{Name: "Cheese"}
+	milk := cookme.Ingredient{Name: "Milk"}
+
+	macAndCheese := cookme.NewRecipe("Mac and cheese", pasta, cheese)
+	cheesyMilk := cookme.NewRecipe("Cheesy milk", milk, cheese)
+	pastaBake := cookme.NewRecipe("Pasta bake", pasta)
+
+	t.Run("ranks recipes by how many of the ingredients they use", func(t *testing.T) {
+		book, cleanup := NewTestRecipeBook(t)
+		defer cleanup()
+
+		book.Add(pastaBake)
+		book.Add(cheesyMilk)
+		book.Add(macAndCheese)
+
+		got := book.RecipesUsing("pasta", "CHEESE")
+
+		want := []recipe.Match{
+			{Recipe: macAndCheese, MatchingIngredients: 2},
+			{Recipe: cheesyMilk, MatchingIngredients: 1},
+			{Recipe: pastaBake, MatchingIngredients: 1},
+		}
+
+		assertMatchesEqual(t, got, want)
+	})
+
+	t.Run("deleted recipes are no longer found", func(t *testing.T) {
+		book, cleanup := NewTestRecipeBook(t)
+		defer cleanup()
+
+		book.Add(macAndCheese)
+		book.Add(pastaBake)
+		book.Delete(macAndCheese.Name)
+
+		assertMatchesEqual(t, book.RecipesUsing("pasta", "cheese"), []recipe.Match{{Recipe: pastaBake, MatchingIngredients: 1}})
+	})
+
+	t.Run("rated recipes are found with their rating", func(t *testing.T) {
+		book, cleanup := NewTestRecipeBook(t)
+		defer cleanup()
+
+		book.Add(pastaBake)
+		book.Rate(pastaBake.Name, 5)
+
+		ratedPastaBake := pastaBake
+		ratedPastaBake.Rating = 5
+
+		assertMatchesEqual(t, book.RecipesUsing("pasta"), []recipe.Match{{Recipe: ratedPastaBake, MatchingIngredients: 1}})
+	})
+
+	t.Run("the index is rebuilt when the book is reopened", func(t *testing.T) {
+		dbFilename := cookme.RandomString() + ".db"
+		defer os.Remove(dbFilename)
+
+		book, _ := recipe.NewBook(dbFilename)
+		book.Add(cheesyMilk)
+
+		reopened, err := recipe.NewBook(dbFilename)
+
+		if err != nil {
+			t.Fatalf("problem reopening book %v", err)
+		}
+
+		assertMatchesEqual(t, reopened.RecipesUsing("milk"), []recipe.Match{{Recipe: cheesyMilk, MatchingIngredients: 1}})
+	})
+
+	t.Run("finds recipes using ingredients over RPC", func(t *testing.T) {
+		book, cleanup := NewTestRecipeBook(t)
+		defer cleanup()
+
+		book.Add(macAndCheese)
+
+		res, err := book.FindRecipesUsing(context.Background(), &recipe.FindRecipesUsingRequest{Ingredients: []string{"cheese", "pasta", "milk"}})
+
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		if len(res.Matches) != 1 || res.Matches[0].Recipe.Name != macAndCheese.Name || res.Matches[0].MatchingIngredients != 2 {
+			t.Errorf("expected mac and cheese matching 2 ingredients but got %v", res.Matches)
+		}
+	})
+}
+
+func assertMatchesEqual(t *testing.T, got, want []recipe.Match) {
+	t.Helper()
+	if !cmp.Equal(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func assertTotal(t *testing.T, got, want int) {
+	t.Helper()
+	if got != want {
+		t.Errorf("got total %d, want %d", got, want)
+	}
+}
+
+func assertStatusCode(t *testing.T, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Errorf("got status %v, want %v", got, want)
+	}
+}
+
 func AssertRecipesEqual(t *testing.T, got, want cookme.Recipes) {
 	t.Helper()
 	if !cmp.Equal(got, want) {
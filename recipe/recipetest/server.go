@@ -0,0 +1,80 @@
+// Package recipetest runs the recipe service in process for tests, over bufconn rather than a real network
+package recipetest
+
+import (
+	"github.com/quii/monolith-to-micro/recipe"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Server is a RecipeService listening on bufconn
+type Server struct {
+	listener   *bufconn.Listener
+	grpcServer *grpc.Server
+}
+
+// NewServer serves service on bufconn with the grpc options, e.g. interceptors or credentials
+func NewServer(t testing.TB, service recipe.RecipeServiceServer, options ...grpc.ServerOption) (server *Server, cleanup func()) {
+	t.Helper()
+
+	server = &Server{
+		listener:   bufconn.Listen(1024 * 1024),
+		grpcServer: grpc.NewServer(options...),
+	}
+
+	recipe.RegisterRecipeServiceServer(server.grpcServer, service)
+	go server.grpcServer.Serve(server.listener)
+
+	return server, server.grpcServer.Stop
+}
+
+// NewBookServer serves a Book on a database in a temporary directory, returning the book so tests can arrange and
+// inspect its recipes. Cleaning up removes the directory
+func NewBookServer(t testing.TB, options ...grpc.ServerOption) (server *Server, book *recipe.Book, cleanup func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "recipetest")
+
+	if err != nil {
+		t.Fatalf("problem creating temporary directory %+v", err)
+	}
+
+	book, err = recipe.NewBook(filepath.Join(dir, "recipes.db"))
+
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("problem creating db %+v", err)
+	}
+
+	server, stop := NewServer(t, book, options...)
+
+	return server, book, func() {
+		stop()
+		os.RemoveAll(dir)
+	}
+}
+
+// Dialer is the ClientOption for connecting to the server
+func (s *Server) Dialer() recipe.ClientOption {
+	return recipe.WithDialOptions(grpc.WithDialer(func(string, time.Duration) (net.Conn, error) {
+		return s.listener.Dial()
+	}))
+}
+
+// NewClient connects a client to the server. It doesn't retry unless options say otherwise, so tests fail fast
+func (s *Server) NewClient(t testing.TB, options ...recipe.ClientOption) (client *recipe.Client, cleanup func()) {
+	t.Helper()
+
+	options = append([]recipe.ClientOption{recipe.WithRetries(0, time.Millisecond, time.Millisecond)}, options...)
+	// the dialer ignores the address, but it's the name TLS verifies the server's certificate against
+	client, closeClient := recipe.NewClient("localhost", append(options, s.Dialer())...)
+
+	return client, func() {
+		closeClient()
+	}
+}
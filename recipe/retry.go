@@ -0,0 +1,64 @@
+package recipe
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// idempotentMethods are safe to retry because calling them twice has the same effect as calling them once
+var idempotentMethods = map[string]bool{
+	"/RecipeService/GetRecipes":       true,
+	"/RecipeService/DeleteRecipe":     true,
+	"/RecipeService/RateRecipe":       true,
+	"/RecipeService/SetFavourite":     true,
+	"/RecipeService/FindRecipesUsing": true,
+}
+
+// retryPolicy describes how calls to the recipe server are timed out and retried
+type retryPolicy struct {
+	timeout        time.Duration
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// backoff returns how long to wait before the given retry, doubling each time up to the maximum
+func (p retryPolicy) backoff(retry int) time.Duration {
+	backoff := p.initialBackoff
+	for i := 0; i < retry && backoff < p.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.maxBackoff {
+		return p.maxBackoff
+	}
+	return backoff
+}
+
+// interceptor gives every attempt of a call its own deadline and retries idempotent calls while the server is unavailable
+func (p retryPolicy) interceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	for retry := 0; ; retry++ {
+		err := p.invoke(ctx, method, req, reply, cc, invoker, opts...)
+
+		if err == nil || !idempotentMethods[method] || status.Code(err) != codes.Unavailable || retry >= p.maxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(p.backoff(retry)):
+		}
+	}
+}
+
+func (p retryPolicy) invoke(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
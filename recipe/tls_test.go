@@ -0,0 +1,83 @@
+package recipe_test
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/certs"
+	"github.com/quii/monolith-to-micro/certs/certstest"
+	"github.com/quii/monolith-to-micro/recipe"
+	"github.com/quii/monolith-to-micro/recipe/recipetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"path/filepath"
+	"testing"
+)
+
+func TestClientOverTLS(t *testing.T) {
+
+	macAndCheese := cookme.NewRecipe("Mac and cheese", cookme.Ingredient{Name: "Pasta"}, cookme.Ingredient{Name: "Cheese"})
+
+	dir, cleanupCerts := certstest.NewCerts(t)
+	defer cleanupCerts()
+
+	file := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+
+	cases := []struct {
+		name       string
+		clientCA   string
+		clientCert string
+		clientKey  string
+		want       codes.Code
+	}{
+		{name: "with a client certificate", clientCA: file(certs.CAFile), clientCert: file(certs.ClientFile), clientKey: file(certs.ClientKeyFile), want: codes.OK},
+		{name: "without a client certificate", clientCA: file(certs.CAFile), want: codes.Unavailable},
+		{name: "not trusting the server", clientCert: file(certs.ClientFile), clientKey: file(certs.ClientKeyFile), want: codes.Unavailable},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			book, cleanupBook := NewTestRecipeBook(t)
+			defer cleanupBook()
+			book.Add(macAndCheese)
+
+			serverTLS, err := certs.ServerConfig(file(certs.ServerFile), file(certs.ServerKeyFile), file(certs.CAFile))
+
+			if err != nil {
+				t.Fatalf("problem loading server certificates %v", err)
+			}
+
+			clientTLS, err := certs.ClientConfig(c.clientCA, c.clientCert, c.clientKey)
+
+			if err != nil {
+				t.Fatalf("problem loading client certificates %v", err)
+			}
+
+			client, cleanup := newTestTLSClient(t, book, serverTLS, clientTLS)
+			defer cleanup()
+
+			got, err := client.RecipesContext(context.Background())
+
+			assertStatusCode(t, err, c.want)
+
+			if c.want == codes.OK {
+				AssertRecipesEqual(t, got, cookme.Recipes{macAndCheese})
+			}
+		})
+	}
+}
+
+func newTestTLSClient(t *testing.T, book *recipe.Book, serverTLS, clientTLS *tls.Config) (client *recipe.Client, cleanup func()) {
+	t.Helper()
+
+	server, stop := recipetest.NewServer(t, book, grpc.Creds(credentials.NewTLS(serverTLS)))
+	client, closeClient := server.NewClient(t, recipe.WithTLS(clientTLS))
+
+	return client, func() {
+		closeClient()
+		stop()
+	}
+}
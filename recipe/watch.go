@@ -0,0 +1,83 @@
+package recipe
+
+import (
+	"github.com/quii/monolith-to-micro"
+	"sync"
+)
+
+// watchBuffer is how many events a watcher can fall behind by before it is disconnected
+const watchBuffer = 32
+
+// watchers fans out events to everyone watching a Book without ever blocking on a slow watcher
+type watchers struct {
+	mu       sync.Mutex
+	buffer   int
+	channels map[chan *RecipeEvent]bool
+	closed   bool
+}
+
+func newWatchers(buffer int) *watchers {
+	return &watchers{buffer: buffer, channels: make(map[chan *RecipeEvent]bool)}
+}
+
+func (w *watchers) subscribe() (<-chan *RecipeEvent, func()) {
+	events := make(chan *RecipeEvent, w.buffer)
+
+	w.mu.Lock()
+	if w.closed {
+		close(events)
+	} else {
+		w.channels[events] = true
+	}
+	w.mu.Unlock()
+
+	return events, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.remove(events)
+	}
+}
+
+func (w *watchers) publish(eventType RecipeEvent_EventType, recipes ...cookme.Recipe) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, r := range recipes {
+		event := &RecipeEvent{Type: eventType, Recipe: ConvertRecipeToGRPC(r)}
+
+		for events := range w.channels {
+			select {
+			case events <- event:
+			default:
+				w.remove(events)
+			}
+		}
+	}
+}
+
+// closeAll closes every watcher's channel, and the channels of any watchers who subscribe afterwards, so they all stop
+func (w *watchers) closeAll() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+
+	for events := range w.channels {
+		w.remove(events)
+	}
+}
+
+// isClosed tells you if the channels were closed by closeAll rather than because a watcher fell behind
+func (w *watchers) isClosed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closed
+}
+
+// remove closes and forgets a watcher's channel, it must be called with mu held
+func (w *watchers) remove(events chan *RecipeEvent) {
+	if w.channels[events] {
+		delete(w.channels, events)
+		close(events)
+	}
+}
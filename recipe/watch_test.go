@@ -0,0 +1,212 @@
+package recipe_test
+
+import (
+	"context"
+	"github.com/golang/protobuf/proto"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/recipe"
+	"google.golang.org/grpc/codes"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatchRecipes(t *testing.T) {
+
+	macAndCheese := cookme.NewRecipe("Mac and cheese", cookme.Ingredient{Name: "Pasta"}, cookme.Ingredient{Name: "Cheese"})
+
+	grpcMacAndCheese := &recipe.Recipe{Name: macAndCheese.Name, Ingredients: []*recipe.Ingredient{{Name: "Pasta"}, {Name: "Cheese"}}}
+	ratedMacAndCheese := &recipe.Recipe{Name: macAndCheese.Name, Ingredients: grpcMacAndCheese.Ingredients, Rating: 4}
+
+	wantEvents := []*recipe.RecipeEvent{
+		{Type: recipe.RecipeEvent_ADDED, Recipe: grpcMacAndCheese},
+		{Type: recipe.RecipeEvent_UPDATED, Recipe: ratedMacAndCheese},
+		{Type: recipe.RecipeEvent_DELETED, Recipe: ratedMacAndCheese},
+	}
+
+	changeBook := func(book *recipe.Book) {
+		book.Add(macAndCheese)
+		book.Rate(macAndCheese.Name, 4)
+		book.Delete(macAndCheese.Name)
+	}
+
+	t.Run("streams added, updated and deleted recipes to every watcher", func(t *testing.T) {
+		book, cleanupBook := NewTestRecipeBook(t)
+		defer cleanupBook()
+
+		client, cleanup := newTestClient(t, book, &flakyServer{})
+		defer cleanup()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		const watcherCount = 3
+		var streams []recipe.RecipeService_WatchRecipesClient
+
+		for i := 0; i < watcherCount; i++ {
+			stream, err := client.WatchContext(ctx)
+
+			if err != nil {
+				t.Fatalf("problem watching recipes %v", err)
+			}
+
+			streams = append(streams, stream)
+		}
+
+		changeBook(book)
+
+		var wg sync.WaitGroup
+
+		for _, stream := range streams {
+			wg.Add(1)
+			go func(stream recipe.RecipeService_WatchRecipesClient) {
+				defer wg.Done()
+				for _, want := range wantEvents {
+					got, err := stream.Recv()
+
+					if err != nil {
+						t.Errorf("problem receiving event %v", err)
+						return
+					}
+
+					if !proto.Equal(got, want) {
+						t.Errorf("got event %v, want %v", got, want)
+					}
+				}
+			}(stream)
+		}
+
+		wg.Wait()
+	})
+
+	t.Run("slow watchers are disconnected without holding up the book or other watchers", func(t *testing.T) {
+		book, cleanupBook := NewTestRecipeBook(t)
+		defer cleanupBook()
+
+		slow, stopSlow := book.Watch()
+		defer stopSlow()
+
+		fast, stopFast := book.Watch()
+		defer stopFast()
+
+		for i := 0; i < 100; i++ {
+			book.Add(cookme.NewRecipe(cookme.RandomString()))
+
+			if _, ok := <-fast; !ok {
+				t.Fatalf("fast watcher was disconnected after %d events", i)
+			}
+		}
+
+		buffered := 0
+		for range slow {
+			buffered++
+		}
+
+		if buffered == 0 || buffered >= 100 {
+			t.Errorf("expected the slow watcher to be cut off after its buffer filled, but it received %d events", buffered)
+		}
+	})
+
+	t.Run("signals once watching starts and then for each change", func(t *testing.T) {
+		book, cleanupBook := NewTestRecipeBook(t)
+		defer cleanupBook()
+
+		client, cleanup := newTestClient(t, book, &flakyServer{})
+		defer cleanup()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		changes := client.Changes(ctx)
+		assertSignalled(t, changes)
+
+		book.Add(macAndCheese)
+		assertSignalled(t, changes)
+
+		cancel()
+
+		for {
+			select {
+			case _, ok := <-changes:
+				if !ok {
+					return
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("expected changes to be closed once ctx is done")
+			}
+		}
+	})
+
+	t.Run("watchers are told the service is going away when it stops watching", func(t *testing.T) {
+		book, cleanupBook := NewTestRecipeBook(t)
+		defer cleanupBook()
+
+		client, cleanup := newTestClient(t, book, &flakyServer{})
+		defer cleanup()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		stream, err := client.WatchContext(ctx)
+
+		if err != nil {
+			t.Fatalf("problem watching recipes %v", err)
+		}
+
+		book.StopWatching()
+
+		_, err = stream.Recv()
+		assertStatusCode(t, err, codes.Unavailable)
+
+		late, err := client.WatchContext(ctx)
+
+		if err == nil {
+			_, err = late.Recv()
+		}
+
+		assertStatusCode(t, err, codes.Unavailable)
+	})
+
+	t.Run("slow watchers are told they fell behind over RPC", func(t *testing.T) {
+		book, cleanupBook := NewTestRecipeBook(t)
+		defer cleanupBook()
+
+		server := &flakyServer{holdSends: make(chan struct{})}
+		client, cleanup := newTestClient(t, book, server)
+		defer cleanup()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		stream, err := client.WatchContext(ctx)
+
+		if err != nil {
+			t.Fatalf("problem watching recipes %v", err)
+		}
+
+		for i := 0; i < 100; i++ {
+			book.Add(cookme.NewRecipe(cookme.RandomString()))
+		}
+
+		close(server.holdSends)
+
+		for err == nil {
+			_, err = stream.Recv()
+		}
+
+		assertStatusCode(t, err, codes.ResourceExhausted)
+	})
+}
+
+func assertSignalled(t *testing.T, changes <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case _, ok := <-changes:
+		if !ok {
+			t.Fatal("expected a signal but changes were closed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a signal")
+	}
+}
@@ -0,0 +1,103 @@
+package cookme
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+const neutralRating = 3
+
+// RecipeScorer scores how good a suggestion a recipe is, recipes with a higher total score are suggested first
+type RecipeScorer interface {
+	Score(recipe Recipe) float64
+}
+
+// RecipeScorerFunc allows you to implement RecipeScorer with a func
+type RecipeScorerFunc func(recipe Recipe) float64
+
+// Score returns the score given by f
+func (f RecipeScorerFunc) Score(recipe Recipe) float64 {
+	return f(recipe)
+}
+
+// RecipeDemoter is a RecipeScorer which can put recipes after every recipe it doesn't demote, whatever their scores
+type RecipeDemoter interface {
+	RecipeScorer
+	Demote(recipe Recipe) bool
+}
+
+// SortByScore sorts _in place_ the recipes by their total score from the scorers, keeping the order of recipes with the same score.
+// Recipes demoted by any of the scorers come after the rest, sorted by score among themselves
+func (recipes Recipes) SortByScore(scorers ...RecipeScorer) Recipes {
+	if len(scorers) == 0 {
+		return recipes
+	}
+
+	scored := make([]scoredRecipe, len(recipes))
+
+	for n, recipe := range recipes {
+		scored[n].recipe = recipe
+
+		for _, scorer := range scorers {
+			scored[n].score += scorer.Score(recipe)
+
+			if demoter, ok := scorer.(RecipeDemoter); ok && demoter.Demote(recipe) {
+				scored[n].demoted = true
+			}
+		}
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].demoted != scored[j].demoted {
+			return scored[j].demoted
+		}
+		return scored[i].score > scored[j].score
+	})
+
+	for n, s := range scored {
+		recipes[n] = s.recipe
+	}
+
+	return recipes
+}
+
+// scoredRecipe keeps a recipe with its score while sorting, so recipes with the same name can score differently
+type scoredRecipe struct {
+	recipe  Recipe
+	score   float64
+	demoted bool
+}
+
+// ScoreByRating is a RecipeScorer which scores recipes up to weight by their rating, unrated recipes count as average
+func ScoreByRating(weight float64) RecipeScorer {
+	return RecipeScorerFunc(func(recipe Recipe) float64 {
+		rating := recipe.Rating
+		if rating == 0 {
+			rating = neutralRating
+		}
+		return weight * float64(rating) / MaxRating
+	})
+}
+
+// ScoreByExpiry is a RecipeScorer which scores recipes up to weight, the sooner the first of their ingredients expires after now the higher the score
+func ScoreByExpiry(ingredients PerishableIngredients, now time.Time, weight float64) RecipeScorer {
+	return RecipeScorerFunc(func(recipe Recipe) float64 {
+		var soonest time.Time
+
+		for _, required := range recipe.Ingredients {
+			for _, i := range ingredients {
+				if !i.HasExpired(now) && strings.EqualFold(i.Name, required.Name) && (soonest.IsZero() || i.ExpirationDate.Before(soonest)) {
+					soonest = i.ExpirationDate
+				}
+			}
+		}
+
+		if soonest.IsZero() {
+			return 0
+		}
+
+		days := soonest.Sub(now).Hours() / 24
+		return weight / (1 + days)
+	})
+}
@@ -0,0 +1,310 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// WriterExporter writes each span as a line of JSON, for reading locally
+type WriterExporter struct {
+	mu    sync.Mutex
+	out   io.Writer
+	close func() error
+}
+
+// NewWriterExporter writes spans to out, e.g. os.Stderr
+func NewWriterExporter(out io.Writer) *WriterExporter {
+	return &WriterExporter{out: out, close: func() error { return nil }}
+}
+
+// NewFileExporter appends spans to the file, creating it if needed
+func NewFileExporter(filename string) (*WriterExporter, error) {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+
+	if err != nil {
+		return nil, fmt.Errorf("problem opening trace file %s, %v", filename, err)
+	}
+
+	return &WriterExporter{out: f, close: f.Close}, nil
+}
+
+// ExportSpan writes the span in the OTLP JSON encoding, along with its service
+func (w *WriterExporter) ExportSpan(span SpanData) {
+	line, _ := json.Marshal(struct {
+		Service string `json:"service"`
+		otlpSpan
+	}{span.Service, otlpSpanOf(span)})
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.out.Write(append(line, '\n'))
+}
+
+// Close closes the file, if writing to one
+func (w *WriterExporter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.close()
+}
+
+// DefaultOTLPEndpoint is where an OpenTelemetry collector takes traces over HTTP by default
+const DefaultOTLPEndpoint = "http://localhost:4318/v1/traces"
+
+const (
+	otlpBatchSize = 512
+	otlpMaxQueue  = 4096
+)
+
+// OTLPExporter sends spans in batches to an OpenTelemetry collector, as OTLP JSON over HTTP
+type OTLPExporter struct {
+	endpoint string
+	client   *http.Client
+
+	mu    sync.Mutex
+	queue []SpanData
+
+	flush chan struct{}
+	done  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewOTLPExporter sends spans to the collector's traces endpoint every interval, or sooner once a batch is full
+func NewOTLPExporter(endpoint string, interval time.Duration) *OTLPExporter {
+	e := &OTLPExporter{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		flush:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+
+	e.wg.Add(1)
+	go e.run(interval)
+
+	return e
+}
+
+// ExportSpan queues the span to be sent, dropping the oldest if the collector can't keep up
+func (e *OTLPExporter) ExportSpan(span SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.queue) >= otlpMaxQueue {
+		e.queue = e.queue[1:]
+	}
+
+	e.queue = append(e.queue, span)
+
+	if len(e.queue) >= otlpBatchSize {
+		select {
+		case e.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Close sends any queued spans and stops
+func (e *OTLPExporter) Close() error {
+	close(e.done)
+	e.wg.Wait()
+	return e.send()
+}
+
+func (e *OTLPExporter) run(interval time.Duration) {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-ticker.C:
+		case <-e.flush:
+		}
+
+		// failures are dropped rather than retried, tracing mustn't hold up the service
+		e.send()
+	}
+}
+
+func (e *OTLPExporter) send() error {
+	e.mu.Lock()
+	spans := e.queue
+	e.queue = nil
+	e.mu.Unlock()
+
+	for len(spans) > 0 {
+		n := len(spans)
+		if n > otlpBatchSize {
+			n = otlpBatchSize
+		}
+
+		if err := e.post(spans[:n]); err != nil {
+			return err
+		}
+
+		spans = spans[n:]
+	}
+
+	return nil
+}
+
+func (e *OTLPExporter) post(spans []SpanData) error {
+	body, err := json.Marshal(otlpRequestOf(spans))
+
+	if err != nil {
+		return err
+	}
+
+	res, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(body))
+
+	if err != nil {
+		return fmt.Errorf("problem sending spans to %s, %v", e.endpoint, err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("problem sending spans to %s, got status %d", e.endpoint, res.StatusCode)
+	}
+
+	return nil
+}
+
+// the OTLP JSON encoding, see https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              Kind            `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+const otlpStatusError = 2
+
+func otlpRequestOf(spans []SpanData) otlpRequest {
+	var req otlpRequest
+	byService := make(map[string]int)
+
+	for _, span := range spans {
+		i, exists := byService[span.Service]
+
+		if !exists {
+			i = len(req.ResourceSpans)
+			byService[span.Service] = i
+			req.ResourceSpans = append(req.ResourceSpans, otlpResourceSpans{
+				Resource:   otlpResource{Attributes: []otlpAttribute{otlpAttributeOf(Attribute{Key: "service.name", Value: span.Service})}},
+				ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "github.com/quii/monolith-to-micro/tracing"}}},
+			})
+		}
+
+		scope := &req.ResourceSpans[i].ScopeSpans[0]
+		scope.Spans = append(scope.Spans, otlpSpanOf(span))
+	}
+
+	return req
+}
+
+func otlpSpanOf(span SpanData) otlpSpan {
+	s := otlpSpan{
+		TraceID:           span.SpanContext.TraceID.String(),
+		SpanID:            span.SpanContext.SpanID.String(),
+		Name:              span.Name,
+		Kind:              span.Kind,
+		StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+	}
+
+	if span.Parent != (SpanID{}) {
+		s.ParentSpanID = span.Parent.String()
+	}
+
+	for _, a := range span.Attributes {
+		s.Attributes = append(s.Attributes, otlpAttributeOf(a))
+	}
+
+	if span.Error != "" {
+		s.Status = otlpStatus{Code: otlpStatusError, Message: span.Error}
+	}
+
+	return s
+}
+
+func otlpAttributeOf(a Attribute) otlpAttribute {
+	var v otlpValue
+
+	switch value := a.Value.(type) {
+	case string:
+		v.StringValue = &value
+	case bool:
+		v.BoolValue = &value
+	case int:
+		s := strconv.Itoa(value)
+		v.IntValue = &s
+	case int32:
+		s := strconv.FormatInt(int64(value), 10)
+		v.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(value, 10)
+		v.IntValue = &s
+	case float64:
+		v.DoubleValue = &value
+	default:
+		s := fmt.Sprint(value)
+		v.StringValue = &s
+	}
+
+	return otlpAttribute{Key: a.Key, Value: v}
+}
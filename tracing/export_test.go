@@ -0,0 +1,109 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/quii/monolith-to-micro/tracing"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriterExporter(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := tracing.NewTracer("cookme", tracing.NewWriterExporter(&buf))
+
+	_, span := tracer.Start(context.Background(), "ListRecipes", "recipes", 3)
+	span.End()
+
+	var got struct {
+		Service string
+		TraceID string
+		Name    string
+	}
+
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("problem decoding %q, %v", buf.String(), err)
+	}
+
+	if got.Service != "cookme" || got.Name != "ListRecipes" || got.TraceID != span.SpanContext().TraceID.String() {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	requests := make(chan []byte, 1)
+
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s %s", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		requests <- body
+	}))
+	defer collector.Close()
+
+	exporter := tracing.NewOTLPExporter(collector.URL+"/v1/traces", time.Hour)
+	tracer := tracing.NewTracer("recipes", exporter)
+
+	ctx, parent := tracer.Start(context.Background(), "/RecipeService/GetRecipes")
+	_, child := tracer.Start(ctx, "bolt.read", "db.bytes", 120)
+	child.End()
+	parent.End()
+
+	// closing sends the queued spans
+	if err := exporter.Close(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	var req struct {
+		ResourceSpans []struct {
+			Resource struct {
+				Attributes []struct {
+					Key   string
+					Value struct{ StringValue string }
+				}
+			}
+			ScopeSpans []struct {
+				Spans []struct {
+					TraceID      string
+					SpanID       string
+					ParentSpanID string
+					Name         string
+					Attributes   []struct {
+						Key   string
+						Value struct{ IntValue string }
+					}
+				}
+			}
+		}
+	}
+
+	body := <-requests
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		t.Fatalf("problem decoding %s, %v", body, err)
+	}
+
+	if len(req.ResourceSpans) != 1 || req.ResourceSpans[0].Resource.Attributes[0].Value.StringValue != "recipes" {
+		t.Fatalf("expected spans for the recipes service but got %s", body)
+	}
+
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+
+	if len(spans) != 2 || spans[0].Name != "bolt.read" || spans[0].ParentSpanID != spans[1].SpanID || spans[0].TraceID != spans[1].TraceID {
+		t.Fatalf("expected bolt.read to be a child of the call but got %s", body)
+	}
+
+	if attr := spans[0].Attributes[0]; attr.Key != "db.bytes" || attr.Value.IntValue != "120" {
+		t.Errorf("expected an int attribute but got %+v", attr)
+	}
+
+	if !strings.Contains(string(body), `"startTimeUnixNano"`) {
+		t.Errorf("expected span times in %s", body)
+	}
+}
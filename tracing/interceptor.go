@@ -0,0 +1,144 @@
+package tracing
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net/http"
+)
+
+// UnaryServerInterceptor starts a server span for every call, joining the caller's trace if its metadata has a
+// traceparent. A nil tracer uses Default
+func UnaryServerInterceptor(tracer *Tracer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := orDefault(tracer).start(ctx, info.FullMethod, KindServer, incoming(ctx), rpcAttributes(info.FullMethod)...)
+		defer span.End()
+
+		res, err := handler(ctx, req)
+
+		endRPC(span, err)
+		return res, err
+	}
+}
+
+// StreamServerInterceptor starts a server span for every stream like UnaryServerInterceptor, ending once it has finished
+func StreamServerInterceptor(tracer *Tracer) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := orDefault(tracer).start(stream.Context(), info.FullMethod, KindServer, incoming(stream.Context()), rpcAttributes(info.FullMethod)...)
+		defer span.End()
+
+		err := handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+
+		endRPC(span, err)
+		return err
+	}
+}
+
+// UnaryClientInterceptor starts a client span for every call, a child of the span in ctx, and passes it on to the
+// server as a traceparent
+func UnaryClientInterceptor(tracer *Tracer) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, span := orDefault(tracer).start(ctx, method, KindClient, SpanContextFromContext(ctx), rpcAttributes(method)...)
+		defer span.End()
+
+		err := invoker(outgoing(ctx), method, req, reply, cc, opts...)
+
+		endRPC(span, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor starts a client span for opening every stream, passing it on like UnaryClientInterceptor
+func StreamClientInterceptor(tracer *Tracer) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, span := orDefault(tracer).start(ctx, method, KindClient, SpanContextFromContext(ctx), rpcAttributes(method)...)
+		defer span.End()
+
+		stream, err := streamer(outgoing(ctx), desc, cc, method, opts...)
+
+		endRPC(span, err)
+		return stream, err
+	}
+}
+
+// Handler starts a server span for every HTTP request, joining the caller's trace if it sent a traceparent header
+func Handler(tracer *Tracer, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parent, _ := ParseTraceparent(r.Header.Get(TraceparentKey))
+
+		ctx, span := orDefault(tracer).start(r.Context(), r.Method+" "+r.URL.Path, KindServer, parent,
+			"http.method", r.Method,
+			"http.target", r.URL.RequestURI(),
+		)
+		defer span.End()
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes("http.status_code", recorder.status)
+
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetError(errorString(http.StatusText(recorder.status)))
+		}
+	})
+}
+
+type errorString string
+
+func (e errorString) Error() string {
+	return string(e)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func rpcAttributes(method string) []interface{} {
+	return []interface{}{"rpc.system", "grpc", "rpc.method", method}
+}
+
+func endRPC(span *Span, err error) {
+	span.SetAttributes("rpc.grpc.status_code", int(status.Code(err)))
+	span.SetError(err)
+}
+
+func incoming(ctx context.Context) SpanContext {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if values := md.Get(TraceparentKey); len(values) > 0 {
+		sc, _ := ParseTraceparent(values[0])
+		return sc
+	}
+
+	return SpanContext{}
+}
+
+func outgoing(ctx context.Context) context.Context {
+	if sc := SpanContextFromContext(ctx); sc.IsValid() {
+		return metadata.AppendToOutgoingContext(ctx, TraceparentKey, sc.Traceparent())
+	}
+	return ctx
+}
+
+func orDefault(tracer *Tracer) *Tracer {
+	if tracer == nil {
+		return Default()
+	}
+	return tracer
+}
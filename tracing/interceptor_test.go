@@ -0,0 +1,92 @@
+package tracing_test
+
+import (
+	"context"
+	"github.com/quii/monolith-to-micro/tracing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestInterceptors(t *testing.T) {
+	exporter := &recordingExporter{}
+	clientTracer := tracing.NewTracer("cookme", exporter)
+	serverTracer := tracing.NewTracer("recipes", exporter)
+
+	client := tracing.UnaryClientInterceptor(clientTracer)
+	server := tracing.UnaryServerInterceptor(serverTracer)
+
+	var handled tracing.SpanContext
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		handled = tracing.SpanContextFromContext(ctx)
+		return nil, status.Error(codes.NotFound, "no such recipe")
+	}
+
+	// the invoker stands in for the network, handing the outgoing metadata to the server
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		_, err := server(metadata.NewIncomingContext(ctx, md), req, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	ctx, root := clientTracer.Start(context.Background(), "cookme")
+	client(ctx, "/RecipeService/RateRecipe", nil, nil, nil, invoker)
+	root.End()
+
+	spans := exporter.Spans()
+
+	if len(spans) != 3 {
+		t.Fatalf("expected server, client and root spans but got %+v", spans)
+	}
+
+	serverSpan, clientSpan := spans[0], spans[1]
+
+	if serverSpan.Kind != tracing.KindServer || serverSpan.Service != "recipes" || serverSpan.Parent != clientSpan.SpanContext.SpanID {
+		t.Errorf("expected a server span that's a child of the client span but got %+v", serverSpan)
+	}
+
+	if clientSpan.Kind != tracing.KindClient || clientSpan.Parent != root.SpanContext().SpanID {
+		t.Errorf("expected a client span that's a child of the root span but got %+v", clientSpan)
+	}
+
+	if serverSpan.SpanContext.TraceID != root.SpanContext().TraceID || handled != serverSpan.SpanContext {
+		t.Errorf("expected the handler to be in the server span of the root's trace but got %+v", handled)
+	}
+
+	if serverSpan.Error != "rpc error: code = NotFound desc = no such recipe" {
+		t.Errorf("expected the server span to have failed but got %q", serverSpan.Error)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	exporter := &recordingExporter{}
+
+	handler := tracing.Handler(tracing.NewTracer("recipes", exporter), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/recipes", nil)
+	req.Header.Set(tracing.TraceparentKey, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.Spans()
+
+	if len(spans) != 1 {
+		t.Fatalf("expected one span but got %+v", spans)
+	}
+
+	span := spans[0]
+
+	if span.Name != "GET /recipes" || span.SpanContext.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || span.Parent.String() != "00f067aa0ba902b7" {
+		t.Errorf("expected a span joining the caller's trace but got %+v", span)
+	}
+
+	if span.Error == "" {
+		t.Error("expected the span to have failed")
+	}
+}
@@ -0,0 +1,265 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceID identifies every span in a trace
+type TraceID [16]byte
+
+// SpanID identifies a span within its trace
+type SpanID [8]byte
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// SpanContext is what's passed between services for their spans to join the same trace
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid says whether the span context identifies a span
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// TraceparentKey is the gRPC metadata, and HTTP header, carrying the span context as a W3C traceparent
+const TraceparentKey = "traceparent"
+
+// Traceparent formats the span context as a W3C traceparent, e.g. 00-<trace id>-<span id>-01
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceparent reads a W3C traceparent
+func ParseTraceparent(s string) (SpanContext, error) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(s), "-")
+
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, fmt.Errorf("invalid traceparent %q", s)
+	}
+
+	if parts[0] == "00" && len(parts) != 4 {
+		return sc, fmt.Errorf("invalid traceparent %q", s)
+	}
+
+	flags, err := hex.DecodeString(parts[3])
+	_, traceErr := hex.Decode(sc.TraceID[:], []byte(parts[1]))
+	_, spanErr := hex.Decode(sc.SpanID[:], []byte(parts[2]))
+
+	if err != nil || traceErr != nil || spanErr != nil || !sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("invalid traceparent %q", s)
+	}
+
+	sc.Sampled = flags[0]&1 == 1
+	return sc, nil
+}
+
+// Kind says what a span is doing, as OpenTelemetry numbers them
+type Kind int
+
+const (
+	// KindInternal spans are work within a service
+	KindInternal Kind = 1
+	// KindServer spans are handling a call from another service
+	KindServer Kind = 2
+	// KindClient spans are calling another service
+	KindClient Kind = 3
+)
+
+// Attribute is a key and value describing a span
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// SpanData is what's exported about a finished span
+type SpanData struct {
+	Service     string
+	Name        string
+	Kind        Kind
+	SpanContext SpanContext
+	Parent      SpanID
+	Start       time.Time
+	End         time.Time
+	Attributes  []Attribute
+	Error       string
+}
+
+// Span times some work within a trace. A nil Span records nothing so tracing can be left off
+type Span struct {
+	exporter Exporter
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SpanContext is the span's position in its trace, for passing on to other services
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.data.SpanContext
+}
+
+// SetAttributes adds alternating keys and values to the span
+func (s *Span) SetAttributes(keyValues ...interface{}) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		s.data.Attributes = append(s.data.Attributes, Attribute{Key: fmt.Sprint(keyValues[i]), Value: keyValues[i+1]})
+	}
+}
+
+// SetError marks the span as failed, unless err is nil
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Error = err.Error()
+}
+
+// End finishes the span and exports it if it was sampled, only the first call counts
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if data.SpanContext.Sampled {
+		s.exporter.ExportSpan(data)
+	}
+}
+
+// Exporter sends finished spans somewhere to be looked at
+type Exporter interface {
+	ExportSpan(span SpanData)
+	Close() error
+}
+
+// Tracer starts spans for a service and exports them when they end
+type Tracer struct {
+	service  string
+	exporter Exporter
+}
+
+// NewTracer returns a Tracer exporting the spans of service to exporter. A nil exporter turns tracing off
+func NewTracer(service string, exporter Exporter) *Tracer {
+	return &Tracer{service: service, exporter: exporter}
+}
+
+// Start begins a span of work within the service, a child of the span in ctx if there is one. End it when the work
+// is done and use the returned context for the work so its spans are children of this one
+func (t *Tracer) Start(ctx context.Context, name string, keyValues ...interface{}) (context.Context, *Span) {
+	return t.start(ctx, name, KindInternal, SpanContextFromContext(ctx), keyValues...)
+}
+
+func (t *Tracer) start(ctx context.Context, name string, kind Kind, parent SpanContext, keyValues ...interface{}) (context.Context, *Span) {
+	if t == nil || t.exporter == nil {
+		return ctx, nil
+	}
+
+	sc := SpanContext{TraceID: parent.TraceID, Sampled: parent.Sampled}
+
+	if !parent.IsValid() {
+		rand.Read(sc.TraceID[:])
+		sc.Sampled = true
+	}
+
+	rand.Read(sc.SpanID[:])
+
+	span := &Span{exporter: t.exporter, data: SpanData{
+		Service:     t.service,
+		Name:        name,
+		Kind:        kind,
+		SpanContext: sc,
+		Parent:      parent.SpanID,
+		Start:       time.Now(),
+	}}
+	span.SetAttributes(keyValues...)
+
+	return ContextWithSpanContext(ctx, sc), span
+}
+
+// Close flushes any spans waiting to be exported
+func (t *Tracer) Close() error {
+	if t == nil || t.exporter == nil {
+		return nil
+	}
+	return t.exporter.Close()
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns a context whose spans are children of sc
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context in ctx, which isn't valid if there isn't one
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultTracer = NewTracer("cookme", nil)
+)
+
+// Default is the Tracer used by Start, it doesn't record anything until SetDefault is called
+func Default() *Tracer {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultTracer
+}
+
+// SetDefault changes the Tracer used by Start
+func SetDefault(tracer *Tracer) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultTracer = tracer
+}
+
+// Start begins a span with the Default tracer
+func Start(ctx context.Context, name string, keyValues ...interface{}) (context.Context, *Span) {
+	return Default().Start(ctx, name, keyValues...)
+}
@@ -0,0 +1,135 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"github.com/quii/monolith-to-micro/tracing"
+	"sync"
+	"testing"
+)
+
+func TestTraceparent(t *testing.T) {
+
+	t.Run("round trips", func(t *testing.T) {
+		header := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+		sc, err := tracing.ParseTraceparent(header)
+
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		if !sc.Sampled || sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" {
+			t.Errorf("got %+v from %s", sc, header)
+		}
+
+		if got := sc.Traceparent(); got != header {
+			t.Errorf("got %q, want %q", got, header)
+		}
+	})
+
+	t.Run("rejects invalid headers", func(t *testing.T) {
+		for _, header := range []string{
+			"",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+			"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01",
+			"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		} {
+			if _, err := tracing.ParseTraceparent(header); err == nil {
+				t.Errorf("expected an error for %q", header)
+			}
+		}
+	})
+}
+
+func TestTracer(t *testing.T) {
+
+	t.Run("children join their parent's trace", func(t *testing.T) {
+		exporter := &recordingExporter{}
+		tracer := tracing.NewTracer("test", exporter)
+
+		ctx, parent := tracer.Start(context.Background(), "parent")
+		_, child := tracer.Start(ctx, "child", "recipes", 2)
+		child.SetError(errors.New("no such recipe"))
+		child.End()
+		parent.End()
+
+		spans := exporter.Spans()
+
+		if len(spans) != 2 {
+			t.Fatalf("expected 2 spans but got %d", len(spans))
+		}
+
+		got, want := spans[0], parent.SpanContext()
+
+		if got.SpanContext.TraceID != want.TraceID || got.Parent != want.SpanID {
+			t.Errorf("expected %s to be a child of %+v but got %+v", got.Name, want, got)
+		}
+
+		if got.Error != "no such recipe" || len(got.Attributes) != 1 || got.Attributes[0] != (tracing.Attribute{Key: "recipes", Value: 2}) {
+			t.Errorf("got %+v", got)
+		}
+
+		if spans[1].Parent != (tracing.SpanID{}) || spans[1].Service != "test" {
+			t.Errorf("expected the parent to be a root span of test but got %+v", spans[1])
+		}
+	})
+
+	t.Run("spans are only exported once", func(t *testing.T) {
+		exporter := &recordingExporter{}
+		_, span := tracing.NewTracer("test", exporter).Start(context.Background(), "twice")
+
+		span.End()
+		span.End()
+
+		if len(exporter.Spans()) != 1 {
+			t.Errorf("expected 1 span but got %v", exporter.Spans())
+		}
+	})
+
+	t.Run("unsampled traces aren't exported", func(t *testing.T) {
+		exporter := &recordingExporter{}
+		parent, _ := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+
+		_, span := tracing.NewTracer("test", exporter).Start(tracing.ContextWithSpanContext(context.Background(), parent), "unsampled")
+		span.End()
+
+		if len(exporter.Spans()) != 0 {
+			t.Errorf("expected no spans but got %v", exporter.Spans())
+		}
+	})
+
+	t.Run("without an exporter nothing is traced", func(t *testing.T) {
+		ctx, span := tracing.NewTracer("test", nil).Start(context.Background(), "off")
+
+		span.SetAttributes("ignored", true)
+		span.SetError(errors.New("ignored"))
+		span.End()
+
+		if span != nil || tracing.SpanContextFromContext(ctx).IsValid() {
+			t.Errorf("expected no span but got %+v", span)
+		}
+	})
+}
+
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []tracing.SpanData
+}
+
+func (r *recordingExporter) ExportSpan(span tracing.SpanData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, span)
+}
+
+func (r *recordingExporter) Close() error {
+	return nil
+}
+
+func (r *recordingExporter) Spans() []tracing.SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]tracing.SpanData{}, r.spans...)
+}
@@ -0,0 +1,14 @@
+package tui_test
+
+import (
+	"github.com/quii/monolith-to-micro"
+	"testing"
+)
+
+func TestStubRecipeBookContract(t *testing.T) {
+	cookme.RecipeRepoContract{
+		NewRepo: func(t *testing.T, recipes cookme.Recipes) (cookme.RecipeRepo, func()) {
+			return &stubRecipeBook{recipes: recipes}, func() {}
+		},
+	}.Test(t)
+}
@@ -0,0 +1,71 @@
+package tui
+
+import "unicode/utf8"
+
+// KeyType is the kind of key pressed
+type KeyType int
+
+// The keys the terminal UI understands, any other character is a KeyRune
+const (
+	KeyRune KeyType = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyTab
+	KeyEnter
+	KeyBackspace
+	KeyEscape
+	KeyCtrlC
+)
+
+// Key is a key pressed on the terminal, Rune is set for KeyRune
+type Key struct {
+	Type KeyType
+	Rune rune
+}
+
+// ParseKeys turns bytes read from a terminal in raw mode into keys. A lone escape byte is the escape key, otherwise it
+// starts an arrow key sequence
+func ParseKeys(b []byte) []Key {
+	var keys []Key
+
+	for len(b) > 0 {
+		switch {
+		case len(b) >= 3 && b[0] == 0x1b && b[1] == '[':
+			if key, ok := arrows[b[2]]; ok {
+				keys = append(keys, Key{Type: key})
+			}
+			b = b[3:]
+			continue
+		case b[0] == 0x1b:
+			keys = append(keys, Key{Type: KeyEscape})
+		case b[0] == '\r' || b[0] == '\n':
+			keys = append(keys, Key{Type: KeyEnter})
+		case b[0] == '\t':
+			keys = append(keys, Key{Type: KeyTab})
+		case b[0] == 0x7f || b[0] == 0x08:
+			keys = append(keys, Key{Type: KeyBackspace})
+		case b[0] == 0x03:
+			keys = append(keys, Key{Type: KeyCtrlC})
+		case b[0] < 0x20:
+			// ignore other control characters
+		default:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, Key{Type: KeyRune, Rune: r})
+			b = b[size:]
+			continue
+		}
+
+		b = b[1:]
+	}
+
+	return keys
+}
+
+var arrows = map[byte]KeyType{
+	'A': KeyUp,
+	'B': KeyDown,
+	'C': KeyRight,
+	'D': KeyLeft,
+}
@@ -0,0 +1,463 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"github.com/quii/monolith-to-micro"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Inventory is what the terminal UI needs from the ingredients in the house, *inventory.HouseInventory implements it
+type Inventory interface {
+	cookme.IngredientsRepo
+	AddIngredients(ingredients ...cookme.PerishableIngredient) (cookme.PerishableIngredients, error)
+	DeleteBatch(ingredient string, batchID string) error
+}
+
+// RecipeBook is what the terminal UI needs from the recipes, *recipe.CachedClient implements it
+type RecipeBook interface {
+	cookme.RecipeRepo
+	Add(name string, ingredients []string) error
+	Delete(name string) error
+}
+
+type pane int
+
+const (
+	inventoryPane pane = iota
+	recipesPane
+	suggestionsPane
+	paneCount
+)
+
+var paneTitles = [paneCount]string{"In the house", "Recipes", "Why not cook"}
+
+// soonDays is how close to expiring an ingredient is before it is highlighted
+const soonDays = 2
+
+const help = "tab switch pane, ↑↓ move, a add, d delete, r refresh, q quit"
+
+// Model is the state of the terminal UI. Keys change it through Update and View draws it
+type Model struct {
+	inventory Inventory
+	recipes   RecipeBook
+	scorers   []cookme.RecipeScorer
+
+	ingredients cookme.PerishableIngredients
+	recipeList  cookme.Recipes
+	suggestions cookme.Recipes
+
+	focus   pane
+	cursors [paneCount]int
+
+	prompt string
+	input  []rune
+	status string
+}
+
+// NewModel creates a Model showing the inventory and recipes. Suggestions are scored by rating and expiry like the cookme
+// command, along with any extra scorers
+func NewModel(inventory Inventory, recipes RecipeBook, scorers ...cookme.RecipeScorer) *Model {
+	m := &Model{inventory: inventory, recipes: recipes, scorers: scorers}
+	m.Refresh()
+	return m
+}
+
+// Refresh reloads the inventory and recipes and works out the suggestions again. If either can't be read the ones from
+// before are kept and the status says why
+func (m *Model) Refresh() error {
+	now := time.Now()
+
+	ingredients, err := cookme.IngredientsContext(context.Background(), m.inventory)
+
+	if err != nil {
+		m.status = fmt.Sprintf("Couldn't read the inventory, %v", err)
+		return err
+	}
+
+	m.ingredients = ingredients.SortByExpirationDate()
+	recipes, err := cookme.RecipesContext(context.Background(), m.recipes)
+
+	if err != nil {
+		m.status = fmt.Sprintf("Couldn't get the recipes, %v", err)
+	} else {
+		m.recipeList = recipes
+	}
+
+	scorers := append([]cookme.RecipeScorer{
+		cookme.ScoreByRating(0.5),
+		cookme.ScoreByExpiry(m.ingredients, now, 0.5),
+	}, m.scorers...)
+
+	m.suggestions = cookme.ListRecipes(
+		cookme.IngredientsRepoFunc(func() cookme.PerishableIngredients { return m.ingredients }),
+		cookme.RecipeRepoFunc(func() cookme.Recipes { return m.recipeList }),
+		scorers...,
+	)
+
+	for p := range m.cursors {
+		if rows := m.rowCount(pane(p)); m.cursors[p] >= rows {
+			m.cursors[p] = rows - 1
+		}
+		if m.cursors[p] < 0 {
+			m.cursors[p] = 0
+		}
+	}
+
+	return err
+}
+
+// Update applies a key press, returning false when the UI should quit
+func (m *Model) Update(key Key) bool {
+	if key.Type == KeyCtrlC {
+		return false
+	}
+
+	if m.prompt != "" {
+		m.updatePrompt(key)
+		return true
+	}
+
+	switch key.Type {
+	case KeyTab, KeyRight:
+		m.focus = (m.focus + 1) % paneCount
+	case KeyLeft:
+		m.focus = (m.focus + paneCount - 1) % paneCount
+	case KeyUp:
+		m.move(-1)
+	case KeyDown:
+		m.move(1)
+	case KeyRune:
+		return m.command(key.Rune)
+	}
+
+	return true
+}
+
+func (m *Model) command(r rune) bool {
+	switch r {
+	case 'q':
+		return false
+	case 'k':
+		m.move(-1)
+	case 'j':
+		m.move(1)
+	case 'r':
+		if m.Refresh() == nil {
+			m.status = "Refreshed"
+		}
+	case 'a':
+		switch m.focus {
+		case inventoryPane:
+			m.startPrompt("Add ingredient (name, days to expire[, quantity[, category]]): ")
+		case recipesPane:
+			m.startPrompt("Add recipe (name: ingredient, ingredient...): ")
+		default:
+			m.status = "Suggestions come from the inventory and recipes"
+		}
+	case 'd':
+		m.delete()
+	}
+
+	return true
+}
+
+func (m *Model) move(by int) {
+	cursor := m.cursors[m.focus] + by
+
+	if cursor >= m.rowCount(m.focus) {
+		cursor = m.rowCount(m.focus) - 1
+	}
+
+	if cursor < 0 {
+		cursor = 0
+	}
+
+	m.cursors[m.focus] = cursor
+}
+
+func (m *Model) startPrompt(prompt string) {
+	m.prompt = prompt
+	m.input = nil
+	m.status = ""
+}
+
+func (m *Model) updatePrompt(key Key) {
+	switch key.Type {
+	case KeyEscape:
+		m.prompt = ""
+	case KeyBackspace:
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+	case KeyEnter:
+		input := string(m.input)
+		m.prompt = ""
+
+		if m.focus == inventoryPane {
+			m.status = m.addIngredient(input)
+		} else {
+			m.status = m.addRecipe(input)
+		}
+
+		m.Refresh()
+	case KeyRune:
+		m.input = append(m.input, key.Rune)
+	}
+}
+
+func (m *Model) addIngredient(input string) string {
+	fields := splitTrimmed(input, ",")
+
+	if len(fields) < 2 || fields[0] == "" {
+		return "An ingredient needs a name and the number of days until it expires"
+	}
+
+	days, err := strconv.Atoi(fields[1])
+
+	if err != nil {
+		return "Days to expire must be a number"
+	}
+
+	ingredient := cookme.Ingredient{Name: fields[0]}.ExpiresAt(time.Now().Add(time.Duration(days) * 24 * time.Hour))
+
+	if len(fields) > 2 {
+		if ingredient.Quantity, err = strconv.Atoi(fields[2]); err != nil || ingredient.Quantity < 1 {
+			return "Quantity must be a whole number of at least 1"
+		}
+	}
+
+	if len(fields) > 3 {
+		ingredient.Category = fields[3]
+	}
+
+	if _, err := m.inventory.AddIngredients(ingredient); err != nil {
+		return fmt.Sprintf("Couldn't add %s, %v", ingredient.Name, err)
+	}
+
+	return fmt.Sprintf("Added %s", ingredient.Name)
+}
+
+func (m *Model) addRecipe(input string) string {
+	parts := strings.SplitN(input, ":", 2)
+	name := strings.TrimSpace(parts[0])
+
+	if len(parts) < 2 || name == "" {
+		return "A recipe needs a name and at least one ingredient"
+	}
+
+	var ingredients []string
+	for _, i := range splitTrimmed(parts[1], ",") {
+		if i != "" {
+			ingredients = append(ingredients, i)
+		}
+	}
+
+	if len(ingredients) == 0 {
+		return "A recipe needs a name and at least one ingredient"
+	}
+
+	if err := m.recipes.Add(name, ingredients); err != nil {
+		return fmt.Sprintf("Couldn't add %s, %v", name, err)
+	}
+
+	return fmt.Sprintf("Added %s", name)
+}
+
+func (m *Model) delete() {
+	cursor := m.cursors[m.focus]
+
+	switch {
+	case m.focus == inventoryPane && cursor < len(m.ingredients):
+		batch := m.ingredients[cursor]
+
+		if err := m.inventory.DeleteBatch(batch.Name, batch.BatchID); err != nil {
+			m.status = fmt.Sprintf("Couldn't delete %s, %v", batch.Name, err)
+		} else {
+			m.status = fmt.Sprintf("Deleted a batch of %s", batch.Name)
+		}
+	case m.focus == recipesPane && cursor < len(m.recipeList):
+		name := m.recipeList[cursor].Name
+
+		if err := m.recipes.Delete(name); err != nil {
+			m.status = fmt.Sprintf("Couldn't delete %s, %v", name, err)
+		} else {
+			m.status = fmt.Sprintf("Deleted %s", name)
+		}
+	default:
+		return
+	}
+
+	m.Refresh()
+}
+
+func (m *Model) rowCount(p pane) int {
+	switch p {
+	case inventoryPane:
+		return len(m.ingredients)
+	case recipesPane:
+		return len(m.recipeList)
+	default:
+		return len(m.suggestions)
+	}
+}
+
+// row is a line of a pane and the colour it is drawn in
+type row struct {
+	text   string
+	colour string
+}
+
+const (
+	red    = "\x1b[31m"
+	yellow = "\x1b[33m"
+	green  = "\x1b[32m"
+	bold   = "\x1b[1m"
+	invert = "\x1b[7m"
+	reset  = "\x1b[0m"
+)
+
+func (m *Model) rows(p pane, now time.Time) []row {
+	var rows []row
+
+	switch p {
+	case inventoryPane:
+		for _, i := range m.ingredients {
+			rows = append(rows, row{
+				text:   fmt.Sprintf("%s x%d %s", i.Name, i.Quantity, i.ExpirationDate.Format("Mon 2 Jan")),
+				colour: urgency(i, now),
+			})
+		}
+	case recipesPane:
+		for _, r := range m.recipeList {
+			var ingredients []string
+			for _, i := range r.Ingredients {
+				ingredients = append(ingredients, i.Name)
+			}
+			rows = append(rows, row{text: fmt.Sprintf("%s (%s)", r.Name, strings.Join(ingredients, ", "))})
+		}
+	default:
+		for _, r := range m.suggestions {
+			rows = append(rows, row{text: r.Name})
+		}
+	}
+
+	return rows
+}
+
+// urgency is the colour an ingredient is drawn in, red when expired, yellow when expiring soon, otherwise green
+func urgency(i cookme.PerishableIngredient, now time.Time) string {
+	switch {
+	case i.HasExpired(now):
+		return red
+	case i.HasExpired(now.Add(soonDays * 24 * time.Hour)):
+		return yellow
+	default:
+		return green
+	}
+}
+
+// View draws the panes side by side to fit a terminal of width by height, lines are separated by \r\n as the terminal
+// is in raw mode
+func (m *Model) View(width, height int) string {
+	now := time.Now()
+	columnWidth := (width - int(paneCount) + 1) / int(paneCount)
+	visibleRows := height - 3
+
+	if columnWidth < 1 || visibleRows < 1 {
+		return "terminal too small"
+	}
+
+	var lines []string
+	lines = append(lines, bold+fit("cook me - "+help, width)+reset)
+
+	var headings []string
+	for p := pane(0); p < paneCount; p++ {
+		heading := fit(fmt.Sprintf("%s (%d)", paneTitles[p], m.rowCount(p)), columnWidth)
+		if p == m.focus {
+			heading = invert + heading + reset
+		} else {
+			heading = bold + heading + reset
+		}
+		headings = append(headings, heading)
+	}
+	lines = append(lines, strings.Join(headings, "│"))
+
+	var columns [paneCount][]string
+	for p := pane(0); p < paneCount; p++ {
+		columns[p] = m.column(p, now, columnWidth, visibleRows)
+	}
+
+	for i := 0; i < visibleRows; i++ {
+		lines = append(lines, columns[0][i]+"│"+columns[1][i]+"│"+columns[2][i])
+	}
+
+	if m.prompt != "" {
+		lines = append(lines, fit(m.prompt+string(m.input)+"█", width))
+	} else {
+		lines = append(lines, fit(m.status, width))
+	}
+
+	return strings.Join(lines, "\r\n")
+}
+
+// column draws the rows of a pane scrolled so the cursor can be seen
+func (m *Model) column(p pane, now time.Time, width, height int) []string {
+	rows := m.rows(p, now)
+	cursor := m.cursors[p]
+
+	offset := 0
+	if cursor >= height {
+		offset = cursor - height + 1
+	}
+
+	lines := make([]string, height)
+
+	for i := range lines {
+		n := offset + i
+
+		if n >= len(rows) {
+			lines[i] = strings.Repeat(" ", width)
+			continue
+		}
+
+		text := fit(rows[n].text, width)
+
+		switch {
+		case n == cursor && p == m.focus:
+			lines[i] = invert + text + reset
+		case rows[n].colour != "":
+			lines[i] = rows[n].colour + text + reset
+		default:
+			lines[i] = text
+		}
+	}
+
+	return lines
+}
+
+// fit truncates or pads s to exactly width characters
+func fit(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+
+	runes := []rune(s)
+
+	if width > 1 && len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+
+	return string(runes[:width])
+}
+
+func splitTrimmed(s, sep string) []string {
+	var fields []string
+	for _, f := range strings.Split(s, sep) {
+		fields = append(fields, strings.TrimSpace(f))
+	}
+	return fields
+}
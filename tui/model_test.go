@@ -0,0 +1,320 @@
+package tui_test
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/inventory/inventorytest"
+	"github.com/quii/monolith-to-micro/tui"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestModel(t *testing.T) {
+
+	cheese := cookme.Ingredient{Name: "Cheese"}
+	bread := cookme.Ingredient{Name: "Bread"}
+
+	cheeseOnToast := cookme.NewRecipe("Cheese on toast", cheese, bread)
+	omelette := cookme.NewRecipe("Omelette", cookme.Ingredient{Name: "Eggs"})
+
+	t.Run("shows the inventory, recipes and suggestions", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		inv.AddIngredients(cheese.ExpiresAt(time.Now().Add(24*time.Hour)), bread.ExpiresAt(time.Now().Add(24*time.Hour)))
+
+		model := tui.NewModel(inv, &stubRecipeBook{recipes: cookme.Recipes{cheeseOnToast, omelette}})
+
+		view := plain(model.View(120, 10))
+
+		assertContains(t, view, "In the house (2)")
+		assertContains(t, view, "Recipes (2)")
+		assertContains(t, view, "Why not cook (1)")
+		assertContains(t, view, "Omelette (Eggs)")
+	})
+
+	t.Run("keeps the ingredients from before when the inventory can't be read", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		inv.AddIngredients(cheese.ExpiresAt(time.Now().Add(24 * time.Hour)))
+		failing := &failingInventory{Inventory: inv}
+		model := tui.NewModel(failing, &stubRecipeBook{})
+
+		failing.err = errors.New("timeout")
+		press(model, "r")
+
+		view := plain(model.View(120, 10))
+		assertContains(t, view, "In the house (1)")
+		assertContains(t, view, "Couldn't read the inventory, timeout")
+	})
+
+	t.Run("keeps the recipes from before when they can't be fetched", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		recipes := &stubRecipeBook{recipes: cookme.Recipes{cheeseOnToast, omelette}}
+		model := tui.NewModel(inv, recipes)
+
+		recipes.err = errors.New("permission denied")
+		press(model, "r")
+
+		view := plain(model.View(120, 10))
+		assertContains(t, view, "Recipes (2)")
+		assertContains(t, view, "Couldn't get the recipes, permission denied")
+	})
+
+	t.Run("adding an ingredient refreshes the suggestions", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		inv.AddIngredients(cheese.ExpiresAt(time.Now().Add(24 * time.Hour)))
+
+		model := tui.NewModel(inv, &stubRecipeBook{recipes: cookme.Recipes{cheeseOnToast}})
+		assertContains(t, plain(model.View(120, 10)), "Why not cook (0)")
+
+		press(model, "a")
+		press(model, "Bread, 3, 2, bakery")
+		model.Update(tui.Key{Type: tui.KeyEnter})
+
+		view := plain(model.View(120, 10))
+		assertContains(t, view, "Added Bread")
+		assertContains(t, view, "Why not cook (1)")
+
+		added := inv.Ingredients()[1]
+		if added.Name != "Bread" || added.Quantity != 2 || added.Category != "bakery" {
+			t.Errorf("got %+v, want 2 Bread in bakery", added)
+		}
+	})
+
+	t.Run("rejects an ingredient without days to expire", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		model := tui.NewModel(inv, &stubRecipeBook{})
+
+		press(model, "a")
+		press(model, "Bread")
+		model.Update(tui.Key{Type: tui.KeyEnter})
+
+		assertContains(t, plain(model.View(120, 10)), "needs a name and the number of days")
+		cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), nil)
+	})
+
+	t.Run("escape cancels adding", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		model := tui.NewModel(inv, &stubRecipeBook{})
+
+		press(model, "a")
+		press(model, "Bread, 3")
+		model.Update(tui.Key{Type: tui.KeyEscape})
+		model.Update(tui.Key{Type: tui.KeyEnter})
+
+		cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), nil)
+	})
+
+	t.Run("deletes the selected batch", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		added, _ := inv.AddIngredients(
+			cheese.ExpiresAt(time.Now().Add(24*time.Hour)),
+			bread.ExpiresAt(time.Now().Add(48*time.Hour)),
+		)
+
+		model := tui.NewModel(inv, &stubRecipeBook{})
+
+		model.Update(tui.Key{Type: tui.KeyDown})
+		press(model, "d")
+
+		cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), added[:1])
+	})
+
+	t.Run("adds and deletes recipes in the recipes pane", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		recipes := &stubRecipeBook{recipes: cookme.Recipes{omelette}}
+		model := tui.NewModel(inv, recipes)
+
+		model.Update(tui.Key{Type: tui.KeyTab})
+		press(model, "a")
+		press(model, "Cheese on toast: Cheese, Bread")
+		model.Update(tui.Key{Type: tui.KeyEnter})
+
+		cookme.AssertRecipesEqual(t, recipes.recipes, cookme.Recipes{omelette, cheeseOnToast})
+
+		press(model, "d")
+
+		cookme.AssertRecipesEqual(t, recipes.recipes, cookme.Recipes{cheeseOnToast})
+	})
+
+	t.Run("says when the recipe service rejects a change", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		recipes := &stubRecipeBook{recipes: cookme.Recipes{omelette}, changeErr: errors.New("permission denied")}
+		model := tui.NewModel(inv, recipes)
+
+		model.Update(tui.Key{Type: tui.KeyTab})
+		press(model, "a")
+		press(model, "Cheese on toast: Cheese, Bread")
+		model.Update(tui.Key{Type: tui.KeyEnter})
+
+		assertContains(t, plain(model.View(120, 10)), "Couldn't add Cheese on toast, permission denied")
+
+		press(model, "d")
+
+		assertContains(t, plain(model.View(120, 10)), "Couldn't delete Omelette, permission denied")
+	})
+
+	t.Run("highlights the selected row of the focused pane", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		model := tui.NewModel(inv, &stubRecipeBook{recipes: cookme.Recipes{omelette, cheeseOnToast}})
+
+		model.Update(tui.Key{Type: tui.KeyRight})
+		press(model, "j")
+
+		selected := regexp.MustCompile("\x1b\\[7m([^\x1b]*)").FindAllStringSubmatch(model.View(120, 10), -1)
+
+		if len(selected) != 2 || !strings.HasPrefix(selected[0][1], "Recipes") || !strings.HasPrefix(selected[1][1], "Cheese on toast") {
+			t.Errorf("expected the recipes heading and cheese on toast to be highlighted but got %q", selected)
+		}
+	})
+
+	t.Run("quits", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		model := tui.NewModel(inv, &stubRecipeBook{})
+
+		if model.Update(tui.Key{Type: tui.KeyRune, Rune: 'q'}) {
+			t.Error("expected q to quit")
+		}
+
+		if model.Update(tui.Key{Type: tui.KeyCtrlC}) {
+			t.Error("expected ctrl-c to quit")
+		}
+	})
+
+	t.Run("fits the view to the terminal", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		model := tui.NewModel(inv, &stubRecipeBook{recipes: cookme.Recipes{cheeseOnToast, omelette}})
+
+		lines := strings.Split(plain(model.View(40, 5)), "\r\n")
+
+		if len(lines) != 5 {
+			t.Errorf("got %d lines, want 5", len(lines))
+		}
+
+		for _, line := range lines {
+			if n := len([]rune(line)); n > 40 {
+				t.Errorf("line %q is %d wide, want at most 40", line, n)
+			}
+		}
+	})
+}
+
+func TestParseKeys(t *testing.T) {
+	got := tui.ParseKeys([]byte("a\x1b[A\x1b[B\t\r\x7f\x1bé\x03"))
+	want := []tui.Key{
+		{Type: tui.KeyRune, Rune: 'a'},
+		{Type: tui.KeyUp},
+		{Type: tui.KeyDown},
+		{Type: tui.KeyTab},
+		{Type: tui.KeyEnter},
+		{Type: tui.KeyBackspace},
+		{Type: tui.KeyEscape},
+		{Type: tui.KeyRune, Rune: 'é'},
+		{Type: tui.KeyCtrlC},
+	}
+
+	if !cmp.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+type failingInventory struct {
+	tui.Inventory
+	err error
+}
+
+func (f *failingInventory) IngredientsContext(ctx context.Context) (cookme.PerishableIngredients, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.Ingredients(), nil
+}
+
+type stubRecipeBook struct {
+	recipes   cookme.Recipes
+	err       error
+	changeErr error
+}
+
+func (s *stubRecipeBook) Recipes() cookme.Recipes {
+	return s.recipes
+}
+
+func (s *stubRecipeBook) RecipesContext(ctx context.Context) (cookme.Recipes, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.recipes, nil
+}
+
+func (s *stubRecipeBook) Add(name string, ingredients []string) error {
+	if s.changeErr != nil {
+		return s.changeErr
+	}
+	recipe := cookme.NewRecipe(name)
+	for _, i := range ingredients {
+		recipe.Ingredients = append(recipe.Ingredients, cookme.Ingredient{Name: i})
+	}
+	s.recipes = append(s.recipes, recipe)
+	return nil
+}
+
+func (s *stubRecipeBook) Delete(name string) error {
+	if s.changeErr != nil {
+		return s.changeErr
+	}
+	var recipes cookme.Recipes
+	for _, r := range s.recipes {
+		if r.Name != name {
+			recipes = append(recipes, r)
+		}
+	}
+	s.recipes = recipes
+	return nil
+}
+
+func press(model *tui.Model, keys string) {
+	for _, key := range tui.ParseKeys([]byte(keys)) {
+		model.Update(key)
+	}
+}
+
+var ansi = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// plain removes colours from a view
+func plain(view string) string {
+	return ansi.ReplaceAllString(view, "")
+}
+
+func assertContains(t *testing.T, got, want string) {
+	t.Helper()
+	if !strings.Contains(got, want) {
+		t.Errorf("expected %q in %q", want, got)
+	}
+}
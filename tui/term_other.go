@@ -0,0 +1,21 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package tui
+
+import (
+	"errors"
+	"time"
+)
+
+func makeRaw(fd int) (restore func(), err error) {
+	return nil, errors.New("the terminal ui is only supported on linux and macOS")
+}
+
+func size(fd int) (width, height int) {
+	return 80, 24
+}
+
+func waitForInput(fd int, timeout time.Duration) (ready bool, err error) {
+	return true, nil
+}
@@ -0,0 +1,58 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package tui
+
+import (
+	"golang.org/x/sys/unix"
+	"time"
+)
+
+// makeRaw stops the terminal echoing and buffering lines so each key can be read as it is pressed
+func makeRaw(fd int) (restore func(), err error) {
+	old, err := unix.IoctlGetTermios(fd, getTermios)
+
+	if err != nil {
+		return nil, err
+	}
+
+	raw := *old
+	raw.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	raw.Oflag &^= unix.OPOST
+	raw.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	raw.Cflag &^= unix.CSIZE | unix.PARENB
+	raw.Cflag |= unix.CS8
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+
+	if err := unix.IoctlSetTermios(fd, setTermios, &raw); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		unix.IoctlSetTermios(fd, setTermios, old)
+	}, nil
+}
+
+// size returns the width and height of the terminal, or 80x24 if it can't be found
+func size(fd int) (width, height int) {
+	ws, err := unix.IoctlGetWinsize(fd, unix.TIOCGWINSZ)
+
+	if err != nil || ws.Col == 0 || ws.Row == 0 {
+		return 80, 24
+	}
+
+	return int(ws.Col), int(ws.Row)
+}
+
+// waitForInput waits up to timeout for there to be something to read from fd
+func waitForInput(fd int, timeout time.Duration) (ready bool, err error) {
+	fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+	n, err := unix.Poll(fds, int(timeout/time.Millisecond))
+
+	if err == unix.EINTR {
+		return false, nil
+	}
+
+	return n > 0, err
+}
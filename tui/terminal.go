@@ -0,0 +1,118 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	enterFullScreen = "\x1b[?1049h\x1b[?25l"
+	leaveFullScreen = "\x1b[?25h\x1b[?1049l"
+	home            = "\x1b[H"
+	clearLine       = "\x1b[K"
+	clearBelow      = "\x1b[J"
+)
+
+// Run draws m full screen on the terminal in, handling keys until q is pressed or ctx is done. The inventory and recipes
+// are reloaded whenever something is received from changes, like the recipe service's stream of changes, and every
+// refresh so changes made elsewhere to the inventory show up
+func Run(ctx context.Context, in *os.File, out io.Writer, m *Model, changes <-chan struct{}, refresh time.Duration) error {
+	fd := int(in.Fd())
+	restore, err := makeRaw(fd)
+
+	if err != nil {
+		return fmt.Errorf("problem putting the terminal into raw mode, %v", err)
+	}
+
+	defer restore()
+
+	fmt.Fprint(out, enterFullScreen)
+	defer fmt.Fprint(out, leaveFullScreen)
+
+	keys := make(chan []Key)
+	done := make(chan struct{})
+	go readKeys(in, keys, done)
+
+	// stop reading keys before the terminal is restored, so nothing typed afterwards goes to the ui
+	defer func() {
+		close(done)
+		for range keys {
+		}
+	}()
+
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+
+	for {
+		width, height := size(fd)
+		fmt.Fprint(out, home, m.View(width, height), clearLine, clearBelow)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-changes:
+			if !ok {
+				changes = nil
+				continue
+			}
+
+			m.Refresh()
+		case <-ticker.C:
+			m.Refresh()
+		case pressed, ok := <-keys:
+			if !ok {
+				return nil
+			}
+
+			for _, key := range pressed {
+				if !m.Update(key) {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// keyPoll is how long readKeys waits for a key before checking whether to stop
+const keyPoll = 100 * time.Millisecond
+
+// readKeys sends the keys pressed on in until done is closed or in can't be read, then closes keys
+func readKeys(in *os.File, keys chan<- []Key, done <-chan struct{}) {
+	defer close(keys)
+	buf := make([]byte, 64)
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		ready, err := waitForInput(int(in.Fd()), keyPoll)
+
+		if err != nil {
+			return
+		}
+
+		if !ready {
+			continue
+		}
+
+		n, err := in.Read(buf)
+
+		if n > 0 {
+			select {
+			case keys <- ParseKeys(buf[:n]):
+			case <-done:
+				return
+			}
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
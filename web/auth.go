@@ -0,0 +1,158 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"github.com/quii/monolith-to-micro/auth"
+	"github.com/quii/monolith-to-micro/logging"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"html/template"
+	"net/http"
+)
+
+const (
+	tokenCookie = "cookme_token"
+	csrfCookie  = "cookme_csrf"
+	csrfField   = "csrf"
+)
+
+// Option changes how a Server works
+type Option func(*Server)
+
+// RequireLogin only lets in people with a token for household. Like the recipe service, looking needs a reader and
+// making changes an editor. People log in with their token's secret, or a proxy in front of the UI can forward it as an
+// Authorization: Bearer header
+func RequireLogin(verifier auth.Verifier, household string) Option {
+	return func(s *Server) {
+		s.verifier = verifier
+		s.household = household
+	}
+}
+
+type csrfKey struct{}
+
+// protectForms rejects posts whose form doesn't carry the CSRF token from the visitor's cookie, so other sites can't
+// submit forms on their behalf
+func protectForms(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := csrfToken(w, r)
+
+		if r.Method == http.MethodPost && subtle.ConstantTimeCompare([]byte(r.FormValue(csrfField)), []byte(token)) != 1 {
+			http.Error(w, "the form has expired, go back, reload the page and try again", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), csrfKey{}, token)))
+	})
+}
+
+// csrfToken returns the visitor's CSRF token, giving them one if they don't have one yet
+func csrfToken(w http.ResponseWriter, r *http.Request) string {
+	if cookie, err := r.Cookie(csrfCookie); err == nil && cookie.Value != "" {
+		return cookie.Value
+	}
+
+	b := make([]byte, 32)
+	rand.Read(b)
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	http.SetCookie(w, &http.Cookie{Name: csrfCookie, Value: token, Path: "/", HttpOnly: true, Secure: r.TLS != nil, SameSite: http.SameSiteStrictMode})
+
+	return token
+}
+
+func csrfFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(csrfKey{}).(string)
+	return token
+}
+
+// requireLogin sends anyone without a good enough token for the household to the login page
+func (s *Server) requireLogin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" || r.URL.Path == "/logout" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		required := auth.Editor
+
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			required = auth.Reader
+		}
+
+		token, err := auth.Check(s.verifier, authorization(r), required)
+
+		switch {
+		case status.Code(err) == codes.Unauthenticated && r.Method == http.MethodGet:
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+		case status.Code(err) == codes.Unauthenticated:
+			s.renderLogin(w, r, http.StatusUnauthorized, "Log in to make changes")
+		case err != nil:
+			http.Error(w, "your token can only look at the recipes and inventory, not change them", http.StatusForbidden)
+		case token.Household != s.household:
+			http.Error(w, "your token is for another household", http.StatusForbidden)
+		default:
+			next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), token)))
+		}
+	})
+}
+
+// authorization is the forwarded Authorization header, or one made from the token saved when logging in
+func authorization(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		return header
+	}
+
+	if cookie, err := r.Cookie(tokenCookie); err == nil {
+		return "Bearer " + cookie.Value
+	}
+
+	return ""
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.renderLogin(w, r, http.StatusOK, "")
+		return
+	}
+
+	secret := r.FormValue("token")
+	token, ok := s.verifier.Verify(secret)
+
+	if !ok {
+		s.renderLogin(w, r, http.StatusUnauthorized, "That token is unknown or has been revoked")
+		return
+	}
+
+	if token.Household != s.household {
+		s.renderLogin(w, r, http.StatusForbidden, "That token is for another household")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: tokenCookie, Value: secret, Path: "/", HttpOnly: true, Secure: r.TLS != nil, SameSite: http.SameSiteStrictMode})
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (s *Server) logout(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{Name: tokenCookie, Path: "/", MaxAge: -1, HttpOnly: true, Secure: r.TLS != nil, SameSite: http.SameSiteStrictMode})
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+type loginPage struct {
+	Error string
+	CSRF  string
+}
+
+func (s *Server) renderLogin(w http.ResponseWriter, r *http.Request, code int, errorMessage string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(code)
+
+	if err := loginTemplate.Execute(w, loginPage{Error: errorMessage, CSRF: csrfFromContext(r.Context())}); err != nil {
+		logging.Error("problem rendering login page", "err", err)
+	}
+}
+
+var loginTemplate = template.Must(template.New("login").Parse(loginHTML))
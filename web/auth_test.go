@@ -0,0 +1,139 @@
+package web_test
+
+import (
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/auth"
+	"github.com/quii/monolith-to-micro/inventory"
+	"github.com/quii/monolith-to-micro/inventory/inventorytest"
+	"github.com/quii/monolith-to-micro/web"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRequireLogin(t *testing.T) {
+
+	tokens := map[string]auth.Token{
+		"editor": {Household: "home", Role: auth.Editor},
+		"reader": {Household: "home", Role: auth.Reader},
+		"office": {Household: "office", Role: auth.Editor},
+	}
+
+	verifier := auth.VerifierFunc(func(secret string) (auth.Token, bool) {
+		token, ok := tokens[secret]
+		return token, ok
+	})
+
+	newServer := func(t *testing.T) (server *web.Server, inv *inventory.HouseInventory, cleanup func()) {
+		inv, cleanup = inventorytest.NewInventory(t)
+		return web.NewServer(inv, &stubRecipeBook{}, &stubCookingLog{}, web.RequireLogin(verifier, "home")), inv, cleanup
+	}
+
+	loggedInAs := func(secret string) *http.Cookie {
+		return &http.Cookie{Name: "cookme_token", Value: secret}
+	}
+
+	addMilk := url.Values{"name": {"Milk"}, "days": {"3"}}
+
+	t.Run("sends visitors who haven't logged in to the login page", func(t *testing.T) {
+		server, _, cleanup := newServer(t)
+		defer cleanup()
+
+		res := get(server, "/")
+
+		assertStatus(t, res, http.StatusSeeOther)
+
+		if location := res.Header().Get("Location"); location != "/login" {
+			t.Errorf("expected to be sent to /login but got %q", location)
+		}
+	})
+
+	t.Run("doesn't let visitors who haven't logged in make changes", func(t *testing.T) {
+		server, inv, cleanup := newServer(t)
+		defer cleanup()
+
+		assertStatus(t, post(server, "/ingredients", addMilk), http.StatusUnauthorized)
+		cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), nil)
+	})
+
+	t.Run("logging in with a token for the household saves it in a cookie", func(t *testing.T) {
+		server, _, cleanup := newServer(t)
+		defer cleanup()
+
+		res := post(server, "/login", url.Values{"token": {"editor"}})
+
+		assertStatus(t, res, http.StatusSeeOther)
+
+		var saved *http.Cookie
+		for _, cookie := range res.Result().Cookies() {
+			if cookie.Name == "cookme_token" {
+				saved = cookie
+			}
+		}
+
+		if saved == nil || saved.Value != "editor" || !saved.HttpOnly {
+			t.Fatalf("expected the token to be saved in an http only cookie but got %v", res.Result().Cookies())
+		}
+
+		assertStatus(t, get(server, "/", saved), http.StatusOK)
+	})
+
+	t.Run("rejects unknown tokens and tokens for other households", func(t *testing.T) {
+		server, _, cleanup := newServer(t)
+		defer cleanup()
+
+		assertStatus(t, post(server, "/login", url.Values{"token": {"guessed"}}), http.StatusUnauthorized)
+		assertStatus(t, post(server, "/login", url.Values{"token": {"office"}}), http.StatusForbidden)
+		assertStatus(t, get(server, "/", loggedInAs("office")), http.StatusForbidden)
+	})
+
+	t.Run("readers can look but not make changes", func(t *testing.T) {
+		server, inv, cleanup := newServer(t)
+		defer cleanup()
+
+		assertStatus(t, get(server, "/", loggedInAs("reader")), http.StatusOK)
+		assertStatus(t, post(server, "/ingredients", addMilk, loggedInAs("reader")), http.StatusForbidden)
+		cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), nil)
+	})
+
+	t.Run("editors can make changes", func(t *testing.T) {
+		server, inv, cleanup := newServer(t)
+		defer cleanup()
+
+		assertStatus(t, post(server, "/ingredients", addMilk, loggedInAs("editor")), http.StatusSeeOther)
+
+		if len(inv.Ingredients()) != 1 {
+			t.Errorf("expected milk to be added but got %v", inv.Ingredients())
+		}
+	})
+
+	t.Run("accepts a token forwarded by a proxy", func(t *testing.T) {
+		server, _, cleanup := newServer(t)
+		defer cleanup()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer reader")
+
+		res := httptest.NewRecorder()
+		server.ServeHTTP(res, req)
+
+		assertStatus(t, res, http.StatusOK)
+	})
+
+	t.Run("logging out forgets the token", func(t *testing.T) {
+		server, _, cleanup := newServer(t)
+		defer cleanup()
+
+		res := post(server, "/logout", url.Values{}, loggedInAs("editor"))
+
+		assertStatus(t, res, http.StatusSeeOther)
+
+		for _, cookie := range res.Result().Cookies() {
+			if cookie.Name == "cookme_token" && cookie.MaxAge >= 0 && cookie.Expires.After(time.Now()) {
+				t.Errorf("expected the token cookie to be removed but got %v", cookie)
+			}
+		}
+	})
+}
@@ -0,0 +1,269 @@
+package web
+
+import (
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/auth"
+	"github.com/quii/monolith-to-micro/inventory"
+	"github.com/quii/monolith-to-micro/logging"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Inventory is what the web UI needs from the ingredients in the house, *inventory.HouseInventory implements it
+type Inventory interface {
+	cookme.IngredientsRepo
+	AddIngredients(ingredients ...cookme.PerishableIngredient) (cookme.PerishableIngredients, error)
+	DeleteBatch(ingredient string, batchID string) error
+}
+
+// RecipeBook is what the web UI needs from the recipes, *recipe.CachedClient implements it
+type RecipeBook interface {
+	cookme.RecipeRepo
+	Add(name string, ingredients []string) error
+	Delete(name string) error
+}
+
+// CookingLog is what the web UI needs from the history of what was cooked, *history.CookingLog implements it
+type CookingLog interface {
+	History() cookme.CookingHistory
+}
+
+// Server renders pages to see what's in the house, manage ingredients and recipes and decide what to cook
+type Server struct {
+	inventory  Inventory
+	recipes    RecipeBook
+	cookingLog CookingLog
+	verifier   auth.Verifier
+	household  string
+	http.Handler
+}
+
+// soonDays is how close to expiring an ingredient is before it is highlighted
+const soonDays = 2
+
+// rotateDays is how recently a recipe was cooked for it to be suggested last, the same as the cookme command
+const rotateDays = 3
+
+// NewServer creates a Server, it is an http.Handler
+func NewServer(inventory Inventory, recipes RecipeBook, cookingLog CookingLog, options ...Option) *Server {
+	s := &Server{inventory: inventory, recipes: recipes, cookingLog: cookingLog}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	router := http.NewServeMux()
+	router.HandleFunc("/", s.home)
+	router.Handle("/ingredients", onlyPost(s.addIngredient))
+	router.Handle("/ingredients/delete", onlyPost(s.deleteIngredient))
+	router.Handle("/recipes", onlyPost(s.addRecipe))
+	router.Handle("/recipes/delete", onlyPost(s.deleteRecipe))
+
+	var handler http.Handler = router
+
+	if s.verifier != nil {
+		router.HandleFunc("/login", s.login)
+		router.Handle("/logout", onlyPost(s.logout))
+		handler = s.requireLogin(handler)
+	}
+
+	s.Handler = protectForms(handler)
+
+	return s
+}
+
+type page struct {
+	Error       string
+	CSRF        string
+	LoggedIn    bool
+	Ingredients []ingredientRow
+	Tonight     cookme.Recipes
+	Recipes     cookme.Recipes
+}
+
+type ingredientRow struct {
+	cookme.PerishableIngredient
+	Urgency string
+	Expires string
+}
+
+func (s *Server) home(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.render(w, r, http.StatusOK, "")
+}
+
+func (s *Server) addIngredient(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(r.FormValue("name"))
+	days, err := strconv.Atoi(r.FormValue("days"))
+
+	if name == "" || err != nil {
+		s.render(w, r, http.StatusBadRequest, "An ingredient needs a name and the number of days until it expires")
+		return
+	}
+
+	quantity := 1
+
+	if q := r.FormValue("quantity"); q != "" {
+		quantity, err = strconv.Atoi(q)
+
+		if err != nil || quantity < 1 {
+			s.render(w, r, http.StatusBadRequest, "Quantity must be a whole number of at least 1")
+			return
+		}
+	}
+
+	_, err = s.inventory.AddIngredients(cookme.PerishableIngredient{
+		Ingredient:     cookme.Ingredient{Name: name},
+		ExpirationDate: time.Now().Add(time.Duration(days) * 24 * time.Hour),
+		Quantity:       quantity,
+		Category:       strings.TrimSpace(r.FormValue("category")),
+	})
+
+	if err != nil {
+		logging.Error("problem adding ingredient", "name", name, "err", err)
+		s.render(w, r, http.StatusInternalServerError, "Couldn't add "+name+", try again")
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (s *Server) deleteIngredient(w http.ResponseWriter, r *http.Request) {
+	err := s.inventory.DeleteBatch(r.FormValue("name"), r.FormValue("batch"))
+
+	if err == inventory.ErrBatchNotFound {
+		s.render(w, r, http.StatusNotFound, "That batch has already gone")
+		return
+	}
+
+	if err != nil {
+		logging.Error("problem deleting batch", "name", r.FormValue("name"), "err", err)
+		s.render(w, r, http.StatusInternalServerError, "Couldn't delete "+r.FormValue("name")+", try again")
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (s *Server) addRecipe(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(r.FormValue("name"))
+
+	var ingredients []string
+	for _, i := range strings.Split(r.FormValue("ingredients"), ",") {
+		if i = strings.TrimSpace(i); i != "" {
+			ingredients = append(ingredients, i)
+		}
+	}
+
+	if name == "" || len(ingredients) == 0 {
+		s.render(w, r, http.StatusBadRequest, "A recipe needs a name and at least one ingredient")
+		return
+	}
+
+	if err := s.recipes.Add(name, ingredients); err != nil {
+		logging.Error("problem adding recipe", "recipe", name, "err", err)
+		s.render(w, r, http.StatusBadGateway, "Couldn't add "+name+", the recipe service said no")
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (s *Server) deleteRecipe(w http.ResponseWriter, r *http.Request) {
+	name := r.FormValue("name")
+
+	if err := s.recipes.Delete(name); err != nil {
+		logging.Error("problem deleting recipe", "recipe", name, "err", err)
+		s.render(w, r, http.StatusBadGateway, "Couldn't delete "+name+", the recipe service said no")
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (s *Server) render(w http.ResponseWriter, r *http.Request, code int, errorMessage string) {
+	now := time.Now()
+	ingredients, err := cookme.IngredientsContext(r.Context(), s.inventory)
+
+	if err != nil {
+		logging.Error("problem reading inventory", "err", err)
+
+		if code == http.StatusOK {
+			code = http.StatusInternalServerError
+			errorMessage = "Couldn't read what's in the house, try again"
+		}
+	}
+
+	ingredients = ingredients.SortByExpirationDate()
+	recipes, err := cookme.RecipesContext(r.Context(), s.recipes)
+
+	if err != nil {
+		logging.Error("problem getting recipes", "err", err)
+
+		if code == http.StatusOK {
+			code = http.StatusBadGateway
+			errorMessage = "Couldn't get the recipes, the recipe service may be down"
+		}
+	}
+
+	p := page{
+		Error:    errorMessage,
+		CSRF:     csrfFromContext(r.Context()),
+		LoggedIn: s.verifier != nil,
+		Tonight: cookme.ListRecipesContext(r.Context(),
+			cookme.IngredientsRepoFunc(func() cookme.PerishableIngredients { return ingredients }),
+			cookme.RecipeRepoFunc(func() cookme.Recipes { return recipes }),
+			cookme.ScoreByRating(0.5),
+			cookme.ScoreByExpiry(ingredients, now, 0.5),
+			cookme.DownRankRecentlyCooked(s.cookingLog.History(), now.Add(-rotateDays*24*time.Hour)),
+		),
+		Recipes: recipes,
+	}
+
+	for _, i := range ingredients {
+		p.Ingredients = append(p.Ingredients, ingredientRow{
+			PerishableIngredient: i,
+			Urgency:              urgency(i, now),
+			Expires:              i.ExpirationDate.Format("Mon 2 Jan"),
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(code)
+
+	if err := pageTemplate.Execute(w, p); err != nil {
+		logging.Error("problem rendering page", "err", err)
+	}
+}
+
+// urgency is the CSS class an ingredient is shown with, expired, soon or fresh
+func urgency(i cookme.PerishableIngredient, now time.Time) string {
+	switch {
+	case i.HasExpired(now):
+		return "expired"
+	case i.HasExpired(now.Add(soonDays * 24 * time.Hour)):
+		return "soon"
+	default:
+		return "fresh"
+	}
+}
+
+func onlyPost(handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		handler(w, r)
+	})
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(pageHTML))
@@ -0,0 +1,374 @@
+package web_test
+
+import (
+	"context"
+	"errors"
+	"github.com/quii/monolith-to-micro"
+	"github.com/quii/monolith-to-micro/inventory/inventorytest"
+	"github.com/quii/monolith-to-micro/web"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebUI(t *testing.T) {
+
+	cheese := cookme.Ingredient{Name: "Cheese"}
+	bread := cookme.Ingredient{Name: "Bread"}
+	eggs := cookme.Ingredient{Name: "Eggs"}
+
+	cheeseOnToast := cookme.NewRecipe("Cheese on toast", cheese, bread)
+	omelette := cookme.NewRecipe("Omelette", eggs)
+
+	t.Run("lists ingredients soonest to expire first, coloured by how urgent they are, without what has expired", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		inv.AddIngredients(
+			eggs.ExpiresAt(time.Now().Add(7*24*time.Hour)),
+			bread.ExpiresAt(time.Now().Add(-24*time.Hour)),
+			cheese.ExpiresAt(time.Now().Add(24*time.Hour)),
+		)
+
+		res := get(web.NewServer(inv, &stubRecipeBook{}, &stubCookingLog{}), "/")
+
+		assertStatus(t, res, http.StatusOK)
+
+		got := regexp.MustCompile(`<tr class="(\w+)">\s*<td>(\w+)</td>`).FindAllStringSubmatch(res.Body.String(), -1)
+		want := [][]string{{"soon", "Cheese"}, {"fresh", "Eggs"}}
+
+		if len(got) != len(want) {
+			t.Fatalf("got %d ingredient rows, want %d", len(got), len(want))
+		}
+
+		for i := range want {
+			if got[i][1] != want[i][0] || got[i][2] != want[i][1] {
+				t.Errorf("row %d got %s %s, want %s %s", i, got[i][1], got[i][2], want[i][0], want[i][1])
+			}
+		}
+	})
+
+	t.Run("says when the inventory can't be read rather than showing it empty", func(t *testing.T) {
+		res := get(web.NewServer(unreadableInventory{}, &stubRecipeBook{recipes: cookme.Recipes{cheeseOnToast}}, &stubCookingLog{}), "/")
+
+		assertStatus(t, res, http.StatusInternalServerError)
+		assertContains(t, res.Body.String(), "Couldn&#39;t read what&#39;s in the house")
+	})
+
+	t.Run("says when the recipes can't be fetched but still shows the ingredients", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		inv.AddIngredients(cheese.ExpiresAt(time.Now().Add(24 * time.Hour)))
+
+		res := get(web.NewServer(inv, &stubRecipeBook{err: errors.New("permission denied")}, &stubCookingLog{}), "/")
+
+		assertStatus(t, res, http.StatusBadGateway)
+		assertContains(t, res.Body.String(), "Couldn&#39;t get the recipes")
+		assertContains(t, res.Body.String(), "<td>Cheese</td>")
+	})
+
+	t.Run("shows what can be cooked tonight", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		inv.AddIngredients(cheese.ExpiresAt(time.Now().Add(24*time.Hour)), bread.ExpiresAt(time.Now().Add(24*time.Hour)))
+
+		res := get(web.NewServer(inv, &stubRecipeBook{recipes: cookme.Recipes{cheeseOnToast, omelette}}, &stubCookingLog{}), "/")
+
+		tonight := section(t, res.Body.String(), `<ol id="tonight">`, `</ol>`)
+		assertContains(t, tonight, "Cheese on toast")
+
+		if strings.Contains(tonight, "Omelette") {
+			t.Errorf("didn't expect omelette to be suggested without eggs, got %s", tonight)
+		}
+	})
+
+	t.Run("suggests recipes cooked recently last, like the cookme command", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		inv.AddIngredients(cheese.ExpiresAt(time.Now().Add(24*time.Hour)), bread.ExpiresAt(time.Now().Add(24*time.Hour)), eggs.ExpiresAt(time.Now().Add(24*time.Hour)))
+
+		cookingLog := &stubCookingLog{history: cookme.CookingHistory{{Name: cheeseOnToast.Name, CookedAt: time.Now().Add(-24 * time.Hour)}}}
+		res := get(web.NewServer(inv, &stubRecipeBook{recipes: cookme.Recipes{cheeseOnToast, omelette}}, cookingLog), "/")
+
+		got := regexp.MustCompile(`<li>([^<]+)</li>`).FindAllStringSubmatch(section(t, res.Body.String(), `<ol id="tonight">`, `</ol>`), -1)
+
+		if len(got) != 2 || got[0][1] != "Omelette" || got[1][1] != "Cheese on toast" {
+			t.Errorf("expected omelette then cheese on toast but got %v", got)
+		}
+	})
+
+	t.Run("gives every form a CSRF token from the visitor's cookie", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		res := get(web.NewServer(inv, &stubRecipeBook{recipes: cookme.Recipes{cheeseOnToast}}, &stubCookingLog{}), "/")
+
+		cookies := res.Result().Cookies()
+
+		if len(cookies) != 1 || cookies[0].Name != "cookme_csrf" || !cookies[0].HttpOnly {
+			t.Fatalf("expected an http only CSRF cookie but got %v", cookies)
+		}
+
+		body := res.Body.String()
+		field := `name="csrf" value="` + cookies[0].Value + `"`
+
+		if got, want := strings.Count(body, field), strings.Count(body, "<form"); got != want {
+			t.Errorf("expected all %d forms to have the CSRF token but %d do", want, got)
+		}
+	})
+
+	t.Run("rejects forms without the visitor's CSRF token", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		server := web.NewServer(inv, &stubRecipeBook{}, &stubCookingLog{})
+		form := url.Values{"name": {"Milk"}, "days": {"3"}}
+
+		assertStatus(t, postWithoutCSRF(server, "/ingredients", form), http.StatusForbidden)
+
+		form.Set("csrf", "guessed")
+		assertStatus(t, postWithoutCSRF(server, "/ingredients", form, &http.Cookie{Name: "cookme_csrf", Value: testCSRF}), http.StatusForbidden)
+
+		if len(inv.Ingredients()) != 0 {
+			t.Errorf("expected nothing to be added but got %v", inv.Ingredients())
+		}
+	})
+
+	t.Run("adds an ingredient from the form", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		server := web.NewServer(inv, &stubRecipeBook{}, &stubCookingLog{})
+
+		res := post(server, "/ingredients", url.Values{"name": {"Milk"}, "days": {"3"}, "quantity": {"2"}, "category": {"dairy"}})
+
+		assertStatus(t, res, http.StatusSeeOther)
+
+		ingredients := inv.Ingredients()
+
+		if len(ingredients) != 1 {
+			t.Fatalf("expected one ingredient but got %v", ingredients)
+		}
+
+		if got := ingredients[0]; got.Name != "Milk" || got.Quantity != 2 || got.Category != "dairy" {
+			t.Errorf("got %+v, want 2 Milk in dairy", got)
+		}
+	})
+
+	t.Run("shows an error when the ingredient form is incomplete", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		res := post(web.NewServer(inv, &stubRecipeBook{}, &stubCookingLog{}), "/ingredients", url.Values{"name": {"Milk"}, "days": {"soon"}})
+
+		assertStatus(t, res, http.StatusBadRequest)
+		assertContains(t, res.Body.String(), `<p class="error">`)
+
+		if len(inv.Ingredients()) != 0 {
+			t.Errorf("expected nothing to be added but got %v", inv.Ingredients())
+		}
+	})
+
+	t.Run("deletes a batch of an ingredient", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		added, _ := inv.AddIngredients(cheese.ExpiresAt(time.Now().Add(24*time.Hour)), cheese.ExpiresAt(time.Now().Add(24*time.Hour)))
+
+		res := post(web.NewServer(inv, &stubRecipeBook{}, &stubCookingLog{}), "/ingredients/delete", url.Values{"name": {"Cheese"}, "batch": {added[0].BatchID}})
+
+		assertStatus(t, res, http.StatusSeeOther)
+		cookme.AssertPerishableIngredientsEqual(t, inv.Ingredients(), added[1:])
+	})
+
+	t.Run("adds and deletes recipes", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		recipes := &stubRecipeBook{}
+		server := web.NewServer(inv, recipes, &stubCookingLog{})
+
+		assertStatus(t, post(server, "/recipes", url.Values{"name": {"Cheese on toast"}, "ingredients": {"Cheese, Bread"}}), http.StatusSeeOther)
+		cookme.AssertRecipesEqual(t, recipes.recipes, cookme.Recipes{cheeseOnToast})
+
+		assertStatus(t, post(server, "/recipes/delete", url.Values{"name": {"Cheese on toast"}}), http.StatusSeeOther)
+		cookme.AssertRecipesEqual(t, recipes.recipes, nil)
+	})
+
+	t.Run("says when the recipe service rejects a change", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		server := web.NewServer(inv, &stubRecipeBook{changeErr: errors.New("permission denied")}, &stubCookingLog{})
+
+		res := post(server, "/recipes", url.Values{"name": {"Cheese on toast"}, "ingredients": {"Cheese, Bread"}})
+		assertStatus(t, res, http.StatusBadGateway)
+		assertContains(t, res.Body.String(), "Couldn&#39;t add Cheese on toast")
+
+		res = post(server, "/recipes/delete", url.Values{"name": {"Cheese on toast"}})
+		assertStatus(t, res, http.StatusBadGateway)
+		assertContains(t, res.Body.String(), "Couldn&#39;t delete Cheese on toast")
+	})
+
+	t.Run("escapes what users type", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		inv.AddIngredients(cookme.Ingredient{Name: "<script>alert(1)</script>"}.ExpiresAt(time.Now()))
+
+		body := get(web.NewServer(inv, &stubRecipeBook{}, &stubCookingLog{}), "/").Body.String()
+
+		if strings.Contains(body, "<script>") {
+			t.Error("expected the ingredient name to be escaped")
+		}
+	})
+
+	t.Run("forms only accept posts", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		assertStatus(t, get(web.NewServer(inv, &stubRecipeBook{}, &stubCookingLog{}), "/recipes/delete"), http.StatusMethodNotAllowed)
+	})
+
+	t.Run("unknown pages are not found", func(t *testing.T) {
+		inv, cleanup := inventorytest.NewInventory(t)
+		defer cleanup()
+
+		assertStatus(t, get(web.NewServer(inv, &stubRecipeBook{}, &stubCookingLog{}), "/nope"), http.StatusNotFound)
+	})
+}
+
+type stubCookingLog struct {
+	history cookme.CookingHistory
+}
+
+func (s *stubCookingLog) History() cookme.CookingHistory {
+	return s.history
+}
+
+type unreadableInventory struct{}
+
+func (unreadableInventory) Ingredients() cookme.PerishableIngredients {
+	return nil
+}
+
+func (unreadableInventory) IngredientsContext(ctx context.Context) (cookme.PerishableIngredients, error) {
+	return nil, errors.New("timeout")
+}
+
+func (unreadableInventory) AddIngredients(ingredients ...cookme.PerishableIngredient) (cookme.PerishableIngredients, error) {
+	return nil, errors.New("timeout")
+}
+
+func (unreadableInventory) DeleteBatch(ingredient string, batchID string) error {
+	return errors.New("timeout")
+}
+
+type stubRecipeBook struct {
+	recipes   cookme.Recipes
+	err       error
+	changeErr error
+}
+
+func (s *stubRecipeBook) Recipes() cookme.Recipes {
+	return s.recipes
+}
+
+func (s *stubRecipeBook) RecipesContext(ctx context.Context) (cookme.Recipes, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.recipes, nil
+}
+
+func (s *stubRecipeBook) Add(name string, ingredients []string) error {
+	if s.changeErr != nil {
+		return s.changeErr
+	}
+	recipe := cookme.NewRecipe(name)
+	for _, i := range ingredients {
+		recipe.Ingredients = append(recipe.Ingredients, cookme.Ingredient{Name: i})
+	}
+	s.recipes = append(s.recipes, recipe)
+	return nil
+}
+
+func (s *stubRecipeBook) Delete(name string) error {
+	if s.changeErr != nil {
+		return s.changeErr
+	}
+	var recipes cookme.Recipes
+	for _, r := range s.recipes {
+		if r.Name != name {
+			recipes = append(recipes, r)
+		}
+	}
+	s.recipes = recipes
+	return nil
+}
+
+func get(handler http.Handler, path string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	return res
+}
+
+// post submits a form as if it came from one of the server's pages, with a CSRF token matching the visitor's cookie
+func post(handler http.Handler, path string, form url.Values, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	form.Set("csrf", testCSRF)
+	return postWithoutCSRF(handler, path, form, append(cookies, &http.Cookie{Name: "cookme_csrf", Value: testCSRF})...)
+}
+
+func postWithoutCSRF(handler http.Handler, path string, form url.Values, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	return res
+}
+
+const testCSRF = "test-csrf-token"
+
+func section(t *testing.T, body, start, end string) string {
+	t.Helper()
+	i := strings.Index(body, start)
+
+	if i == -1 {
+		t.Fatalf("expected %s in %s", start, body)
+	}
+
+	body = body[i:]
+	return body[:strings.Index(body, end)]
+}
+
+func assertStatus(t *testing.T, res *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if res.Code != want {
+		t.Errorf("got status %d, want %d", res.Code, want)
+	}
+}
+
+func assertContains(t *testing.T, got, want string) {
+	t.Helper()
+	if !strings.Contains(got, want) {
+		t.Errorf("expected %q in %s", want, got)
+	}
+}
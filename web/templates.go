@@ -0,0 +1,93 @@
+package web
+
+const pageHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Cook me</title>
+<style>
+body { font-family: sans-serif; max-width: 50em; margin: 0 auto; padding: 1em; }
+table { border-collapse: collapse; width: 100%; }
+td, th { text-align: left; padding: 0.3em; }
+tr.expired { background: #f8d0d0; }
+tr.soon { background: #fbe7b5; }
+tr.fresh { background: #d8f0d0; }
+.error { color: #a00; font-weight: bold; }
+form.inline { display: inline; }
+</style>
+</head>
+<body>
+<h1>Cook me</h1>
+{{if .LoggedIn}}<form method="post" action="/logout"><input type="hidden" name="csrf" value="{{$.CSRF}}"><button type="submit">Log out</button></form>{{end}}
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+
+<h2>Cook tonight</h2>
+{{with .Tonight}}
+<ol id="tonight">
+{{range .}}<li>{{.Name}}</li>
+{{end}}</ol>
+{{else}}
+<p>Nothing can be cooked with what's in the house.</p>
+{{end}}
+
+<h2>In the house</h2>
+<table id="ingredients">
+<tr><th>Ingredient</th><th>Quantity</th><th>Category</th><th>Expires</th><th></th></tr>
+{{range .Ingredients}}<tr class="{{.Urgency}}">
+<td>{{.Name}}</td><td>{{.Quantity}}</td><td>{{.Category}}</td><td>{{.Expires}}</td>
+<td><form class="inline" method="post" action="/ingredients/delete"><input type="hidden" name="csrf" value="{{$.CSRF}}">
+<input type="hidden" name="name" value="{{.Name}}"><input type="hidden" name="batch" value="{{.BatchID}}">
+<button type="submit">Delete</button></form></td>
+</tr>
+{{end}}</table>
+
+<form method="post" action="/ingredients">
+<input type="hidden" name="csrf" value="{{$.CSRF}}">
+<input name="name" placeholder="Ingredient" required>
+<input name="days" type="number" placeholder="Days until it expires" required>
+<input name="quantity" type="number" min="1" value="1">
+<input name="category" placeholder="Category">
+<button type="submit">Add ingredient</button>
+</form>
+
+<h2>Recipes</h2>
+<ul id="recipes">
+{{range .Recipes}}<li>{{.Name}} ({{range $i, $ingredient := .Ingredients}}{{if $i}}, {{end}}{{$ingredient.Name}}{{end}})
+<form class="inline" method="post" action="/recipes/delete"><input type="hidden" name="csrf" value="{{$.CSRF}}">
+<input type="hidden" name="name" value="{{.Name}}"><button type="submit">Delete</button></form></li>
+{{end}}</ul>
+
+<form method="post" action="/recipes">
+<input type="hidden" name="csrf" value="{{$.CSRF}}">
+<input name="name" placeholder="Recipe" required>
+<input name="ingredients" placeholder="Ingredients, separated by commas" required>
+<button type="submit">Add recipe</button>
+</form>
+</body>
+</html>
+`
+
+const loginHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Cook me</title>
+<style>
+body { font-family: sans-serif; max-width: 50em; margin: 0 auto; padding: 1em; }
+.error { color: #a00; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>Cook me</h1>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+
+<form method="post" action="/login">
+<input type="hidden" name="csrf" value="{{.CSRF}}">
+<input name="token" type="password" placeholder="Token, see cookme token create" required>
+<button type="submit">Log in</button>
+</form>
+</body>
+</html>
+`